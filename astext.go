@@ -0,0 +1,89 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isAsText reports whether v is encoded in the form named by its `as` tag.
+// Like other tags it passes through pointers, and through arrays and
+// slices that are not TextMarshalers themselves, to their elements.
+func isAsText(t reflect.Type, tags fieldTags) bool {
+	if tags.as == "" {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return false
+	case reflect.Array, reflect.Slice:
+		return reflect.PtrTo(t).Implements(textMarshalerType)
+	}
+	return true
+}
+
+func parseAsTag(tag string) error {
+	if tag != "text" {
+		return fmt.Errorf("binencoder: invalid as tag %q", tag)
+	}
+	return nil
+}
+
+// encodeAsText encodes a value with `as:"text"` as the string returned by
+// its MarshalText method, padded to the field's length or preceded by its
+// `prefix`.
+func (enc *Encoder) encodeAsText(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	if err := parseAsTag(tags.as); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if !reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		return enc.fail(path, v.Type(), fmt.Errorf("%w: %s does not implement encoding.TextMarshaler", ErrUnknownType, v.Type()))
+	}
+	if !v.CanAddr() {
+		// MarshalText may have a pointer receiver.
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+	text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if err := enc.writeBytes(text, bytesLen, tags.prefix); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeAsText is the inverse of Encoder.encodeAsText. Without a prefix
+// the padding is trimmed before UnmarshalText is called.
+func (dec *Decoder) decodeAsText(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	if err := parseAsTag(tags.as); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if !v.CanAddr() || !reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: %s does not implement encoding.TextUnmarshaler", ErrUnknownType, v.Type()))
+	}
+	text, err := dec.readBytes(bytesLen, tags.prefix)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if tags.prefix == "" {
+		if dec.byteOrder == binary.LittleEndian {
+			text = bytes.TrimRight(text, string(dec.padByte))
+		} else {
+			text = bytes.TrimLeft(text, string(dec.padByte))
+		}
+	}
+	if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: %v", ErrInvalidValue, err))
+	}
+	return nil
+}
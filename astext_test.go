@@ -0,0 +1,77 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type orderID uint32
+
+func (id orderID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("ORD-%d", uint32(id))), nil
+}
+
+func (id *orderID) UnmarshalText(b []byte) error {
+	var n uint32
+	if _, err := fmt.Sscanf(string(b), "ORD-%d", &n); err != nil {
+		return err
+	}
+	*id = orderID(n)
+	return nil
+}
+
+func TestAsText(t *testing.T) {
+	type order struct {
+		ID     orderID    `as:"text" len:"8"`
+		Host   net.IP     `as:"text" prefix:"u8"`
+		Parent *orderID   `as:"text" prefix:"u8"`
+		Items  [2]orderID `as:"text" len:"6"`
+	}
+	parent := orderID(7)
+	in := order{ID: 42, Host: net.ParseIP("192.0.2.1"), Parent: &parent, Items: [2]orderID{1, 23}}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	if err := enc.Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("ORD-42\x00\x00")
+	want = append(want, 9)
+	want = append(want, "192.0.2.1"...)
+	want = append(want, 5)
+	want = append(want, "ORD-7"...)
+	want = append(want, "ORD-1\x00ORD-23"...)
+	equalByte(t, buf.Bytes(), want)
+
+	var out order
+	if err := binencoder.NewDecoder(bytes.NewReader(want)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != 42 || !out.Host.Equal(in.Host) || out.Parent == nil || *out.Parent != 7 || out.Items != in.Items {
+		t.Errorf("got %+v", out)
+	}
+
+	bad := append([]byte("ID-42\x00\x00\x00"), want[8:]...)
+	if err := binencoder.NewDecoder(bytes.NewReader(bad)).Decode(&out, 0); !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if err := enc.Encode(struct {
+		ID orderID `as:"text" len:"4"`
+	}{42}, 0); !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+	if err := enc.Encode(struct {
+		N uint32 `as:"text" len:"4"`
+	}{42}, 0); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+	if err := enc.Encode(struct {
+		ID orderID `as:"json" len:"8"`
+	}{42}, 0); err == nil {
+		t.Error("expected an error for an unknown as tag")
+	}
+}
@@ -0,0 +1,73 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchEncoder encodes the elements of a slice concurrently, each into a
+// frame of its own, for bulk exports and message batches.
+type BatchEncoder struct {
+	workers int
+	opts    []Option
+}
+
+// NewBatchEncoder returns a BatchEncoder running up to workers encoders at
+// once, runtime.GOMAXPROCS(0) if workers is not positive. Each encoder is
+// created with opts.
+func NewBatchEncoder(workers int, opts ...Option) *BatchEncoder {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &BatchEncoder{workers: workers, opts: opts}
+}
+
+// Encode encodes every element of items, a slice or an array, and returns
+// the frames in the order of the elements. If some elements fail, the error
+// of the first of them is returned.
+func (b *BatchEncoder) Encode(items interface{}, bytesLen int) ([][]byte, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, newEncodeError("", reflect.TypeOf(items), fmt.Errorf("%w: BatchEncoder needs a slice or an array", ErrUnknownType))
+	}
+	frames := make([][]byte, v.Len())
+	errs := make([]error, v.Len())
+	workers := b.workers
+	if workers > len(frames) {
+		workers = len(frames)
+	}
+	var next, failed int32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			enc := NewEncoder(nil, b.opts...)
+			for atomic.LoadInt32(&failed) == 0 {
+				i := int(atomic.AddInt32(&next, 1)) - 1
+				if i >= len(frames) {
+					return
+				}
+				var buf bytes.Buffer
+				enc.Reset(&buf)
+				if err := enc.Encode(v.Index(i).Interface(), bytesLen); err != nil {
+					errs[i] = err
+					atomic.StoreInt32(&failed, 1)
+					return
+				}
+				frames[i] = buf.Bytes()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("binencoder: record %d: %w", i, err)
+		}
+	}
+	return frames, nil
+}
@@ -0,0 +1,46 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestBatchEncoder(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	items := make([]message, 100)
+	for i := range items {
+		items[i] = message{ID: uint16(i), Name: "m"}
+	}
+	frames, err := binencoder.NewBatchEncoder(4, binencoder.WithByteOrder(binary.BigEndian)).Encode(items, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != len(items) {
+		t.Fatalf("got %d frames", len(frames))
+	}
+	for i, frame := range frames {
+		want, _ := binencoder.Marshal(items[i], binary.BigEndian)
+		if !bytes.Equal(frame, want) {
+			t.Errorf("frame %d: got %v, want %v", i, frame, want)
+		}
+	}
+
+	items[7].Name = "too long"
+	items[60].Name = "also too long"
+	_, err = binencoder.NewBatchEncoder(0).Encode(items, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Fatalf("expected ErrFieldTooLong, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: record 7: binencoder: encoding Name (string): field too long")
+
+	if _, err := binencoder.NewBatchEncoder(1).Encode(items[0], 0); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// bigIntSigned reports whether a `bigint` tag selects two's complement
+// ("twos", the default) rather than the unsigned magnitude ("unsigned").
+func bigIntSigned(tag string) (bool, error) {
+	switch tag {
+	case "", "twos":
+		return true, nil
+	case "unsigned":
+		return false, nil
+	}
+	return false, fmt.Errorf("binencoder: invalid bigint tag %q", tag)
+}
+
+// bigIntOf returns the big.Int v holds.
+func bigIntOf(v reflect.Value) *big.Int {
+	if v.CanAddr() {
+		return v.Addr().Interface().(*big.Int)
+	}
+	x := v.Interface().(big.Int)
+	return &x
+}
+
+// bigIntToWire returns x as n bytes in the given byte order.
+func bigIntToWire(x *big.Int, n int, signed bool, order binary.ByteOrder) ([]byte, error) {
+	bits := uint(8 * n)
+	y := new(big.Int).Set(x)
+	switch {
+	case !signed && x.Sign() < 0:
+		return nil, fmt.Errorf("%w: %s is negative", ErrOverflow, x)
+	case !signed && x.BitLen() > int(bits):
+		return nil, fmt.Errorf("%w: %s does not fit %d bytes", ErrOverflow, x, n)
+	case signed:
+		limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+		if x.Cmp(limit) >= 0 || x.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%w: %s does not fit %d bytes", ErrOverflow, x, n)
+		}
+		if x.Sign() < 0 {
+			y.Add(y, limit.Lsh(limit, 1))
+		}
+	}
+	b := y.FillBytes(make([]byte, n))
+	if order == binary.LittleEndian {
+		reverse(b)
+	}
+	return b, nil
+}
+
+// bigIntFromWire is the inverse of bigIntToWire. It reverses b in place.
+func bigIntFromWire(x *big.Int, b []byte, signed bool, order binary.ByteOrder) {
+	if order == binary.LittleEndian {
+		reverse(b)
+	}
+	x.SetBytes(b)
+	if signed && len(b) > 0 && b[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// encodeBigInt encodes a big.Int in exactly bytesLen bytes.
+func (enc *Encoder) encodeBigInt(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	if bytesLen == 0 {
+		return enc.fail(path, v.Type(), fmt.Errorf("binencoder: big.Int needs a len tag"))
+	}
+	signed, err := bigIntSigned(tags.bigint)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	b, err := bigIntToWire(bigIntOf(v), bytesLen, signed, enc.byteOrder)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeBigInt is the inverse of Encoder.encodeBigInt.
+func (dec *Decoder) decodeBigInt(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	if bytesLen == 0 {
+		return newDecodeError(path, v.Type(), fmt.Errorf("binencoder: big.Int needs a len tag"))
+	}
+	signed, err := bigIntSigned(tags.bigint)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	b := make([]byte, bytesLen)
+	if err := dec.readFull(b); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	bigIntFromWire(v.Addr().Interface().(*big.Int), b, signed, dec.byteOrder)
+	return nil
+}
@@ -0,0 +1,47 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestBigInt(t *testing.T) {
+	type transfer struct {
+		Amount  *big.Int `len:"32" bigint:"unsigned"`
+		Delta   *big.Int `len:"4"`
+		Missing *big.Int `len:"2"`
+	}
+	amount, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	in := transfer{Amount: amount, Delta: big.NewInt(-2)}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 32)
+	copy(want[23:], []byte{0x36, 0x35, 0xc9, 0xad, 0xc5, 0xde, 0xa0, 0x00, 0x00})
+	want = append(want, 0xff, 0xff, 0xff, 0xfe, 0, 0)
+	equalByte(t, buf.Bytes(), want)
+
+	var out transfer
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.BigEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Amount.Cmp(amount) != 0 || out.Delta.Int64() != -2 || out.Missing.Sign() != 0 {
+		t.Errorf("got %v %v %v", out.Amount, out.Delta, out.Missing)
+	}
+
+	err := binencoder.NewEncoder(buf).Encode(struct {
+		V *big.Int `len:"1"`
+	}{V: big.NewInt(128)}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	if err := binencoder.NewEncoder(buf).Encode(struct{ V *big.Int }{big.NewInt(1)}, 0); err == nil {
+		t.Error("expected an error for a big.Int without len")
+	}
+}
@@ -0,0 +1,174 @@
+// Package binencbench measures encoding and decoding throughput and
+// allocations of sample values, so that layout, tag and codec choices can be
+// compared with data.
+package binencbench
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+// Case is a sample value to measure.
+type Case struct {
+	Name     string
+	Value    interface{}
+	BytesLen int
+	Order    binary.ByteOrder
+}
+
+// Suite is a set of cases measured together.
+type Suite struct {
+	// Duration is the minimal time each measurement runs, 1s if zero.
+	Duration time.Duration
+	cases    []Case
+}
+
+// Add adds a sample value encoded with bytesLen 0 and little-endian order.
+func (s *Suite) Add(name string, sample interface{}) {
+	s.AddCase(Case{Name: name, Value: sample})
+}
+
+func (s *Suite) AddCase(c Case) {
+	if c.Order == nil {
+		c.Order = binary.LittleEndian
+	}
+	s.cases = append(s.cases, c)
+}
+
+// Measurement holds per-operation costs.
+type Measurement struct {
+	NsPerOp     float64
+	AllocsPerOp float64
+	BytesPerOp  float64
+	MBPerSec    float64
+}
+
+// Result is the outcome of measuring one case.
+type Result struct {
+	Name   string
+	Size   int
+	Encode Measurement
+	Decode Measurement
+}
+
+// Report lists results in the order the cases were added.
+type Report []Result
+
+// Run measures every case. It stops at the first case that fails to encode
+// or decode.
+func (s *Suite) Run() (Report, error) {
+	d := s.Duration
+	if d <= 0 {
+		d = time.Second
+	}
+	report := make(Report, 0, len(s.cases))
+	for _, c := range s.cases {
+		res, err := runCase(c, d)
+		if err != nil {
+			return report, fmt.Errorf("binencbench: %s: %w", c.Name, err)
+		}
+		report = append(report, res)
+	}
+	return report, nil
+}
+
+func runCase(c Case, d time.Duration) (Result, error) {
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithByteOrder(c.Order))
+	if err := enc.Encode(c.Value, c.BytesLen); err != nil {
+		return Result{}, err
+	}
+	data := append([]byte{}, buf.Bytes()...)
+	res := Result{Name: c.Name, Size: len(data)}
+
+	var err error
+	res.Encode, err = measure(d, len(data), func() error {
+		buf.Reset()
+		return enc.Encode(c.Value, c.BytesLen)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	// Decoding into a copy of the sample keeps slices and strings sized.
+	target := reflect.New(reflect.TypeOf(c.Value))
+	target.Elem().Set(reflect.ValueOf(c.Value))
+	r := bytes.NewReader(data)
+	dec := binencoder.NewDecoder(r, binencoder.WithByteOrder(c.Order))
+	res.Decode, err = measure(d, len(data), func() error {
+		r.Reset(data)
+		return dec.Decode(target.Interface(), c.BytesLen)
+	})
+	return res, err
+}
+
+// measure runs op with a growing iteration count until a run lasts at least d.
+func measure(d time.Duration, size int, op func() error) (Measurement, error) {
+	for n := 1; ; {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if err := op(); err != nil {
+				return Measurement{}, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed >= d || n >= 1e9 {
+			ops := float64(n)
+			m := Measurement{
+				NsPerOp:     float64(elapsed.Nanoseconds()) / ops,
+				AllocsPerOp: float64(after.Mallocs-before.Mallocs) / ops,
+				BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / ops,
+			}
+			if elapsed > 0 {
+				m.MBPerSec = float64(size) * ops / 1e6 / elapsed.Seconds()
+			}
+			return m, nil
+		}
+		next := 100 * n
+		if elapsed > 0 {
+			next = int(1.2 * float64(n) * float64(d) / float64(elapsed))
+		}
+		if next <= n {
+			next = n + 1
+		}
+		n = next
+	}
+}
+
+// WriteTo writes the report as an aligned text table.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "case\tbytes\tenc ns/op\tenc MB/s\tenc allocs/op\tdec ns/op\tdec MB/s\tdec allocs/op\t")
+	for _, res := range r {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.2f\t%.1f\t%.1f\t%.2f\t%.1f\t\n", res.Name, res.Size,
+			res.Encode.NsPerOp, res.Encode.MBPerSec, res.Encode.AllocsPerOp,
+			res.Decode.NsPerOp, res.Decode.MBPerSec, res.Decode.AllocsPerOp)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
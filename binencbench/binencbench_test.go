@@ -0,0 +1,58 @@
+package binencbench_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder/binencbench"
+)
+
+type fixedMessage struct {
+	ID    uint32
+	Flags uint16
+	Name  string `len:"16"`
+}
+
+type sliceMessage struct {
+	Values []uint64
+}
+
+func TestSuite(t *testing.T) {
+	s := binencbench.Suite{Duration: 5 * time.Millisecond}
+	s.Add("fixed", fixedMessage{ID: 1, Name: "sensor"})
+	s.Add("slice", sliceMessage{Values: make([]uint64, 32)})
+
+	report, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 || report[0].Size != 22 || report[1].Size != 256 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, res := range report {
+		if res.Encode.NsPerOp <= 0 || res.Decode.NsPerOp <= 0 {
+			t.Errorf("%s: missing timings: %+v", res.Name, res)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := report.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "fixed") || !strings.Contains(lines[2], "slice") {
+		t.Errorf("unexpected table:\n%s", buf)
+	}
+}
+
+func TestSuiteError(t *testing.T) {
+	s := binencbench.Suite{Duration: time.Millisecond}
+	s.Add("broken", struct {
+		Name string `len:"2"`
+	}{Name: "too long"})
+	if _, err := s.Run(); err == nil {
+		t.Error("expected an error")
+	}
+}
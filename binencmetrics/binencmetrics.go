@@ -0,0 +1,183 @@
+// Package binencmetrics collects the measurements of encoders and decoders
+// created with binencoder.WithMetrics and exposes them in the Prometheus
+// text format, so that they can be scraped without a client library.
+package binencmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+var _ binencoder.Metrics = (*Collector)(nil)
+
+// DefaultBuckets are the upper bounds in seconds of the duration histogram
+// buckets used when a Collector has none, from 1µs to 100ms.
+var DefaultBuckets = []float64{1e-6, 5e-6, 1e-5, 5e-5, 1e-4, 5e-4, 1e-3, 5e-3, 1e-2, 1e-1}
+
+type seriesKey struct {
+	op string
+	t  string
+}
+
+type series struct {
+	messages uint64
+	errors   uint64
+	bytes    uint64
+	buckets  []uint64
+	sum      float64
+}
+
+// Collector implements binencoder.Metrics. It counts messages, errors and
+// bytes and keeps a histogram of durations by operation ("encode" or
+// "decode") and message type. It is safe for concurrent use, so one
+// Collector can be shared by all encoders and decoders of a service.
+type Collector struct {
+	// Namespace prefixes the metric names, "binencoder" if empty.
+	Namespace string
+	// Buckets are the upper bounds in seconds of the histogram buckets,
+	// DefaultBuckets if nil. They must not change once observed.
+	Buckets []float64
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+// ObserveEncode records an encoded message.
+func (c *Collector) ObserveEncode(t reflect.Type, n int, d time.Duration, err error) {
+	c.observe("encode", t, n, d, err)
+}
+
+// ObserveDecode records a decoded message.
+func (c *Collector) ObserveDecode(t reflect.Type, n int, d time.Duration, err error) {
+	c.observe("decode", t, n, d, err)
+}
+
+func (c *Collector) buckets() []float64 {
+	if c.Buckets == nil {
+		return DefaultBuckets
+	}
+	return c.Buckets
+}
+
+func (c *Collector) observe(op string, t reflect.Type, n int, d time.Duration, err error) {
+	key := seriesKey{op: op, t: fmt.Sprint(t)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.series == nil {
+		c.series = map[seriesKey]*series{}
+	}
+	s := c.series[key]
+	if s == nil {
+		s = &series{buckets: make([]uint64, len(c.buckets()))}
+		c.series[key] = s
+	}
+	s.messages++
+	if err != nil {
+		s.errors++
+	}
+	s.bytes += uint64(n)
+	sec := d.Seconds()
+	s.sum += sec
+	for i, le := range c.buckets() {
+		if sec <= le {
+			s.buckets[i]++
+		}
+	}
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	ns := c.Namespace
+	if ns == "" {
+		ns = "binencoder"
+	}
+	c.mu.Lock()
+	keys := make([]seriesKey, 0, len(c.series))
+	snapshot := make(map[seriesKey]series, len(c.series))
+	for k, s := range c.series {
+		keys = append(keys, k)
+		copied := *s
+		copied.buckets = append([]uint64(nil), s.buckets...)
+		snapshot[k] = copied
+	}
+	c.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].op != keys[j].op {
+			return keys[i].op < keys[j].op
+		}
+		return keys[i].t < keys[j].t
+	})
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	counters := []struct {
+		name, help string
+		value      func(series) uint64
+	}{
+		{"messages_total", "Messages encoded or decoded.", func(s series) uint64 { return s.messages }},
+		{"errors_total", "Messages that failed to encode or decode.", func(s series) uint64 { return s.errors }},
+		{"bytes_total", "Bytes written or read.", func(s series) uint64 { return s.bytes }},
+	}
+	for _, m := range counters {
+		fmt.Fprintf(cw, "# HELP %s_%s %s\n# TYPE %s_%s counter\n", ns, m.name, m.help, ns, m.name)
+		for _, k := range keys {
+			fmt.Fprintf(cw, "%s_%s{%s} %d\n", ns, m.name, labels(k), m.value(snapshot[k]))
+		}
+	}
+	fmt.Fprintf(cw, "# HELP %s_duration_seconds Time taken to encode or decode a message.\n# TYPE %s_duration_seconds histogram\n", ns, ns)
+	for _, k := range keys {
+		s := snapshot[k]
+		for i, le := range c.buckets() {
+			fmt.Fprintf(cw, "%s_duration_seconds_bucket{%s,le=%q} %d\n", ns, labels(k), strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(cw, "%s_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", ns, labels(k), s.messages)
+		fmt.Fprintf(cw, "%s_duration_seconds_sum{%s} %s\n", ns, labels(k), strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(cw, "%s_duration_seconds_count{%s} %d\n", ns, labels(k), s.messages)
+	}
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format, e.g. on a
+// /metrics endpoint.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+func labels(k seriesKey) string {
+	return "op=" + quoteLabel(k.op) + ",type=" + quoteLabel(k.t)
+}
+
+// quoteLabel quotes a label value with the escapes of the text format.
+func quoteLabel(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// countWriter counts the bytes written to w and keeps the first error.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
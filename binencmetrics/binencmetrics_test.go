@@ -0,0 +1,53 @@
+package binencmetrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+	"github.com/milQA/binencoder/binencmetrics"
+)
+
+type reading struct {
+	Sensor uint8
+	Value  int32
+}
+
+func TestCollector(t *testing.T) {
+	c := &binencmetrics.Collector{Buckets: []float64{10}}
+	var buf bytes.Buffer
+	enc := binencoder.NewEncoder(&buf, binencoder.WithMetrics(c), binencoder.WithMaxSize(5))
+	if err := enc.Encode(reading{1, 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(struct{ A, B, C uint16 }{}, 0); err == nil {
+		t.Fatal("expected an error over the size limit")
+	}
+	dec := binencoder.NewDecoder(&buf, binencoder.WithMetrics(c))
+	var out reading
+	if err := dec.Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&out, 0); err == nil {
+		t.Fatal("expected an error at the end of the input")
+	}
+
+	var text strings.Builder
+	if _, err := c.WriteTo(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`binencoder_messages_total{op="decode",type="binencmetrics_test.reading"} 2`,
+		`binencoder_errors_total{op="decode",type="binencmetrics_test.reading"} 1`,
+		`binencoder_messages_total{op="encode",type="binencmetrics_test.reading"} 1`,
+		`binencoder_errors_total{op="encode",type="struct { A uint16; B uint16; C uint16 }"} 1`,
+		`binencoder_bytes_total{op="encode",type="binencmetrics_test.reading"} 5`,
+		`binencoder_duration_seconds_bucket{op="encode",type="binencmetrics_test.reading",le="10"} 1`,
+		`binencoder_duration_seconds_count{op="decode",type="binencmetrics_test.reading"} 2`,
+	} {
+		if !strings.Contains(text.String(), line+"\n") {
+			t.Errorf("missing %s in\n%s", line, text.String())
+		}
+	}
+}
@@ -1,85 +1,760 @@
 package binencoder
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
+	"math"
 	"reflect"
 	"strconv"
+	"sync"
+	"time"
 )
 
+// Logger receives diagnostics about skipped values. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// LoggerFunc adapts a printf-style function to the Logger interface.
+type LoggerFunc func(format string, v ...interface{})
+
+func (f LoggerFunc) Printf(format string, v ...interface{}) {
+	f(format, v...)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...interface{}) {}
+
+// Encoder writes values in their binary form. An Encoder keeps the state
+// of the value being encoded and must not be used by several goroutines at
+// once; give each goroutine or connection its own, e.g. with Clone. The
+// plans built from struct tags are cached per type and shared by all
+// encoders and decoders, so creating them is cheap.
 type Encoder struct {
-	w         io.Writer
-	byteOrder binary.ByteOrder
+	w io.Writer
+	n int
+	config
+
+	// end is the furthest position written to before an `offset` tag
+	// moved back.
+	end int
+
+	// ctx is checked before each value while EncodeContext runs.
+	ctx context.Context
+
+	// prev is the previous snapshot while EncodeDelta runs.
+	prev reflect.Value
+
+	// reported is the position of the last WithProgress report.
+	reported int
+
+	// skipped counts the values of unsupported types logged and left out
+	// of the output.
+	skipped int
+
+	// zeroing holds the types of the nil pointers being encoded as zero
+	// values, outermost first.
+	zeroing []reflect.Type
+
+	// scratch, padding and bin are reused between values to avoid
+	// per-field allocations.
+	scratch []byte
+	padding []byte
+	bin     bytes.Buffer
+
+	// pathBuf holds the path of the value being encoded. Paths are passed
+	// around as their end in pathBuf and only turned into strings for
+	// errors; a child's path is appended after its parent's.
+	pathBuf []byte
+
+	// traceBuf holds the bytes of the message being encoded and traced
+	// counts the fields traced so far, while WithTrace is set.
+	traceBuf []byte
+	traced   int
+
+	// layout collects the fields encoded while Describe runs.
+	layout *layoutBuilder
+
+	// deflated holds compressed fields encoded ahead of time for their
+	// `sizeof` fields, by field, innermost last.
+	deflated map[*fieldPlan][][]byte
+
+	// interceptors are called for each struct field, see Use.
+	interceptors []Interceptor
+
+	// buffer collects the values encoded while WithBuffer is set.
+	buffer *frameBuffer
+
+	// names holds the offsets of the DNS names written to namesW, the
+	// writer of the message, for compression pointers.
+	names  map[string]int
+	namesW io.Writer
 }
 
-func NewEncoder(w io.Writer, byteOrder binary.ByteOrder) *Encoder {
-	return &Encoder{
-		w:         w,
-		byteOrder: byteOrder,
-	}
+// NewEncoder returns an Encoder writing to w. Without options it encodes
+// little-endian with zero padding.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	enc := &Encoder{}
+	enc.config.init(opts)
+	enc.setWriter(w)
+	return enc
+}
+
+// SetLogger sets the logger for diagnostics. A nil logger discards them,
+// which is the default.
+func (enc *Encoder) SetLogger(l Logger) {
+	WithLogger(l)(&enc.config)
+}
+
+// Clone returns a new Encoder writing to w with the options and
+// interceptors of enc, so that each goroutine or connection can have its
+// own. It may run concurrently with enc's Encode calls.
+func (enc *Encoder) Clone(w io.Writer) *Encoder {
+	clone := &Encoder{config: enc.config, interceptors: append([]Interceptor(nil), enc.interceptors...)}
+	clone.setWriter(w)
+	return clone
+}
+
+// Reset makes the Encoder write to w, keeping its options and internal
+// buffers, so that encoders can be pooled and reused. Values buffered
+// WithBuffer and not flushed are discarded.
+func (enc *Encoder) Reset(w io.Writer) {
+	enc.setWriter(w)
 }
 
 func (enc *Encoder) Encode(data interface{}, bytesLen int) error {
-	if bytesLen == -1 {
+	_, err := enc.EncodeN(data, bytesLen)
+	return err
+}
+
+// EncodeN is like Encode and also returns the number of bytes written,
+// including those written before an error.
+func (enc *Encoder) EncodeN(data interface{}, bytesLen int) (int, error) {
+	return enc.encodeValue(reflect.ValueOf(data), bytesLen)
+}
+
+// encodeValue encodes v as a whole message, reporting it to the metrics.
+func (enc *Encoder) encodeValue(v reflect.Value, bytesLen int) (int, error) {
+	if enc.metrics != nil {
+		start := time.Now()
+		n, err := enc.encodeN(v, bytesLen)
+		enc.metrics.ObserveEncode(typeOf(v), n, time.Since(start), err)
+		return n, err
+	}
+	return enc.encodeN(v, bytesLen)
+}
+
+// typeOf returns the type of v, or nil if v is the zero Value.
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
 		return nil
 	}
+	return v.Type()
+}
+
+func (enc *Encoder) encodeN(v reflect.Value, bytesLen int) (int, error) {
+	enc.n, enc.end, enc.reported = 0, 0, 0
+	enc.pathBuf = enc.pathBuf[:0]
+	enc.traceBuf, enc.traced = enc.traceBuf[:0], 0
+	var hw *hashWriter
+	if enc.trailer != nil {
+		h, err := enc.trailer.hash()
+		if err != nil {
+			return 0, enc.fail(0, typeOf(v), err)
+		}
+		hw = &hashWriter{w: enc.w, h: h}
+		enc.w = hw
+	}
+	var sealTo io.Writer
+	var plain *bytes.Buffer
+	if enc.sealKey != nil {
+		plain = new(bytes.Buffer)
+		sealTo, enc.w = enc.w, plain
+	}
+	for name := range enc.names {
+		delete(enc.names, name)
+	}
+	enc.namesW = enc.w
 	var err error
-	v := reflect.ValueOf(data)
-	switch v.Type().Kind() {
+	if enc.prev.IsValid() {
+		err = enc.encodeDelta(enc.prev, v)
+	} else if enc.format != FormatRaw {
+		err = enc.encodeFormat(v)
+	} else {
+		err = enc.encode(v, bytesLen, fieldTags{}, 0)
+	}
+	if err == nil {
+		err = enc.seekEnd()
+	}
+	if sealTo != nil {
+		enc.w = sealTo
+		if err == nil {
+			err = enc.writeEnvelope(plain.Bytes(), typeOf(v))
+		}
+	}
+	if hw != nil {
+		enc.w = hw.w
+		if err == nil {
+			err = enc.writeTrailer(hw.h, typeOf(v))
+		}
+	}
+	if fw, ok := enc.w.(FrameWriter); ok {
+		if err != nil {
+			fw.AbortFrame()
+			return enc.n, err
+		}
+		err = fw.EndFrame()
+	}
+	return enc.n, err
+}
+
+// EncodeStream encodes the values returned by next until it reports false,
+// so that large collections can be written without holding them in memory.
+func (enc *Encoder) EncodeStream(next func() (interface{}, bool), bytesLen int) error {
+	for i := 0; ; i++ {
+		v, ok := next()
+		if !ok {
+			return nil
+		}
+		if err := enc.Encode(v, bytesLen); err != nil {
+			return fmt.Errorf("binencoder: record %d: %w", i, err)
+		}
+	}
+}
+
+// EncodeContext is like Encode but stops with ctx's error, wrapped in an
+// EncodeError naming the field reached, once ctx is done. The context is
+// checked before every field and element.
+func (enc *Encoder) EncodeContext(ctx context.Context, data interface{}, bytesLen int) error {
+	enc.ctx = ctx
+	defer func() { enc.ctx = nil }()
+	return enc.Encode(data, bytesLen)
+}
+
+// write writes b to the underlying writer, counting the bytes written.
+func (enc *Encoder) write(b []byte) error {
+	if enc.maxSize > 0 && enc.n+len(b) > enc.maxSize {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d", ErrMessageTooLarge, enc.n+len(b), enc.maxSize)
+	}
+	n, err := enc.w.Write(b)
+	if enc.trace != nil {
+		enc.traceBuf = append(enc.traceBuf[:enc.n], b[:n]...)
+	}
+	enc.n += n
+	return err
+}
+
+// childPath appends the name of a struct field to the path ending at
+// parent.
+func (enc *Encoder) childPath(parent int, name string) int {
+	enc.pathBuf = enc.pathBuf[:parent]
+	if parent > 0 {
+		enc.pathBuf = append(enc.pathBuf, '.')
+	}
+	enc.pathBuf = append(enc.pathBuf, name...)
+	return len(enc.pathBuf)
+}
+
+// indexPath appends an element index to the path ending at parent.
+func (enc *Encoder) indexPath(parent int, i int) int {
+	enc.pathBuf = append(enc.pathBuf[:parent], '[')
+	enc.pathBuf = strconv.AppendInt(enc.pathBuf, int64(i), 10)
+	enc.pathBuf = append(enc.pathBuf, ']')
+	return len(enc.pathBuf)
+}
+
+// fail returns an EncodeError for the value at path.
+func (enc *Encoder) fail(path int, t reflect.Type, err error) error {
+	return newEncodeError(string(enc.pathBuf[:path]), t, err)
+}
+
+// Marshal returns the encoding of v in the given byte order, with opts
+// applied after it.
+func Marshal(v interface{}, order binary.ByteOrder, opts ...Option) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, append([]Option{WithByteOrder(order)}, opts...)...).Encode(v, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeTo encodes v into dst and returns the number of bytes written. It
+// does not allocate for values of base types and structs of them, which
+// suits hot paths encoding many small messages. If dst is too small the
+// error matches io.ErrShortBuffer. WithBuffer has no effect.
+func EncodeTo(dst []byte, v interface{}, opts ...Option) (int, error) {
+	se := sliceEncoders.Get().(*sliceEncoder)
+	se.config.init(opts)
+	// The options of an earlier call must not leave a buffer behind, and
+	// dst needs none.
+	se.bufSize, se.buffer = 0, nil
+	se.buf = sliceWriter{b: dst}
+	se.Reset(&se.buf)
+	err := se.Encode(v, 0)
+	n := se.buf.n
+	se.buf = sliceWriter{}
+	se.Reset(nil)
+	sliceEncoders.Put(se)
+	return n, err
+}
+
+// sliceEncoder is an Encoder writing into a caller's slice. They are pooled
+// to reuse their scratch buffers.
+type sliceEncoder struct {
+	Encoder
+	buf sliceWriter
+}
+
+var sliceEncoders = sync.Pool{
+	New: func() interface{} { return new(sliceEncoder) },
+}
+
+type sliceWriter struct {
+	b []byte
+	n int
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	n := copy(w.b[w.n:], p)
+	w.n += n
+	if n < len(p) {
+		return n, io.ErrShortBuffer
+	}
+	return n, nil
+}
+
+// Size returns the number of bytes Encode writes for v with a bytesLen of 0.
+// It runs the encoding against a discarding writer, so the result follows
+// the same tag logic without buffering the output.
+func Size(v interface{}) (int, error) {
+	n, err := NewEncoder(ioutil.Discard).EncodeN(v, 0)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// fieldTags holds the settings a struct field's tags apply to its whole
+// subtree (array/slice elements and pointer targets).
+type fieldTags struct {
+	compact  string
+	timefmt  string
+	durfmt   string
+	ip       string
+	uuid     string
+	prefix   string
+	typeid   string
+	encoding string
+	strenc   string
+	overflow string
+	fixed    string
+	scale    string
+	bigint   string
+	dns      string
+	as       string
+	raw      string
+}
+
+func (enc *Encoder) encode(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	if bytesLen == -1 {
+		return nil
+	}
+	if v.IsValid() {
+		if enc.ctx != nil {
+			if err := enc.ctx.Err(); err != nil {
+				return enc.fail(path, v.Type(), err)
+			}
+		}
+		if enc.progress != nil {
+			enc.reportProgress(path)
+		}
+		if err := beforeEncode(v); err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+		if c, ok := lookupCodec(v.Type()); ok && c.enc != nil {
+			return enc.encodeCodec(c.enc, v, bytesLen, tags, path)
+		}
+		if isAsText(v.Type(), tags) {
+			return enc.encodeAsText(v, bytesLen, tags, path)
+		}
+		if v.Kind() == reflect.Interface && tags.typeid != "" {
+			return enc.encodeTypeID(v, bytesLen, tags, path)
+		}
+		if v.Kind() == reflect.String && tags.strenc != "" {
+			return enc.encodeStrenc(v, bytesLen, tags, path)
+		}
+		if v.Kind() == reflect.String && tags.dns != "" {
+			return enc.encodeDNSName(v, tags, path)
+		}
+		if isVarint(v, tags) {
+			return enc.encodeVarint(v, tags, path)
+		}
+		if v.Type() == bigIntType {
+			return enc.encodeBigInt(v, bytesLen, tags, path)
+		}
+		if isByteBlob(v.Type(), bytesLen, tags) {
+			return enc.encodeByteBlob(v, bytesLen, tags, path)
+		}
+	}
+	v, err := toWire(v, tags)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if v.IsValid() && v.Type() == rawBytesType {
+		if err := enc.write(v.Bytes()); err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+		return nil
+	}
+	if v.IsValid() && v.Type() == readerType {
+		return enc.encodeReader(v, bytesLen, path)
+	}
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Invalid {
+		if m, ok := binMarshaler(v); ok {
+			return enc.encodeBin(m, bytesLen, tags, path, v.Type())
+		}
+		if m, ok := marshaler(v); ok {
+			return enc.encodeMarshaler(m, bytesLen, tags, path, v.Type())
+		}
+	}
+	switch v.Kind() {
 	case reflect.Array, reflect.Slice:
+		if elem := v.Type().Elem(); plainElem(elem) && !isAsText(elem, tags) && !enc.narrows(elem, bytesLen) {
+			return enc.encodePlainArray(v, bytesLen, path)
+		}
 		l := v.Len()
 		for i := 0; i < l; i++ {
-			err = enc.Encode(v.Index(i).Interface(), bytesLen)
+			err := enc.encode(v.Index(i), bytesLen, tags, enc.indexPath(path, i))
 			if err != nil {
 				return err
 			}
 		}
 	case reflect.Struct:
-		l := v.NumField()
-		for i := 0; i < l; i++ {
-			fieldType := v.Type().Field(i)
-			tag := decodeTags(fieldType.Tag.Get("len"), bytesLen)
-			err = enc.Encode(v.Field(i).Interface(), tag)
+		if order := structByteOrder(v); order != nil {
+			defer enc.useOrder(order)()
+		}
+		if b, ok := enc.memoryBytes(v, bytesLen); ok {
+			if err := enc.write(b); err != nil {
+				return enc.fail(path, v.Type(), err)
+			}
+			return nil
+		}
+		plan := enc.structPlan(v.Type())
+		block, blockStart := structBlockSize(v), enc.n
+		presence, err := enc.encodePresence(v, plan, path)
+		if err != nil {
+			return err
+		}
+		bit := 0
+		for i := range plan {
+			f := &plan[i]
+			if !enc.present(f) {
+				continue
+			}
+			if presence != nil && f.nullable {
+				bit++
+				if absent(presence, bit-1) {
+					continue
+				}
+			}
+			fieldPath := enc.childPath(path, f.name)
+			if f.offset >= 0 && f.err == nil {
+				if err := enc.seek(f.offset); err != nil {
+					return enc.fail(fieldPath, v.Type().Field(f.index).Type, err)
+				}
+			}
+			start, traced := enc.n, enc.traced
+			if enc.layout != nil {
+				enc.layout.enter(enc, v.Type().Field(f.index), f, fieldPath)
+			}
+			var err error
+			if len(enc.interceptors) > 0 {
+				err = enc.interceptField(v, f, bytesLen, fieldPath)
+			} else {
+				err = enc.encodeField(v, f, bytesLen, fieldPath)
+			}
 			if err != nil {
 				return err
 			}
+			if enc.layout != nil {
+				enc.layout.exit(enc.n)
+			}
+			if enc.trace != nil && enc.traced == traced {
+				enc.traceField(start, fieldPath, v.Field(f.index), f)
+			}
 		}
+		if block > 0 {
+			if err := enc.padBlock(blockStart, block); err != nil {
+				return enc.fail(path, v.Type(), err)
+			}
+		}
+	case reflect.Map:
+		return enc.encodeMap(v, bytesLen, tags, path)
 	case reflect.Ptr:
-		return enc.Encode(v.Elem().Interface(), bytesLen)
+		if v.IsNil() {
+			return enc.encodeZero(v.Type().Elem(), bytesLen, tags, path)
+		}
+		return enc.encode(v.Elem(), bytesLen, tags, path)
+	case reflect.Invalid:
+		return enc.fail(path, nil, ErrUnknownType)
 	default:
-		by, err := encodeBaseType(data)
+		by, err := appendBaseType(enc.scratch[:0], v, enc.intOrder())
+		if err != nil && enc.strict {
+			return enc.fail(path, v.Type(), err)
+		}
 		if err != nil {
-			log.Printf("[encodeBaseType] Error: %s", err)
+			enc.logger.Printf("[encodeBaseType] Error: %s", enc.fail(path, v.Type(), err))
+			enc.skipped++
 			return nil
 		}
-		if bytesLen != 0 {
-			delta := bytesLen - len(by)
-			if delta < 0 {
-				return errors.New("StringLenErr")
+		enc.scratch = by
+		if enc.narrows(v.Type(), bytesLen) {
+			if by, err = narrowInt(by, v, bytesLen, enc.intOrder()); err != nil {
+				return enc.fail(path, v.Type(), err)
 			}
-			byDelta := make([]byte, delta)
-			if enc.byteOrder == binary.LittleEndian {
-				by = append(by, byDelta...)
-			} else {
-				by = append(byDelta, by...)
+		}
+		if bytesLen != 0 && len(by) > bytesLen && v.Kind() == reflect.String {
+			s, err := v.String(), error(nil)
+			switch {
+			case tags.compact != "":
+				s, err = compactString(s, bytesLen, tags.compact)
+			case tags.overflow != "":
+				s, err = truncateString(s, bytesLen, tags.overflow)
 			}
+			if err != nil {
+				return enc.fail(path, v.Type(), err)
+			}
+			by = []byte(s)
+		}
+		if err := enc.writePadded(by, bytesLen); err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+	}
+	return nil
+}
 
+// encodeZero encodes the zero value of type t in place of a nil pointer.
+// A zero value reached again while encoding itself would recurse forever
+// and fails instead.
+func (enc *Encoder) encodeZero(t reflect.Type, bytesLen int, tags fieldTags, path int) error {
+	for _, z := range enc.zeroing {
+		if z == t {
+			return enc.fail(path, t, recursiveType(t))
 		}
-		err = binary.Write(enc.w, enc.byteOrder, by)
+	}
+	enc.zeroing = append(enc.zeroing, t)
+	err := enc.encode(reflect.Zero(t), bytesLen, tags, path)
+	enc.zeroing = enc.zeroing[:len(enc.zeroing)-1]
+	return err
+}
+
+// encodeField encodes a field of struct v, applying its tags. A field with
+// an endian option is encoded, along with its subtree, in that byte order.
+func (enc *Encoder) encodeField(v reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	field := v.Field(f.index)
+	if err := enc.fieldErr(f); err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	if f.flagged {
+		return nil
+	}
+	if f.flagBits != nil {
+		return enc.encodeFlags(v, f, path)
+	}
+	if f.sizeTo != nil {
+		return enc.encodeCompressedSize(v, f, bytesLen, path)
+	}
+	if f.sizeFrom >= 0 {
+		if f.compress != "" {
+			return enc.encodeCompressed(field, f, path)
+		}
+		n, err := sizeValue(v.Field(f.sizeFrom))
+		if err != nil {
+			return enc.fail(path, field.Type(), err)
+		}
+		return enc.encodeReader(field, n, path)
+	}
+	if f.lenFrom >= 0 {
+		n, err := sizeValue(v.Field(f.lenFrom))
 		if err != nil {
+			return enc.fail(path, field.Type(), err)
+		}
+		if n == 0 {
+			return nil
+		}
+		bytesLen = n
+	}
+	if f.tlv >= 0 {
+		return enc.encodeTLV(field, f, bytesLen, path)
+	}
+	if f.until != nil {
+		return enc.encodeUntil(field, f, bytesLen, path)
+	}
+	if f.klv != nil {
+		return enc.encodeKLV(field, f, bytesLen, path)
+	}
+	return enc.encodeFieldValue(field, f, bytesLen, path)
+}
+
+// encodeFieldValue encodes the value of a struct field with the field's
+// length, byte order and unit.
+func (enc *Encoder) encodeFieldValue(field reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	if err := f.check(field); err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	if f.transform != "" {
+		var err error
+		if field, err = enc.transformField(field, f, path); err != nil {
 			return err
 		}
 	}
-	return err
+	tag := f.fieldLen(bytesLen)
+	if f.order != nil {
+		defer enc.useOrder(f.order)()
+	}
+	if f.ratio != 0 && tag != -1 {
+		var err error
+		field, err = convertUnit(field, f.ratio)
+		if err != nil {
+			return enc.fail(path, field.Type(), err)
+		}
+	}
+	if f.sizedEmbed() {
+		return enc.encodeEmbedded(field, f, path)
+	}
+	return enc.encode(field, tag, f.tags, path)
+}
+
+// plainElem reports whether values of type t are fixed-size base type values
+// without custom encoding, which arrays can be written in one piece.
+func plainElem(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Uint8, reflect.Uint16, reflect.Int16,
+		reflect.Uint32, reflect.Int32, reflect.Uint64, reflect.Int64,
+		reflect.Complex64, reflect.Complex128:
+	default:
+		return false
+	}
+	if _, ok := lookupCodec(t); ok || t == durationType {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return !pt.Implements(marshalerType) && !pt.Implements(binaryMarshalerType)
+}
+
+// encodePlainArray encodes an array or slice of plainElem values with a
+// single write.
+func (enc *Encoder) encodePlainArray(v reflect.Value, bytesLen int, path int) error {
+	b := enc.scratch[:0]
+	for i := 0; i < v.Len(); i++ {
+		start := len(b)
+		b, _ = appendBaseType(b, v.Index(i), enc.intOrder())
+		if bytesLen == 0 {
+			continue
+		}
+		delta := bytesLen - (len(b) - start)
+		if delta < 0 {
+			enc.scratch = b
+			return enc.fail(enc.indexPath(path, i), v.Type().Elem(), ErrFieldTooLong)
+		}
+		for j := 0; j < delta; j++ {
+			b = append(b, enc.padByte)
+		}
+		if enc.byteOrder != binary.LittleEndian {
+			copy(b[start+delta:], b[start:len(b)-delta])
+			for j := start; j < start+delta; j++ {
+				b[j] = enc.padByte
+			}
+		}
+	}
+	enc.scratch = b
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// writePadded writes by widened to bytesLen bytes with the pad byte: the
+// padding follows by for little-endian output and precedes it otherwise. A
+// bytesLen of 0 writes by as is.
+func (enc *Encoder) writePadded(by []byte, bytesLen int) error {
+	delta := 0
+	if bytesLen != 0 {
+		delta = bytesLen - len(by)
+	}
+	if delta < 0 {
+		return ErrFieldTooLong
+	}
+	if delta > 0 && enc.byteOrder != binary.LittleEndian {
+		if err := enc.writePadding(delta); err != nil {
+			return err
+		}
+	}
+	if err := enc.write(by); err != nil {
+		return err
+	}
+	if delta > 0 && enc.byteOrder == binary.LittleEndian {
+		return enc.writePadding(delta)
+	}
+	return nil
+}
+
+func (enc *Encoder) writePadding(n int) error {
+	if len(enc.padding) < n || enc.padding[0] != enc.padByte {
+		enc.padding = bytes.Repeat([]byte{enc.padByte}, n)
+	}
+	return enc.write(enc.padding[:n])
+}
+
+// toWire replaces values of types with a dedicated wire representation by
+// that representation.
+func toWire(v reflect.Value, tags fieldTags) (reflect.Value, error) {
+	if !v.IsValid() {
+		return v, nil
+	}
+	if tags.uuid != "" {
+		return uuidToWire(v, tags.uuid)
+	}
+	if tags.encoding != "" && v.Kind() == reflect.String {
+		b, err := textToWire(v.String(), tags.encoding)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(string(b)), nil
+	}
+	if isScaled(v, tags) {
+		return scaledToWire(v, tags)
+	}
+	if isScaledDecimal(v, tags) {
+		return decimalToWire(v, tags)
+	}
+	switch v.Type() {
+	case timeType:
+		wire, err := timeToWire(v.Interface().(time.Time), tags.timefmt)
+		if err != nil {
+			return v, err
+		}
+		return wire, nil
+	case durationType:
+		wire, err := durationToWire(time.Duration(v.Int()), tags.durfmt)
+		if err != nil {
+			return v, err
+		}
+		return wire, nil
+	case ipType, ipNetType, hardwareAddrType:
+		return netToWire(v, tags.ip)
+	}
+	return v, nil
 }
 
-func encodeBaseType(data interface{}) ([]byte, error) {
-	b := make([]byte, 0)
-	v := reflect.ValueOf(data)
-	switch v.Type().Kind() {
+// appendBaseType appends the encoding of a base type value to b.
+func appendBaseType(b []byte, v reflect.Value, order binary.ByteOrder) ([]byte, error) {
+	switch v.Kind() {
 	case reflect.Bool:
 		if v.Bool() {
 			return append(b, 0x01), nil
@@ -88,75 +763,57 @@ func encodeBaseType(data interface{}) ([]byte, error) {
 
 	case reflect.Uint8:
 		return append(b, uint8(v.Uint())), nil
-	case reflect.Uint16:
-		val := uint16(v.Uint())
-		return append(b,
-			uint8(val),
-			uint8(val>>8),
-		), nil
-
-	case reflect.Int16:
-		val := uint16(v.Int())
-		return append(b,
-			uint8(val),
-			uint8(val>>8),
-		), nil
-
-	case reflect.Uint32:
-		val := uint32(v.Uint())
-		return append(b,
-			uint8(val>>0),
-			uint8(val>>8),
-			uint8(val>>16),
-			uint8(val>>24),
-		), nil
-
-	case reflect.Int32:
-		val := uint32(v.Int())
-		return append(b,
-			uint8(val>>0),
-			uint8(val>>8),
-			uint8(val>>16),
-			uint8(val>>24),
-		), nil
-
-	case reflect.Uint64:
-		val := v.Uint()
-		return append(b,
-			uint8(val>>0),
-			uint8(val>>8),
-			uint8(val>>16),
-			uint8(val>>24),
-			uint8(val>>32),
-			uint8(val>>40),
-			uint8(val>>48),
-			uint8(val>>56),
-		), nil
-
-	case reflect.Int64:
-		val := uint64(v.Int())
-		return append(b,
-			uint8(val>>0),
-			uint8(val>>8),
-			uint8(val>>16),
-			uint8(val>>24),
-			uint8(val>>32),
-			uint8(val>>40),
-			uint8(val>>48),
-			uint8(val>>56),
-		), nil
 
-	case reflect.String:
-		s := v.String()
-		b = append(b, s...)
+	case reflect.Uint16, reflect.Int16:
+		b = append(b, 0, 0)
+		order.PutUint16(b[len(b)-2:], uint16(intBits(v)))
 		return b, nil
 
+	case reflect.Uint32, reflect.Int32:
+		b = append(b, 0, 0, 0, 0)
+		order.PutUint32(b[len(b)-4:], uint32(intBits(v)))
+		return b, nil
+
+	case reflect.Uint64, reflect.Int64:
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		order.PutUint64(b[len(b)-8:], intBits(v))
+		return b, nil
+
+	case reflect.Complex64:
+		c := v.Complex()
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		order.PutUint32(b[len(b)-8:], math.Float32bits(float32(real(c))))
+		order.PutUint32(b[len(b)-4:], math.Float32bits(float32(imag(c))))
+		return b, nil
+
+	case reflect.Complex128:
+		c := v.Complex()
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+		order.PutUint64(b[len(b)-16:], math.Float64bits(real(c)))
+		order.PutUint64(b[len(b)-8:], math.Float64bits(imag(c)))
+		return b, nil
+
+	case reflect.String:
+		return append(b, v.String()...), nil
+
 	default:
-		return b, fmt.Errorf("unsupported type: " + v.Type().Kind().String())
+		return b, fmt.Errorf("%w: %s", ErrUnknownType, v.Kind())
 	}
 }
 
+// intBits returns the bits of an integer value as an unsigned number.
+func intBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
 func decodeTags(tag string, defaultTag int) int {
+	if tag == "" {
+		return defaultTag
+	}
 	if tag == "-" {
 		return -1
 	}
@@ -2,8 +2,12 @@ package binencoder_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"testing"
 
 	"github.com/milQA/binencoder"
@@ -87,6 +91,7 @@ var (
 					255, 0, 0, 0, 0, 0, 0, 0,
 					255, 0, 0, 0, 0, 0, 0, 0,
 					116, 101, 115, 116,
+					0, 0, 0, 0,
 					116, 101, 115, 116, 0, 0, 0, 0, 0, 0,
 				},
 			},
@@ -97,9 +102,9 @@ var (
 func TestOne(t *testing.T) {
 	for _, data := range dataForTests {
 		buf := new(bytes.Buffer)
-		encoder := binencoder.NewEncoder(buf, data.in.byteOrder)
+		encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(data.in.byteOrder))
 		err := encoder.Encode(data.in.data, 0)
-		equalErr(t, err.Error(), "StringLenErr")
+		equalErr(t, err.Error(), "binencoder: encoding InString4 (string): field too long")
 		equalByte(t, buf.Bytes(), data.out.answer)
 	}
 }
@@ -116,3 +121,235 @@ func equalErr(t *testing.T, answerErr, testDataErr string) {
 		t.Errorf("We have:\n%s\n got:\n%s\n", answerErr, testDataErr)
 	}
 }
+
+func TestLogger(t *testing.T) {
+	var logged []string
+	encoder := binencoder.NewEncoder(new(bytes.Buffer), binencoder.WithByteOrder(binary.LittleEndian))
+	encoder.SetLogger(binencoder.LoggerFunc(func(format string, v ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, v...))
+	}))
+	err := encoder.Encode(1.5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, fmt.Sprint(logged), "[[encodeBaseType] Error: binencoder: encoding float64: unsupported type: float64]")
+}
+
+func TestSize(t *testing.T) {
+	in := dataForTests[0].in.data
+	in.InString4 = "tes"
+	n, err := binencoder.Size(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(dataForTests[0].out.answer) + 3; n != want {
+		t.Errorf("We have: %d got: %d", want, n)
+	}
+
+	if _, err := binencoder.Size(dataForTests[0].in.data); err == nil {
+		t.Error("expected an error for a field that is too long")
+	}
+}
+
+func TestEncodeTo(t *testing.T) {
+	type message struct {
+		ID    uint32
+		Flags uint16
+		Temp  int16
+		Ok    bool
+		Name  string `len:"8"`
+		Raw   [4]uint8
+	}
+	in := message{ID: 1, Flags: 0x0203, Temp: -4, Ok: true, Name: "node"}
+	want, err := binencoder.Marshal(in, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := make([]byte, 64)
+	n, err := binencoder.EncodeTo(dst, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, dst[:n], want)
+
+	var boxed interface{} = in
+	if !raceEnabled {
+		allocs := testing.AllocsPerRun(100, func() {
+			binencoder.EncodeTo(dst, boxed)
+		})
+		if allocs != 0 {
+			t.Errorf("EncodeTo allocates %.0f times per call", allocs)
+		}
+	}
+
+	if _, err := binencoder.EncodeTo(dst[:5], in); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("short buffer: got %v", err)
+	}
+
+	// Pooled encoders must not keep the options of earlier calls.
+	for i := 0; i < 3; i++ {
+		n, err := binencoder.EncodeTo(dst, uint32(7), binencoder.WithBuffer(1024))
+		if err != nil || n != 4 {
+			t.Fatalf("with a buffer: got %d, %v", n, err)
+		}
+		equalByte(t, dst[:n], []byte{7, 0, 0, 0})
+		n, err = binencoder.EncodeTo(dst, uint32(9))
+		if err != nil || n != 4 {
+			t.Fatalf("after a buffer: got %d, %v", n, err)
+		}
+		equalByte(t, dst[:n], []byte{9, 0, 0, 0})
+	}
+}
+
+func TestEncoderReset(t *testing.T) {
+	first, second := new(bytes.Buffer), new(bytes.Buffer)
+	enc := binencoder.NewEncoder(first, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err := enc.Encode(uint16(1), 0); err != nil {
+		t.Fatal(err)
+	}
+	enc.Reset(second)
+	if err := enc.Encode(uint16(2), 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, first.Bytes(), []byte{0, 1})
+	equalByte(t, second.Bytes(), []byte{0, 2})
+}
+
+func TestEncoderClone(t *testing.T) {
+	type msg struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	enc := binencoder.NewEncoder(new(bytes.Buffer), binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	dec := binencoder.NewDecoder(new(bytes.Buffer), binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buf := new(bytes.Buffer)
+			in := msg{ID: uint16(i), Name: "ab"}
+			if err := enc.Clone(buf).Encode(in, 0); err != nil {
+				t.Error(err)
+				return
+			}
+			if buf.Bytes()[1] != byte(i) {
+				t.Errorf("clone lost the byte order: % x", buf.Bytes())
+			}
+			var out msg
+			if err := dec.Clone(buf).Decode(&out, 0); err != nil {
+				t.Error(err)
+				return
+			}
+			if out != in {
+				t.Errorf("got %+v, want %+v", out, in)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEncodeNestedAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with the race detector")
+	}
+	type point struct {
+		X, Y int32
+		Tag  string `len:"4"`
+	}
+	type frame struct {
+		Header struct {
+			ID      uint32
+			Version uint16
+		}
+		Points [16]point
+		Extra  []point
+	}
+	var in frame
+	in.Extra = make([]point, 4)
+	var boxed interface{} = &in
+	dst := make([]byte, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := binencoder.EncodeTo(dst, boxed); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("EncodeTo allocates %.0f times per call", allocs)
+	}
+}
+
+func TestEncodeN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	n, err := enc.EncodeN(dataForTests[0].in.data, 0)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if n != buf.Len() || n != len(dataForTests[0].out.answer) {
+		t.Errorf("EncodeN reported %d bytes, %d written", n, buf.Len())
+	}
+
+	n, err = enc.EncodeN(struct {
+		A uint16
+		B string `len:"6"`
+	}{}, 0)
+	if err != nil || n != 8 {
+		t.Errorf("got %d, %v", n, err)
+	}
+}
+
+func TestEncodeStream(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	i := 0
+	err := enc.EncodeStream(func() (interface{}, bool) {
+		i++
+		return uint16(i), i <= 3
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 0, 2, 0, 3, 0})
+
+	err = enc.EncodeStream(func() (interface{}, bool) {
+		return "too long", true
+	}, 2)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("got %v", err)
+	}
+}
+
+// cancelWriter cancels its context once limit bytes have been written.
+type cancelWriter struct {
+	bytes.Buffer
+	limit  int
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(b []byte) (int, error) {
+	n, err := w.Buffer.Write(b)
+	if w.Len() >= w.limit {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestEncodeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancelWriter{limit: 4, cancel: cancel}
+	err := binencoder.NewEncoder(w).EncodeContext(ctx, struct {
+		A    uint32
+		Data []uint16
+	}{1, []uint16{2, 3, 4}}, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: encoding Data ([]uint16): context canceled")
+	equalByte(t, w.Bytes(), []byte{1, 0, 0, 0})
+
+	if err := binencoder.NewEncoder(w).EncodeContext(context.Background(), uint8(1), 0); err != nil {
+		t.Error(err)
+	}
+}
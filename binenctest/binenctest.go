@@ -0,0 +1,118 @@
+// Package binenctest asserts that values encode to golden files, reporting
+// mismatches field by field. Run the tests with -binenctest.update to write
+// the golden files from the current encodings.
+package binenctest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+var update = flag.Bool("binenctest.update", false, "write golden files instead of comparing with them")
+
+// AssertGolden encodes v with opts and fails t unless the encoding equals
+// the contents of the golden file at path. The fields whose bytes differ
+// are reported with their paths and offsets.
+func AssertGolden(t testing.TB, path string, v interface{}, opts ...binencoder.Option) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf, opts...).Encode(v, 0); err != nil {
+		t.Fatalf("binenctest: encoding %T: %v", v, err)
+		return
+	}
+	got := buf.Bytes()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("binenctest: %v", err)
+			return
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("binenctest: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("binenctest: %v (run with -binenctest.update to create it)", err)
+		return
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	l, err := binencoder.Describe(v, opts...)
+	if err != nil {
+		t.Fatalf("binenctest: describing %T: %v", v, err)
+		return
+	}
+	for _, d := range diff(got, want, l) {
+		t.Errorf("binenctest: %s: %s", path, d)
+	}
+}
+
+// AssertRoundTrip checks that runs random values of the type of sample
+// round-trip with opts, as binencoder.Fuzz does for fuzzing data, and fails
+// t with the first field that does not. The values are generated from a
+// fixed seed, so failures are reproducible.
+func AssertRoundTrip(t testing.TB, sample interface{}, runs int, opts ...binencoder.Option) {
+	t.Helper()
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, 256)
+	for i := 0; i < runs; i++ {
+		r.Read(data)
+		if err := binencoder.Fuzz(sample, data, opts...); err != nil {
+			t.Fatalf("binenctest: run %d: %v", i, err)
+			return
+		}
+	}
+}
+
+// diff describes the differences between the encoding got and want, laid
+// out as l, per innermost field.
+func diff(got, want []byte, l *binencoder.Layout) []string {
+	var diffs []string
+	if len(got) != len(want) {
+		diffs = append(diffs, fmt.Sprintf("encoding has %d bytes, golden file %d", len(got), len(want)))
+	}
+	covered := 0
+	var walk func(fields []*binencoder.LayoutField)
+	walk = func(fields []*binencoder.LayoutField) {
+		for _, f := range fields {
+			if len(f.Fields) != 0 {
+				walk(f.Fields)
+				continue
+			}
+			end := f.Offset + f.Size
+			g, w := span(got, f.Offset, end), span(want, f.Offset, end)
+			if !bytes.Equal(g, w) {
+				diffs = append(diffs, fmt.Sprintf("%s at offset %d: got % x, want % x", f.Path, f.Offset, g, w))
+			}
+			if end > covered {
+				covered = end
+			}
+		}
+	}
+	walk(l.Fields)
+	if g, w := span(got, covered, len(got)), span(want, covered, len(want)); !bytes.Equal(g, w) {
+		diffs = append(diffs, fmt.Sprintf("bytes from offset %d: got % x, want % x", covered, g, w))
+	}
+	return diffs
+}
+
+// span returns b[from:to], clipped to the length of b.
+func span(b []byte, from, to int) []byte {
+	if to > len(b) {
+		to = len(b)
+	}
+	if from > to {
+		from = to
+	}
+	return b[from:to]
+}
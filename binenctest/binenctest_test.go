@@ -0,0 +1,100 @@
+package binenctest_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/milQA/binencoder/binenctest"
+)
+
+type header struct {
+	Version uint8
+	Flags   uint16 `endian:"be"`
+}
+
+type message struct {
+	Header header
+	Name   string `len:"4"`
+}
+
+// recorder records the failures reported by binenctest.AssertGolden.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestAssertGolden(t *testing.T) {
+	binenctest.AssertGolden(t, filepath.Join("testdata", "message.bin"), message{header{1, 2}, "ab"})
+
+	r := &recorder{TB: t}
+	binenctest.AssertGolden(r, filepath.Join("testdata", "message.bin"), message{header{1, 3}, "abc"})
+	want := []string{
+		"binenctest: testdata/message.bin: Header.Flags at offset 1: got 00 03, want 00 02",
+		"binenctest: testdata/message.bin: Name at offset 3: got 61 62 63 00, want 61 62 00 00",
+	}
+	if fmt.Sprint(r.errors) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", r.errors, want)
+	}
+
+	r = &recorder{TB: t}
+	binenctest.AssertGolden(r, filepath.Join("testdata", "missing.bin"), message{})
+	if !r.fatal {
+		t.Error("expected a missing golden file to be fatal")
+	}
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binenctest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "header.bin")
+
+	flag.Set("binenctest.update", "true")
+	binenctest.AssertGolden(t, path, header{1, 2})
+	flag.Set("binenctest.update", "false")
+	binenctest.AssertGolden(t, path, header{1, 2})
+
+	r := &recorder{TB: t}
+	binenctest.AssertGolden(r, path, message{})
+	want := []string{
+		"binenctest: " + path + ": encoding has 7 bytes, golden file 3",
+		"binenctest: " + path + ": Header.Version at offset 0: got 00, want 01",
+		"binenctest: " + path + ": Header.Flags at offset 1: got 00 00, want 00 02",
+		"binenctest: " + path + ": Name at offset 3: got 00 00 00 00, want ",
+	}
+	if fmt.Sprint(r.errors) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", r.errors, want)
+	}
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	binenctest.AssertRoundTrip(t, message{}, 100)
+
+	type lossy struct {
+		Name string `len:"2"`
+		Pad  uint16 `len:"1"`
+	}
+	r := &recorder{TB: t}
+	binenctest.AssertRoundTrip(r, lossy{}, 100)
+	if !r.fatal || len(r.errors) != 1 {
+		t.Errorf("expected one fatal error, got %q", r.errors)
+	}
+}
@@ -0,0 +1,214 @@
+package binencoder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BitOrder is the order in which BitWriter and BitReader fill bytes.
+type BitOrder int
+
+const (
+	// MSBFirst fills each byte from its most significant bit and writes
+	// values most significant bit first, as in RTCM and most network
+	// protocols.
+	MSBFirst BitOrder = iota
+	// LSBFirst fills each byte from its least significant bit and writes
+	// values least significant bit first, as in DEFLATE.
+	LSBFirst
+)
+
+var errBitCount = errors.New("binencoder: bit count out of range 0..64")
+
+// BitWriter writes values of any number of bits, for layouts that are
+// easier to write imperatively than to describe with struct tags. Call
+// Align or Flush to write the last partial byte.
+type BitWriter struct {
+	w     io.Writer
+	order BitOrder
+	cur   byte
+	// n is the number of bits in cur.
+	n uint
+}
+
+// NewBitWriter returns a BitWriter writing to w in the given bit order.
+func NewBitWriter(w io.Writer, order BitOrder) *BitWriter {
+	return &BitWriter{w: w, order: order}
+}
+
+// WriteBits writes the n low bits of v. It fails with ErrOverflow if v
+// does not fit in n bits.
+func (bw *BitWriter) WriteBits(v uint64, n int) error {
+	if n < 0 || n > 64 {
+		return errBitCount
+	}
+	if n < 64 && v>>uint(n) != 0 {
+		return fmt.Errorf("%w: %d does not fit in %d bits", ErrOverflow, v, n)
+	}
+	for i := 0; i < n; i++ {
+		var bit byte
+		if bw.order == MSBFirst {
+			bit = byte(v>>uint(n-1-i)) & 1
+			bw.cur |= bit << (7 - bw.n)
+		} else {
+			bit = byte(v>>uint(i)) & 1
+			bw.cur |= bit << bw.n
+		}
+		bw.n++
+		if bw.n == 8 {
+			if err := bw.flushByte(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteSigned writes v as an n-bit two's complement integer. It fails with
+// ErrOverflow if v does not fit.
+func (bw *BitWriter) WriteSigned(v int64, n int) error {
+	if n <= 0 || n > 64 {
+		return errBitCount
+	}
+	if n < 64 && (v < -1<<uint(n-1) || v >= 1<<uint(n-1)) {
+		return fmt.Errorf("%w: %d does not fit in %d signed bits", ErrOverflow, v, n)
+	}
+	if n < 64 {
+		return bw.WriteBits(uint64(v)&(1<<uint(n)-1), n)
+	}
+	return bw.WriteBits(uint64(v), n)
+}
+
+// WriteBool writes a single bit.
+func (bw *BitWriter) WriteBool(b bool) error {
+	if b {
+		return bw.WriteBits(1, 1)
+	}
+	return bw.WriteBits(0, 1)
+}
+
+// Write writes p as 8-bit values, so that it works at any bit position.
+func (bw *BitWriter) Write(p []byte) (int, error) {
+	if bw.n == 0 {
+		return bw.w.Write(p)
+	}
+	for i, b := range p {
+		if err := bw.WriteBits(uint64(b), 8); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// Align pads the current byte with zero bits and writes it, so that the
+// next value starts on a byte boundary. It does nothing if the writer is
+// aligned.
+func (bw *BitWriter) Align() error {
+	if bw.n == 0 {
+		return nil
+	}
+	return bw.flushByte()
+}
+
+// Flush is like Align and also flushes the underlying writer if it has a
+// Flush method, such as a bufio.Writer.
+func (bw *BitWriter) Flush() error {
+	if err := bw.Align(); err != nil {
+		return err
+	}
+	if f, ok := bw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (bw *BitWriter) flushByte() error {
+	b := [1]byte{bw.cur}
+	bw.cur, bw.n = 0, 0
+	_, err := bw.w.Write(b[:])
+	return err
+}
+
+// BitReader is the inverse of BitWriter.
+type BitReader struct {
+	r     io.Reader
+	order BitOrder
+	cur   byte
+	// n is the number of bits of cur not read yet.
+	n uint
+}
+
+// NewBitReader returns a BitReader reading from r in the given bit order.
+func NewBitReader(r io.Reader, order BitOrder) *BitReader {
+	return &BitReader{r: r, order: order}
+}
+
+// ReadBits reads an n-bit unsigned value. It returns io.EOF if the input
+// ends on a byte boundary before the value and an error matching
+// ErrShortMessage if it ends inside it.
+func (br *BitReader) ReadBits(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		return 0, errBitCount
+	}
+	var v uint64
+	for i := 0; i < n; i++ {
+		if br.n == 0 {
+			var b [1]byte
+			if _, err := io.ReadFull(br.r, b[:]); err != nil {
+				if err == io.EOF && i > 0 {
+					err = ErrShortMessage
+				}
+				return 0, err
+			}
+			br.cur, br.n = b[0], 8
+		}
+		br.n--
+		if br.order == MSBFirst {
+			v = v<<1 | uint64(br.cur>>br.n&1)
+		} else {
+			v |= uint64(br.cur>>(7-br.n)&1) << uint(i)
+		}
+	}
+	return v, nil
+}
+
+// ReadSigned reads an n-bit two's complement integer.
+func (br *BitReader) ReadSigned(n int) (int64, error) {
+	if n <= 0 || n > 64 {
+		return 0, errBitCount
+	}
+	v, err := br.ReadBits(n)
+	if err != nil {
+		return 0, err
+	}
+	shift := uint(64 - n)
+	return int64(v<<shift) >> shift, nil
+}
+
+// ReadBool reads a single bit.
+func (br *BitReader) ReadBool() (bool, error) {
+	v, err := br.ReadBits(1)
+	return v == 1, err
+}
+
+// Read reads len(p) 8-bit values, so that it works at any bit position.
+func (br *BitReader) Read(p []byte) (int, error) {
+	if br.n == 0 {
+		return br.r.Read(p)
+	}
+	for i := range p {
+		v, err := br.ReadBits(8)
+		if err != nil {
+			return i, err
+		}
+		p[i] = byte(v)
+	}
+	return len(p), nil
+}
+
+// Align discards the unread bits of the current byte, so that the next
+// value is read from a byte boundary.
+func (br *BitReader) Align() {
+	br.n = 0
+}
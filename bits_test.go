@@ -0,0 +1,99 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestBitWriterMSBFirst(t *testing.T) {
+	// The start of an RTCM 3 message 1005: message number, reference
+	// station ID, ITRF year and four flags.
+	buf := new(bytes.Buffer)
+	bw := binencoder.NewBitWriter(buf, binencoder.MSBFirst)
+	for _, step := range []func() error{
+		func() error { return bw.WriteBits(1005, 12) },
+		func() error { return bw.WriteBits(2003, 12) },
+		func() error { return bw.WriteBits(0, 6) },
+		func() error { return bw.WriteBool(true) },
+		func() error { return bw.WriteBool(false) },
+		func() error { return bw.WriteSigned(-3, 5) },
+		func() error { return bw.Align() },
+		func() error { _, err := bw.Write([]byte{0xab}); return err },
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	equalByte(t, buf.Bytes(), []byte{0x3e, 0xd7, 0xd3, 0x02, 0xe8, 0xab})
+
+	br := binencoder.NewBitReader(bytes.NewReader(buf.Bytes()), binencoder.MSBFirst)
+	if v, err := br.ReadBits(12); err != nil || v != 1005 {
+		t.Errorf("message number %d, %v", v, err)
+	}
+	if v, err := br.ReadBits(12); err != nil || v != 2003 {
+		t.Errorf("station %d, %v", v, err)
+	}
+	if v, err := br.ReadBits(6); err != nil || v != 0 {
+		t.Errorf("ITRF year %d, %v", v, err)
+	}
+	if b, err := br.ReadBool(); err != nil || !b {
+		t.Errorf("first flag %t, %v", b, err)
+	}
+	if b, err := br.ReadBool(); err != nil || b {
+		t.Errorf("second flag %t, %v", b, err)
+	}
+	if v, err := br.ReadSigned(5); err != nil || v != -3 {
+		t.Errorf("signed %d, %v", v, err)
+	}
+	br.Align()
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(br, b); err != nil || b[0] != 0xab {
+		t.Errorf("byte %#x, %v", b[0], err)
+	}
+	if _, err := br.ReadBits(3); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	br = binencoder.NewBitReader(bytes.NewReader([]byte{0xff}), binencoder.MSBFirst)
+	if _, err := br.ReadBits(12); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+	if err := bw.WriteBits(8, 3); !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	if err := bw.WriteSigned(-5, 3); !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestBitWriterLSBFirst(t *testing.T) {
+	buf := new(bytes.Buffer)
+	bw := binencoder.NewBitWriter(buf, binencoder.LSBFirst)
+	if err := bw.WriteBits(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.WriteBits(2, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.WriteBits(0x1ff, 9); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0xfd, 0x0f})
+
+	br := binencoder.NewBitReader(buf, binencoder.LSBFirst)
+	for _, want := range []struct {
+		n int
+		v uint64
+	}{{1, 1}, {2, 2}, {9, 0x1ff}} {
+		if v, err := br.ReadBits(want.n); err != nil || v != want.v {
+			t.Errorf("read %#x, %v, want %#x", v, err, want.v)
+		}
+	}
+}
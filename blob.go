@@ -0,0 +1,83 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isByteBlob reports whether values of type t, with length bytesLen and
+// tags, are []byte slices encoded as a whole rather than element by
+// element: with a `len` they are padded to, a `prefix` holding their
+// length or a `raw:"true"` tag.
+func isByteBlob(t reflect.Type, bytesLen int, tags fieldTags) bool {
+	return t.Kind() == reflect.Slice && t.Elem() == uint8Type && !customEncoding(t.Elem()) &&
+		(bytesLen != 0 || tags.prefix != "" || tags.raw == "true")
+}
+
+// parseRawTag checks the value of a `raw` tag.
+func parseRawTag(tag string) error {
+	switch tag {
+	case "", "true", "false":
+		return nil
+	}
+	return fmt.Errorf("binencoder: invalid raw tag %q", tag)
+}
+
+// encodeByteBlob writes the []byte v as is, preceded by the length from
+// its `prefix` tag and padded to bytesLen. Longer slices are cut to
+// bytesLen by an `overflow` tag or fail with ErrFieldTooLong.
+func (enc *Encoder) encodeByteBlob(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	if err := parseRawTag(tags.raw); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	b := v.Bytes()
+	if bytesLen != 0 && len(b) > bytesLen {
+		if tags.overflow == "" {
+			return enc.fail(path, v.Type(), ErrFieldTooLong)
+		}
+		left, err := truncateLeft(tags.overflow)
+		if err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+		if left {
+			b = b[len(b)-bytesLen:]
+		} else {
+			b = b[:bytesLen]
+		}
+	}
+	if err := enc.writeBytes(b, bytesLen, tags.prefix); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeByteBlob is the inverse of Encoder.encodeByteBlob. Without a
+// `len` or a `prefix` it reads as many bytes as v holds; with a `len` alone
+// it reads all of them, padding included.
+func (dec *Decoder) decodeByteBlob(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	if err := parseRawTag(tags.raw); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	n := v.Len()
+	if bytesLen != 0 {
+		n = bytesLen
+	}
+	var b []byte
+	var err error
+	switch {
+	case tags.prefix != "":
+		b, err = dec.readBytes(bytesLen, tags.prefix)
+	case dec.borrowing():
+		b, err = dec.readView(n)
+	default:
+		if err = dec.reserve(n); err == nil {
+			b = make([]byte, n)
+			err = dec.readFull(b)
+		}
+	}
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	v.SetBytes(b)
+	return nil
+}
@@ -0,0 +1,55 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestByteBlob(t *testing.T) {
+	type frame struct {
+		Key     []byte `len:"4"`
+		Payload []byte `prefix:"u16"`
+		Tail    []byte `raw:"true"`
+	}
+	in := frame{Key: []byte{1, 2}, Payload: []byte("abc"), Tail: []byte{9, 8}}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithPadByte(0xee))
+	if err := enc.Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0xee, 0xee, 1, 2, 0, 3, 'a', 'b', 'c', 9, 8})
+
+	out := frame{Tail: make([]byte, 2)}
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.BigEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, out.Key, []byte{0xee, 0xee, 1, 2})
+	equalByte(t, out.Payload, []byte("abc"))
+	equalByte(t, out.Tail, []byte{9, 8})
+
+	type cut struct {
+		Head []byte `len:"2" overflow:"truncate"`
+		End  []byte `len:"2" overflow:"truncate-left"`
+	}
+	buf.Reset()
+	if err := binencoder.NewEncoder(buf).Encode(cut{Head: []byte{1, 2, 3}, End: []byte{4, 5, 6}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 2, 5, 6})
+
+	err := binencoder.NewEncoder(buf).Encode(frame{Key: []byte{1, 2, 3, 4, 5}}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+	type badRaw struct {
+		Data []byte `raw:"yes"`
+	}
+	if err := binencoder.CheckType(reflect.TypeOf(badRaw{})); err == nil {
+		t.Error("expected an error for an invalid raw tag")
+	}
+}
@@ -0,0 +1,54 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// BlockSizer is implemented by structs stored in blocks of a fixed size,
+// e.g. the sectors of an on-disk format. The encoded struct is padded with
+// the pad byte to BlockSize bytes, and decoding consumes all of them.
+// Structs encoding to more than BlockSize bytes give an error matching
+// ErrFieldTooLong.
+type BlockSizer interface {
+	BlockSize() int
+}
+
+var blockSizerType = reflect.TypeOf((*BlockSizer)(nil)).Elem()
+
+// structBlockSize returns the block size struct v declares, or 0.
+func structBlockSize(v reflect.Value) int {
+	if !implements(v, blockSizerType) {
+		return 0
+	}
+	if b, ok := v.Interface().(BlockSizer); ok {
+		return b.BlockSize()
+	}
+	return v.Addr().Interface().(BlockSizer).BlockSize()
+}
+
+// blockOverflow reports a struct taking n bytes of a block of size bytes.
+func blockOverflow(n, size int) error {
+	return fmt.Errorf("%w: %d bytes in a block of %d", ErrFieldTooLong, n, size)
+}
+
+// padBlock pads the struct encoded from position start to size bytes.
+func (enc *Encoder) padBlock(start, size int) error {
+	n := enc.n - start
+	if n > size {
+		return blockOverflow(n, size)
+	}
+	if n < size {
+		return enc.writePadding(size - n)
+	}
+	return nil
+}
+
+// skipBlock skips the rest of the block of size bytes holding the struct
+// decoded from position start.
+func (dec *Decoder) skipBlock(start, size int) error {
+	if n := dec.n - start; n > size {
+		return blockOverflow(n, size)
+	}
+	return dec.seek(start + size)
+}
@@ -0,0 +1,54 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type sector struct {
+	Magic uint16
+	Count uint8
+	Name  string `len:"4"`
+}
+
+func (sector) BlockSize() int { return 16 }
+
+func TestBlockSizer(t *testing.T) {
+	type disk struct {
+		Boot sector
+		Tail uint8
+	}
+	in := disk{Boot: sector{Magic: 0xaa55, Count: 2, Name: "boot"}, Tail: 9}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithPadByte(0xff)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x55, 0xaa, 2, 'b', 'o', 'o', 't'}
+	want = append(want, bytes.Repeat([]byte{0xff}, 9)...)
+	equalByte(t, buf.Bytes(), append(want, 9))
+
+	var out disk
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	if n, err := binencoder.Size(in.Boot); err != nil || n != 16 {
+		t.Errorf("Size is %d, %v", n, err)
+	}
+
+	err := binencoder.NewEncoder(buf).Encode(bigSector{}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+}
+
+type bigSector struct {
+	Data [20]byte
+}
+
+func (bigSector) BlockSize() int { return 16 }
@@ -0,0 +1,99 @@
+package binencoder
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+// WithBorrow makes a Decoder reading from a buffer set with ResetBytes
+// decode strings and []byte slices as views into the buffer instead of
+// copies, so that parsing a message allocates nothing. The views are valid
+// as long as the buffer is: the caller must not modify or reuse it while
+// the decoded values are in use, and must copy the values that outlive it.
+// With other readers the option has no effect.
+func WithBorrow(enabled bool) Option {
+	return func(c *config) {
+		c.borrow = enabled
+	}
+}
+
+// ResetBytes makes the Decoder read from buf, keeping its options, so that
+// one Decoder can parse packet after packet. Fixed-size values are decoded
+// from buf in place rather than copied out first.
+func (dec *Decoder) ResetBytes(buf []byte) {
+	dec.src = bytesReader{b: buf}
+	dec.r = &dec.src
+}
+
+// bytesReader is the reader of a Decoder set with ResetBytes.
+type bytesReader struct {
+	b   []byte
+	off int
+}
+
+func (r *bytesReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.off:])
+	r.off += n
+	return n, nil
+}
+
+func (r *bytesReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64(r.off)
+	case io.SeekEnd:
+		offset += int64(len(r.b))
+	default:
+		return 0, errors.New("binencoder: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("binencoder: negative position")
+	}
+	r.off = int(offset)
+	return offset, nil
+}
+
+// readView returns the next n bytes of the input: a view of the buffer set
+// with ResetBytes, or else a new slice read from the reader.
+func (dec *Decoder) readView(n int) ([]byte, error) {
+	if err := dec.reserve(n); err != nil {
+		return nil, err
+	}
+	if dec.r != io.Reader(&dec.src) {
+		b := make([]byte, n)
+		return b, dec.readFull(b)
+	}
+	src := &dec.src
+	if src.off+n > len(src.b) {
+		dec.n += len(src.b) - src.off
+		src.off = len(src.b)
+		return nil, ErrShortMessage
+	}
+	b := src.b[src.off : src.off+n : src.off+n]
+	src.off += n
+	dec.n += n
+	return b, nil
+}
+
+// borrowing reports whether strings and byte slices are decoded as views.
+func (dec *Decoder) borrowing() bool {
+	return dec.borrow && dec.r == io.Reader(&dec.src)
+}
+
+// isByteView reports whether v is a []byte that can be decoded as a view.
+func isByteView(v reflect.Value, bytesLen int, tags fieldTags) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem() == uint8Type && bytesLen == 0 && tags.encoding == ""
+}
+
+var uint8Type = reflect.TypeOf(uint8(0))
+
+// viewString returns a string sharing the memory of b.
+func viewString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
@@ -0,0 +1,67 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestBorrow(t *testing.T) {
+	type packet struct {
+		Seq     uint16
+		Name    string `len:"6"`
+		Payload []byte
+	}
+	in := packet{Seq: 3, Name: "probe", Payload: []byte{1, 2, 3, 4}}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	wire := buf.Bytes()
+
+	dec := binencoder.NewDecoder(nil, binencoder.WithBorrow(true))
+	out := packet{Payload: make([]byte, 4)}
+	dec.ResetBytes(wire)
+	if err := dec.Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Seq != 3 || out.Name != "probe" || !bytes.Equal(out.Payload, in.Payload) {
+		t.Errorf("got %+v", out)
+	}
+	wire[len(wire)-1] = 9
+	if out.Payload[3] != 9 {
+		t.Error("Payload is not a view of the buffer")
+	}
+	if cap(out.Payload) != 4 {
+		t.Errorf("Payload has capacity %d, want 4", cap(out.Payload))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		dec.ResetBytes(wire)
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Decode allocates %.0f times per call", allocs)
+	}
+
+	// Without the option the values are copied.
+	copying := binencoder.NewDecoder(nil)
+	copying.ResetBytes(wire)
+	out.Payload = make([]byte, 4)
+	if err := copying.Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	wire[len(wire)-1] = 4
+	if out.Payload[3] != 9 {
+		t.Error("Payload shares the buffer without WithBorrow")
+	}
+
+	dec.ResetBytes(wire[:len(wire)-2])
+	if err := dec.Decode(&out, 0); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package binencoder
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithBuffer makes an Encoder collect the values it encodes in memory and
+// write them to the underlying writer with a single call once n bytes of
+// complete values are buffered, or on Flush, instead of a write per field.
+// A value that fails to encode is dropped from the buffer. If the writer is
+// a FrameWriter, each value still ends a frame of its own. Decoders ignore
+// it.
+func WithBuffer(n int) Option {
+	return func(c *config) {
+		c.bufSize = n
+	}
+}
+
+// frameBuffer buffers the values of an Encoder created WithBuffer. It is a
+// FrameWriter, so the Encoder marks where each value ends, and an
+// io.Seeker within the value being encoded, for `offset` tags.
+type frameBuffer struct {
+	w    io.Writer
+	size int
+	buf  []byte
+	// start is where the value being encoded starts and pos the position
+	// written to next.
+	start int
+	pos   int
+	// ends holds the end of each buffered value while w is a FrameWriter.
+	ends []int
+}
+
+func (fb *frameBuffer) Write(p []byte) (int, error) {
+	n := copy(fb.buf[fb.pos:], p)
+	fb.buf = append(fb.buf, p[n:]...)
+	fb.pos += len(p)
+	return len(p), nil
+}
+
+// Seek moves within the value being encoded; only io.SeekCurrent is
+// supported.
+func (fb *frameBuffer) Seek(offset int64, whence int) (int64, error) {
+	pos := fb.pos + int(offset)
+	if whence != io.SeekCurrent || pos < fb.start || pos > len(fb.buf) {
+		return 0, fmt.Errorf("binencoder: buffered encoder cannot seek to %d", pos-fb.start)
+	}
+	fb.pos = pos
+	return int64(pos - fb.start), nil
+}
+
+// EndFrame completes the value being encoded and flushes the buffer once
+// it holds size bytes.
+func (fb *frameBuffer) EndFrame() error {
+	fb.start, fb.pos = len(fb.buf), len(fb.buf)
+	if _, ok := fb.w.(FrameWriter); ok {
+		fb.ends = append(fb.ends, fb.start)
+	}
+	if fb.start >= fb.size {
+		return fb.flush()
+	}
+	return nil
+}
+
+// AbortFrame drops the value being encoded.
+func (fb *frameBuffer) AbortFrame() {
+	fb.buf = fb.buf[:fb.start]
+	fb.pos = fb.start
+}
+
+// flush writes the complete values to w.
+func (fb *frameBuffer) flush() error {
+	if fb.start == 0 {
+		return nil
+	}
+	var err error
+	if fw, ok := fb.w.(FrameWriter); ok {
+		prev := 0
+		for _, end := range fb.ends {
+			if _, err = fw.Write(fb.buf[prev:end]); err == nil {
+				err = fw.EndFrame()
+			}
+			if err != nil {
+				fw.AbortFrame()
+				break
+			}
+			prev = end
+		}
+		fb.ends = fb.ends[:0]
+	} else {
+		_, err = fb.w.Write(fb.buf[:fb.start])
+	}
+	fb.reset(fb.w)
+	return err
+}
+
+// reset makes fb write to w, discarding the buffered values.
+func (fb *frameBuffer) reset(w io.Writer) {
+	fb.w = w
+	fb.buf = fb.buf[:0]
+	fb.start, fb.pos = 0, 0
+	fb.ends = fb.ends[:0]
+}
+
+// Flush writes the values buffered by an Encoder created WithBuffer to the
+// underlying writer. The values are dropped even if the write fails. It
+// does nothing for other encoders.
+func (enc *Encoder) Flush() error {
+	if enc.buffer == nil {
+		return nil
+	}
+	return enc.buffer.flush()
+}
+
+// setWriter makes enc write to w, through its buffer if it has one.
+func (enc *Encoder) setWriter(w io.Writer) {
+	if enc.bufSize <= 0 {
+		enc.w = w
+		return
+	}
+	if enc.buffer == nil {
+		enc.buffer = &frameBuffer{size: enc.bufSize}
+	}
+	enc.buffer.reset(w)
+	enc.w = enc.buffer
+}
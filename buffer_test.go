@@ -0,0 +1,75 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+// writeCounter records the calls made to Write.
+type writeCounter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestWithBuffer(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	w := new(writeCounter)
+	enc := binencoder.NewEncoder(w, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true), binencoder.WithBuffer(16))
+	if err := enc.Encode(message{1, "ab"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(message{2, "too long"}, 0); !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Fatalf("expected ErrFieldTooLong, got %v", err)
+	}
+	if err := enc.Encode(message{3, "c"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes != 0 {
+		t.Fatalf("%d writes before Flush", w.writes)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes != 1 {
+		t.Errorf("Flush made %d writes, want 1", w.writes)
+	}
+	equalByte(t, w.Bytes(), []byte{0, 1, 0, 0, 'a', 'b', 0, 3, 0, 0, 0, 'c'})
+
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(message{uint16(i), "abc"}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.writes != 2 || w.Len() != 12+18 {
+		t.Errorf("got %d writes of %d bytes, want a write once 16 bytes are buffered", w.writes, w.Len())
+	}
+}
+
+func TestWithBufferFrames(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(binencoder.NewCOBSWriter(buf), binencoder.WithBuffer(64))
+	for _, v := range []uint16{0x0011, 3} {
+		if err := enc.Encode(v, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Fatal("frames written before Flush")
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{2, 0x11, 1, 0, 2, 3, 1, 0})
+}
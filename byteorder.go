@@ -0,0 +1,27 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"reflect"
+)
+
+// ByteOrderer is implemented by structs with a byte order of their own. It
+// replaces the byte order of the Encoder or Decoder, and of an `endian` tag
+// on the field holding the struct, for the struct and the values in it;
+// `endian` tags inside the struct still apply.
+type ByteOrderer interface {
+	ByteOrder() binary.ByteOrder
+}
+
+var byteOrdererType = reflect.TypeOf((*ByteOrderer)(nil)).Elem()
+
+// structByteOrder returns the byte order struct v declares, or nil.
+func structByteOrder(v reflect.Value) binary.ByteOrder {
+	if !implements(v, byteOrdererType) {
+		return nil
+	}
+	if o, ok := v.Interface().(ByteOrderer); ok {
+		return o.ByteOrder()
+	}
+	return v.Addr().Interface().(ByteOrderer).ByteOrder()
+}
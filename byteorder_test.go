@@ -0,0 +1,39 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+// vendorBlob is laid out big-endian whatever the envelope uses.
+type vendorBlob struct {
+	Magic uint16
+	Size  uint32 `endian:"le"`
+}
+
+func (vendorBlob) ByteOrder() binary.ByteOrder { return binary.BigEndian }
+
+func TestByteOrderer(t *testing.T) {
+	type envelope struct {
+		Seq  uint16
+		Blob vendorBlob
+		CRC  uint16
+	}
+	in := envelope{Seq: 1, Blob: vendorBlob{Magic: 0xcafe, Size: 2}, CRC: 3}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 0, 0xca, 0xfe, 2, 0, 0, 0, 3, 0})
+
+	var out envelope
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
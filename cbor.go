@@ -0,0 +1,108 @@
+package binencoder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// CBOR major types (RFC 8949).
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborSimple = 7
+)
+
+// appendCBOR appends the CBOR encoding of v to b. Structs are written as
+// maps keyed by field name, in field order, and maps with their entries
+// sorted by the encoding of the keys, the deterministic order of RFC 8949.
+func (enc *Encoder) appendCBOR(b []byte, v reflect.Value, tags fieldTags, path string) ([]byte, error) {
+	w, err := enc.formatValue(v, tags)
+	if err != nil {
+		return nil, newEncodeError(path, v.Type(), err)
+	}
+	v = w
+	if !v.IsValid() {
+		return append(b, cborSimple<<5|22), nil
+	}
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			return append(b, cborSimple<<5|21), nil
+		}
+		return append(b, cborSimple<<5|20), nil
+	case isInt(v.Kind()):
+		if x := v.Int(); x < 0 {
+			return appendCBORHead(b, cborNegInt, uint64(-1-x)), nil
+		}
+		return appendCBORHead(b, cborUint, uint64(v.Int())), nil
+	case isUint(v.Kind()):
+		return appendCBORHead(b, cborUint, v.Uint()), nil
+	case v.Kind() == reflect.Float32:
+		return appendUint32(append(b, cborSimple<<5|26), math.Float32bits(float32(v.Float()))), nil
+	case v.Kind() == reflect.Float64:
+		return appendUint64(append(b, cborSimple<<5|27), math.Float64bits(v.Float())), nil
+	case v.Kind() == reflect.String:
+		b = appendCBORHead(b, cborText, uint64(v.Len()))
+		return append(b, v.String()...), nil
+	case isBytes(v):
+		b = appendCBORHead(b, cborBytes, uint64(v.Len()))
+		return append(b, bytesOf(v)...), nil
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		b = appendCBORHead(b, cborArray, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if b, err = enc.appendCBOR(b, v.Index(i), tags, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case v.Kind() == reflect.Map:
+		entries, err := formatEntries(v, tags, path, enc.appendCBOR)
+		if err != nil {
+			return nil, err
+		}
+		b = appendCBORHead(b, cborMap, uint64(len(entries)))
+		for _, e := range entries {
+			b = append(append(b, e.key...), e.value...)
+		}
+		return b, nil
+	case v.Kind() == reflect.Struct:
+		fields, err := enc.formatFields(v, path)
+		if err != nil {
+			return nil, err
+		}
+		fields = presentFields(fields)
+		b = appendCBORHead(b, cborMap, uint64(len(fields)))
+		for _, f := range fields {
+			b = appendCBORHead(b, cborText, uint64(len(f.plan.name)))
+			b = append(b, f.plan.name...)
+			if b, err = enc.appendCBOR(b, f.value, f.plan.tags, f.path); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, newEncodeError(path, v.Type(), fmt.Errorf("%w: %s in CBOR", ErrUnknownType, v.Kind()))
+}
+
+// appendCBORHead appends the initial byte of an item of the major type with
+// argument n, followed by n in the shortest form that holds it.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return appendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return appendUint32(append(b, major|26), uint32(n))
+	}
+	return appendUint64(append(b, major|27), n)
+}
@@ -0,0 +1,49 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFormatCBOR(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Neg  int32
+		Name string
+		Data [2]byte
+		List []uint32
+		Ok   bool
+		Opt  *uint8
+		F    float32
+	}
+	in := message{ID: 500, Neg: -25, Name: "ab", Data: [2]byte{1, 2}, List: []uint32{1, 1000000}, Ok: true, F: 1.5}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatCBOR)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0xa7,
+		0x62, 'I', 'D', 0x19, 0x01, 0xf4,
+		0x63, 'N', 'e', 'g', 0x38, 0x18,
+		0x64, 'N', 'a', 'm', 'e', 0x62, 'a', 'b',
+		0x64, 'D', 'a', 't', 'a', 0x42, 0x01, 0x02,
+		0x64, 'L', 'i', 's', 't', 0x82, 0x01, 0x1a, 0x00, 0x0f, 0x42, 0x40,
+		0x62, 'O', 'k', 0xf5,
+		0x61, 'F', 0xfa, 0x3f, 0xc0, 0x00, 0x00,
+	})
+}
+
+func TestFormatCBORMap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	in := map[int8][]bool{-1: {true}, 10: nil}
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatCBOR)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0xa2,
+		0x0a, 0x80,
+		0x20, 0x81, 0xf5,
+	})
+}
@@ -0,0 +1,103 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ExportCHeader returns packed C struct declarations matching the layout
+// the given structs are encoded with by an Encoder with the default
+// options, for firmware consuming the messages. Every struct becomes a
+// typedef named after its Go type in snake case, declared after the types
+// it uses, with a static assertion of its size. Slices and strings without
+// a `len` tag are declared with the length they have in the values.
+func ExportCHeader(types ...interface{}) (string, error) {
+	w := newLayoutWalker("C", nil)
+	for _, v := range types {
+		rv, ok := structValue(v)
+		if !ok {
+			return "", newEncodeError("", reflect.TypeOf(v), fmt.Errorf("%w: ExportCHeader needs structs", ErrUnknownType))
+		}
+		if _, err := w.structType(rv, 0, "message", ""); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("/* Code generated by binencoder.ExportCHeader. DO NOT EDIT. */\n\n")
+	b.WriteString("#include <stdint.h>\n\n")
+	b.WriteString("/* Multi-byte fields are " + cEndian(w.intOrder()) + " unless noted. */\n")
+	b.WriteString("#pragma pack(push, 1)\n")
+	for _, t := range w.types {
+		b.WriteString("\ntypedef struct {\n")
+		size := 0
+		for _, attr := range t.attrs {
+			size += attr.byteSize()
+			writeCField(&b, attr, w.intOrder())
+		}
+		b.WriteString("} " + t.name + ";\n")
+		b.WriteString("_Static_assert(sizeof(" + t.name + ") == " + strconv.Itoa(size) + ", \"" + t.name + " layout\");\n")
+	}
+	b.WriteString("\n#pragma pack(pop)\n")
+	return b.String(), nil
+}
+
+func writeCField(b *strings.Builder, attr layoutAttr, meta binary.ByteOrder) {
+	var typ string
+	n := -1
+	switch attr.kind {
+	case layoutInt:
+		typ = "uint"
+		if attr.signed {
+			typ = "int"
+		}
+		typ += strconv.Itoa(attr.size*8) + "_t"
+	case layoutBytes:
+		typ, n = "uint8_t", attr.size
+	case layoutString:
+		typ, n = "char", attr.size
+	case layoutStruct:
+		typ = attr.typ.name
+	}
+	if attr.repeated {
+		n = attr.count
+	}
+	if n == 0 {
+		b.WriteString("\t/* " + attr.id + ": empty */\n")
+		return
+	}
+	b.WriteString("\t" + typ + " " + attr.id)
+	if n > 0 {
+		b.WriteString("[" + strconv.Itoa(n) + "]")
+	}
+	b.WriteString(";")
+	if attr.kind == layoutInt && attr.size > 1 && attr.order != meta {
+		b.WriteString(" /* " + cEndian(attr.order) + " */")
+	}
+	b.WriteString("\n")
+}
+
+func cEndian(order binary.ByteOrder) string {
+	if order == binary.BigEndian {
+		return "big-endian"
+	}
+	return "little-endian"
+}
+
+// byteSize returns the number of bytes attr spans.
+func (attr layoutAttr) byteSize() int {
+	size := attr.size
+	if attr.kind == layoutStruct {
+		size = 0
+		for _, a := range attr.typ.attrs {
+			size += a.byteSize()
+		}
+	}
+	if attr.repeated {
+		size *= attr.count
+	}
+	return size
+}
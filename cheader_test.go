@@ -0,0 +1,65 @@
+package binencoder_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestExportCHeader(t *testing.T) {
+	type ack struct {
+		Code uint8
+		Seq  uint32 `endian:"be"`
+	}
+	msg := ksyMessage{Values: make([]uint16, 3)}
+	got, err := binencoder.ExportCHeader(msg, &ack{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, got, `/* Code generated by binencoder.ExportCHeader. DO NOT EDIT. */
+
+#include <stdint.h>
+
+/* Multi-byte fields are little-endian unless noted. */
+#pragma pack(push, 1)
+
+typedef struct {
+	uint8_t version;
+	uint16_t flags; /* big-endian */
+} ksy_header;
+_Static_assert(sizeof(ksy_header) == 3, "ksy_header layout");
+
+typedef struct {
+	ksy_header header;
+	uint32_t in_id;
+	char name[8];
+	int16_t padded;
+	uint8_t padded_pad[2];
+	uint16_t values[3];
+	uint8_t raw[4];
+	int64_t time;
+} ksy_message;
+_Static_assert(sizeof(ksy_message) == 37, "ksy_message layout");
+
+typedef struct {
+	uint8_t code;
+	uint32_t seq; /* big-endian */
+} ack;
+_Static_assert(sizeof(ack) == 5, "ack layout");
+
+#pragma pack(pop)
+`)
+
+	size, err := binencoder.Size(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 37 {
+		t.Errorf("encoded size %d does not match the header", size)
+	}
+
+	if _, err := binencoder.ExportCHeader(1); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
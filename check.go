@@ -0,0 +1,197 @@
+package binencoder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var bytesMethodType = reflect.TypeOf((*interface{ Bytes() []byte })(nil)).Elem()
+
+// CheckType reports the first problem in the tags and field types of t
+// that encoding with opts would run into: invalid tag values, `sizeof`
+// fields naming unknown fields and types that cannot be encoded, which a
+// non-strict Encoder skips. It needs no value, so that misconfigured
+// schemas fail at startup or in tests. Problems with particular values,
+// such as strings longer than their `len`, are left to Encode.
+func CheckType(t reflect.Type, opts ...Option) error {
+	var c config
+	c.init(opts)
+	return c.checkType(t, fieldTags{}, "", map[reflect.Type]bool{}, map[reflect.Type]bool{})
+}
+
+// recursiveType reports a struct type t whose zero value, which nil
+// pointers encode as, contains itself.
+func recursiveType(t reflect.Type) error {
+	return fmt.Errorf("%w: %s contains itself through a pointer", ErrUnknownType, t)
+}
+
+// checkType checks type t reached at path with tags. Structs are checked
+// once, as their fields do not depend on the tags of the field holding
+// them. open holds the structs t is reached from through pointers, arrays
+// and fields only: reaching one of them again is a type that cannot be
+// encoded, as nil pointers are encoded as zero values.
+func (c *config) checkType(t reflect.Type, tags fieldTags, path string, seen, open map[reflect.Type]bool) error {
+	if err := checkTags(t, tags); err != nil {
+		return newEncodeError(path, t, err)
+	}
+	if custom, err := customWire(t, tags); custom || err != nil {
+		if err == nil {
+			err = c.checkGenerated(t)
+		}
+		if err != nil {
+			return newEncodeError(path, t, err)
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Uint8, reflect.Uint16, reflect.Int16, reflect.Uint32, reflect.Int32,
+		reflect.Uint64, reflect.Int64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return nil
+	case reflect.Array:
+		return c.checkType(t.Elem(), tags, path+"[]", seen, open)
+	case reflect.Slice:
+		return c.checkType(t.Elem(), tags, path+"[]", seen, map[reflect.Type]bool{})
+	case reflect.Map:
+		prefix := tags.prefix
+		if prefix == "" {
+			prefix = defaultMapPrefix
+		}
+		if _, err := prefixWidth(prefix); err != nil {
+			return newEncodeError(path, t, err)
+		}
+		tags.prefix = ""
+		if err := c.checkType(t.Key(), tags, path+"[key]", seen, map[reflect.Type]bool{}); err != nil {
+			return err
+		}
+		return c.checkType(t.Elem(), tags, path+"[]", seen, map[reflect.Type]bool{})
+	case reflect.Ptr:
+		return c.checkType(t.Elem(), tags, path, seen, open)
+	case reflect.Struct:
+		if open[t] {
+			return newEncodeError(path, t, recursiveType(t))
+		}
+		if seen[t] {
+			return nil
+		}
+		seen[t] = true
+		open[t] = true
+		defer delete(open, t)
+		plan := c.structPlan(t)
+		for i := range plan {
+			f := &plan[i]
+			field := t.Field(f.index)
+			fieldPath := joinPath(path, f.name)
+			if err := c.fieldErr(f); err != nil {
+				return newEncodeError(fieldPath, field.Type, err)
+			}
+			if f.transform != "" {
+				if _, err := lookupTransform(f.transform); err != nil {
+					return newEncodeError(fieldPath, field.Type, err)
+				}
+			}
+			if f.len == -1 || f.sizeFrom >= 0 && f.compress == "" {
+				continue
+			}
+			if err := c.checkType(field.Type, f.tags, fieldPath, seen, open); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return newEncodeError(path, t, fmt.Errorf("%w: %s", ErrUnknownType, t.Kind()))
+}
+
+// customWire reports whether values of type t with tags have a wire
+// representation of their own, checking the tags it depends on.
+func customWire(t reflect.Type, tags fieldTags) (bool, error) {
+	if _, ok := lookupCodec(t); ok {
+		return true, nil
+	}
+	kind := t.Kind()
+	switch {
+	case isAsText(t, tags):
+		if err := parseAsTag(tags.as); err != nil {
+			return true, err
+		}
+		if !reflect.PtrTo(t).Implements(textMarshalerType) || !reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			return true, fmt.Errorf("%w: %s does not implement encoding.TextMarshaler and encoding.TextUnmarshaler", ErrUnknownType, t)
+		}
+		return true, nil
+	case kind == reflect.Interface:
+		if tags.typeid == "" {
+			return true, fmt.Errorf("%w: interface without a typeid tag", ErrUnknownType)
+		}
+		_, err := prefixWidth(tags.typeid)
+		return true, err
+	case kind == reflect.String && tags.strenc != "":
+		_, err := parseStrenc(tags.strenc)
+		return true, err
+	case kind == reflect.String && tags.dns != "":
+		_, err := parseDNSTag(tags.dns)
+		return true, err
+	case t == bigIntType:
+		_, err := bigIntSigned(tags.bigint)
+		return true, err
+	case tags.uuid != "":
+		if _, err := uuidLayout(make([]byte, uuidLen), tags.uuid); err != nil {
+			return true, err
+		}
+		if !isUUIDArray(t) && !t.Implements(bytesMethodType) && !t.Implements(binaryMarshalerType) {
+			return true, fmt.Errorf("%w: %s is not a UUID", ErrUnknownType, t)
+		}
+		return true, nil
+	case tags.encoding != "" && isVarintKind(kind):
+		_, err := parseVarint(tags.encoding)
+		return true, err
+	case kind == reflect.String && tags.encoding != "":
+		_, err := textFromWire(nil, tags.encoding)
+		return false, err
+	case (kind == reflect.Float32 || kind == reflect.Float64) && (tags.fixed != "" || tags.scale != ""):
+		_, err := parseScaled(tags)
+		return true, err
+	case t == decimalType && tags.scale != "":
+		s, err := parseScaled(fieldTags{scale: tags.scale})
+		if err == nil {
+			_, err = decimalPlaces(s.factor)
+		}
+		return true, err
+	case t == timeType:
+		_, err := timeWireType(tags.timefmt)
+		return true, err
+	case t == durationType:
+		if _, ok := durationUnits[tags.durfmt]; !ok {
+			return true, fmt.Errorf("binencoder: unknown durfmt %q", tags.durfmt)
+		}
+		return true, nil
+	case t == ipType || t == ipNetType:
+		_, err := ipWidth(nil, tags.ip)
+		return true, err
+	case t == hardwareAddrType || t == readerType || t == rawBytesType:
+		return true, nil
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(marshalerType) || pt.Implements(binaryMarshalerType), nil
+}
+
+// checkTags checks the `raw` tag and the string tags that do not change how
+// values of type t are encoded, only what happens to them.
+func checkTags(t reflect.Type, tags fieldTags) error {
+	if err := parseRawTag(tags.raw); err != nil {
+		return err
+	}
+	if t.Kind() != reflect.String {
+		return nil
+	}
+	switch tags.compact {
+	case "", "ellipsis", "hash", "abbrev":
+	default:
+		return fmt.Errorf("binencoder: unknown compact policy %q", tags.compact)
+	}
+	if tags.overflow != "" {
+		if _, err := truncateLeft(tags.overflow); err != nil && !errors.Is(err, ErrFieldTooLong) {
+			return err
+		}
+	}
+	return nil
+}
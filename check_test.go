@@ -0,0 +1,63 @@
+package binencoder_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestCheckType(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type good struct {
+		Name   string `len:"8" overflow:"truncate"`
+		When   time.Time
+		Points []point
+		Origin *point
+		Next   []good
+		Attrs  map[string]uint32 `prefix:"u8" len:"4"`
+	}
+	type node struct {
+		V    uint8
+		Next *node
+	}
+	if err := binencoder.CheckType(reflect.TypeOf(good{})); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		typ  interface{}
+		path string
+	}{
+		{struct {
+			Inner struct {
+				When time.Time `timefmt:"unix16"`
+			}
+		}{}, "Inner.When"},
+		{struct {
+			Size uint16 `sizeof:"Body"`
+		}{}, "Size"},
+		{struct{ Ratio []float32 }{}, "Ratio[]"},
+		{struct {
+			Name string `len:"4" overflow:"wrap"`
+		}{}, "Name"},
+		{struct{ Any interface{} }{}, "Any"},
+		{struct{ Head node }{}, "Head.Next"},
+	} {
+		err := binencoder.CheckType(reflect.TypeOf(tc.typ))
+		var encErr *binencoder.EncodeError
+		if !errors.As(err, &encErr) || encErr.Path != tc.path {
+			t.Errorf("%T: expected an error for %s, got %v", tc.typ, tc.path, err)
+		}
+	}
+
+	err := binencoder.CheckType(reflect.TypeOf(struct{ N int }{}))
+	if !errors.Is(err, binencoder.ErrUnknownType) || !strings.Contains(err.Error(), "N") {
+		t.Errorf("expected ErrUnknownType for N, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package binencoder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// checksum is the algorithm of the trailer set by WithTrailerChecksum or
+// WithTrailer.
+type checksum struct {
+	name string
+	// new is nil for a name that is not registered.
+	new func() hash.Hash
+}
+
+var (
+	checksumsMu sync.RWMutex
+	checksums   = map[string]func() hash.Hash{
+		"crc32":      func() hash.Hash { return crc32.NewIEEE() },
+		"crc32c":     func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+		"crc64-iso":  func() hash.Hash { return crc64.New(crc64.MakeTable(crc64.ISO)) },
+		"crc64-ecma": func() hash.Hash { return crc64.New(crc64.MakeTable(crc64.ECMA)) },
+		"adler32":    func() hash.Hash { return adler32.New() },
+		"sha256":     sha256.New,
+	}
+)
+
+// RegisterChecksum makes the checksum or digest computed by the hashes new
+// returns available to WithTrailer under name, e.g. a CRC with the
+// parameters of an industrial protocol, Fletcher-16 or a truncated SHA-256.
+// A hash.Hash32 or hash.Hash64 with a Size of 4 or 8 is written as an
+// integer in the byte order of the Encoder, any other hash as the bytes
+// Sum returns. The built-in names are "crc32", "crc32c", "crc64-iso",
+// "crc64-ecma", "adler32" and "sha256".
+func RegisterChecksum(name string, new func() hash.Hash) {
+	checksumsMu.Lock()
+	defer checksumsMu.Unlock()
+	checksums[name] = new
+}
+
+// WithTrailer is like WithTrailerChecksum with the checksum registered
+// under name, see RegisterChecksum. An unknown name fails encoding and
+// decoding.
+func WithTrailer(name string) Option {
+	return func(c *config) {
+		checksumsMu.RLock()
+		defer checksumsMu.RUnlock()
+		c.trailer = &checksum{name: name, new: checksums[name]}
+	}
+}
+
+// hash returns a new hash computing the checksum.
+func (c *checksum) hash() (hash.Hash, error) {
+	if c.new == nil {
+		return nil, fmt.Errorf("binencoder: unknown checksum %q", c.name)
+	}
+	return c.new(), nil
+}
+
+// trailerSum returns the trailer holding the sum of h in byte order order.
+func trailerSum(h hash.Hash, order binary.ByteOrder) []byte {
+	if s, ok := h.(hash.Hash32); ok && h.Size() == 4 {
+		b := make([]byte, 4)
+		order.PutUint32(b, s.Sum32())
+		return b
+	}
+	if s, ok := h.(hash.Hash64); ok && h.Size() == 8 {
+		b := make([]byte, 8)
+		order.PutUint64(b, s.Sum64())
+		return b
+	}
+	return h.Sum(nil)
+}
+
+// hashWriter passes writes on to w and hashes the bytes written.
+type hashWriter struct {
+	w io.Writer
+	h hash.Hash
+}
+
+func (hw *hashWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	return n, err
+}
+
+// hashReader passes reads on to r and hashes the bytes read.
+type hashReader struct {
+	r io.Reader
+	h hash.Hash
+}
+
+func (hr *hashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	return n, err
+}
+
+// writeTrailer writes the checksum h after the message of type t.
+func (enc *Encoder) writeTrailer(h hash.Hash, t reflect.Type) error {
+	if err := enc.write(trailerSum(h, enc.byteOrder)); err != nil {
+		return enc.fail(0, t, err)
+	}
+	return nil
+}
+
+// readTrailer reads the checksum after the message of type t and compares
+// it with the one computed by h.
+func (dec *Decoder) readTrailer(h hash.Hash, t reflect.Type) error {
+	b := make([]byte, h.Size())
+	if err := dec.readFull(b); err != nil {
+		return newDecodeError("", t, err)
+	}
+	return dec.checkTrailer(b, h, t)
+}
+
+// checkTrailer compares the checksum in b with the one computed by h.
+func (dec *Decoder) checkTrailer(b []byte, h hash.Hash, t reflect.Type) error {
+	if sum := trailerSum(h, dec.byteOrder); !bytes.Equal(b, sum) {
+		return newDecodeError("", t, fmt.Errorf("%w: checksum %x, computed %x", ErrBadChecksum, b, sum))
+	}
+	return nil
+}
@@ -0,0 +1,594 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator emits EncodeBin and DecodeBin methods for struct types of one
+// package.
+type generator struct {
+	pkg     string
+	types   map[string]*ast.TypeSpec
+	tagName string
+	// methods holds the names of the methods declared for each type.
+	methods map[string]map[string]bool
+
+	// imports used by the generated code.
+	imports map[string]bool
+	// bufLen is the size of the scratch buffer DecodeBin needs.
+	bufLen int
+}
+
+// byteOrder is the byte order code is generated for: either the order
+// argument of the methods or a fixed order set with the endian option.
+type byteOrder struct {
+	expr   string
+	static string // "", "le" or "be"
+}
+
+var orderArg = byteOrder{expr: "order"}
+
+// ints returns the byte order numbers are written in: their own for fields
+// with an endian option and little-endian otherwise, as by an Encoder
+// without binencoder.WithIntByteOrder.
+func (o byteOrder) ints() string {
+	if o.static == "" {
+		return "binary.LittleEndian"
+	}
+	return o.expr
+}
+
+const binencoderPath = "github.com/milQA/binencoder"
+
+type basic struct {
+	size   int
+	signed bool
+}
+
+var basics = map[string]basic{
+	"bool":   {size: 1},
+	"uint8":  {size: 1},
+	"byte":   {size: 1},
+	"uint16": {size: 2},
+	"int16":  {size: 2, signed: true},
+	"uint32": {size: 4},
+	"int32":  {size: 4, signed: true},
+	"uint64": {size: 8},
+	"int64":  {size: 8, signed: true},
+	"string": {},
+}
+
+// unsupportedTags are tags the generated code cannot honor.
+var unsupportedTags = []string{"unit", "compact", "timefmt", "durfmt", "ip", "uuid", "prefix", "tlv", "klv", "validate", "enum", "minver", "maxver", "optional", "typeid", "embed", "order", "offset", "compress", "encoding", "strenc", "overflow", "fixed", "scale", "bigint", "flags", "dns", "until", "as", "transform", "raw"}
+
+// generate returns the source of a file with methods for the named struct
+// types declared in the package in dir.
+func generate(dir string, typeNames []string, tagName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		types:   map[string]*ast.TypeSpec{},
+		tagName: tagName,
+		methods: map[string]map[string]bool{},
+		imports: map[string]bool{"encoding/binary": true, "io": true},
+	}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch d := decl.(type) {
+				case *ast.GenDecl:
+					if d.Tok == token.TYPE {
+						for _, spec := range d.Specs {
+							ts := spec.(*ast.TypeSpec)
+							g.types[ts.Name.Name] = ts
+						}
+					}
+				case *ast.FuncDecl:
+					if d.Recv != nil && len(d.Recv.List) == 1 {
+						g.addMethod(d.Recv.List[0].Type, d.Name.Name)
+					}
+				}
+			}
+		}
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var body bytes.Buffer
+	for _, name := range typeNames {
+		if err := g.generateType(&body, name); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by binencoder-gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		if imp != binencoderPath {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+	}
+	if g.imports[binencoderPath] {
+		fmt.Fprintf(&out, "\n\t%q\n", binencoderPath)
+	}
+	fmt.Fprintf(&out, ")\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// addMethod records method name of the receiver type recv.
+func (g *generator) addMethod(recv ast.Expr, name string) {
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	id, ok := recv.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if g.methods[id.Name] == nil {
+		g.methods[id.Name] = map[string]bool{}
+	}
+	g.methods[id.Name][name] = true
+}
+
+// checkLayout rejects struct types whose methods change their layout in a
+// way the generated code does not follow.
+func (g *generator) checkLayout(name, path string) error {
+	if g.methods[name]["ByteOrder"] {
+		return fmt.Errorf("%s: type %s implements binencoder.ByteOrderer, which is not supported", path, name)
+	}
+	if g.methods[name]["BlockSize"] {
+		return fmt.Errorf("%s: type %s implements binencoder.BlockSizer, which is not supported", path, name)
+	}
+	return nil
+}
+
+func (g *generator) generateType(w *bytes.Buffer, name string) error {
+	ts, ok := g.types[name]
+	if !ok {
+		return fmt.Errorf("type %s not found", name)
+	}
+	if _, ok := ts.Type.(*ast.StructType); !ok {
+		return fmt.Errorf("type %s is not a struct", name)
+	}
+	if err := g.checkLayout(name, name); err != nil {
+		return err
+	}
+
+	var enc bytes.Buffer
+	if err := g.encode(&enc, "m", ts.Type, 0, orderArg, 0, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n// EncodeBin implements binencoder.Marshaler.\n")
+	fmt.Fprintf(w, "func (m %s) EncodeBin(w io.Writer, order binary.ByteOrder) error {\n", name)
+	fmt.Fprintf(w, "var b []byte\n%s_, err := w.Write(b)\nreturn err\n}\n", enc.Bytes())
+
+	g.bufLen = 0
+	var dec bytes.Buffer
+	if err := g.decode(&dec, "m", ts.Type, 0, orderArg, 0, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n// DecodeBin implements binencoder.Unmarshaler.\n")
+	fmt.Fprintf(w, "func (m *%s) DecodeBin(r io.Reader, order binary.ByteOrder) error {\n", name)
+	if g.bufLen > 0 {
+		fmt.Fprintf(w, "buf := make([]byte, %d)\n", g.bufLen)
+	}
+	fmt.Fprintf(w, "%sreturn nil\n}\n", dec.Bytes())
+
+	fmt.Fprintf(w, "\n// BinencoderGenerated implements binencoder.Generated.\n")
+	fmt.Fprintf(w, "func (%s) BinencoderGenerated() {}\n", name)
+	return nil
+}
+
+// field is a struct field with its resolved options.
+type field struct {
+	name  string
+	typ   ast.Expr
+	len   int // -1 skips the field, -2 inherits the parent's length
+	order byteOrder
+}
+
+const inheritLen = -2
+
+func (g *generator) fields(st *ast.StructType, order byteOrder, path string) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			id, ok := f.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("%s: unsupported embedded field", path)
+			}
+			names = append(names, id.Name)
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+			fieldPath := path + "." + name
+			if err := g.checkNamespace(tag); err != nil {
+				return nil, fmt.Errorf("%s: %v", fieldPath, err)
+			}
+			for _, key := range unsupportedTags {
+				if g.tag(tag, key) != "" {
+					return nil, fmt.Errorf("%s: tag %q is not supported", fieldPath, key)
+				}
+			}
+			if l := g.tag(tag, "len"); l != "" && l != "-" && parseLen(l) == inheritLen {
+				return nil, fmt.Errorf("%s: len %q naming a field is not supported", fieldPath, l)
+			}
+			fo := order
+			switch endian := g.tag(tag, "endian"); endian {
+			case "":
+			case "le", "little":
+				fo = byteOrder{expr: "binary.LittleEndian", static: "le"}
+			case "be", "big":
+				fo = byteOrder{expr: "binary.BigEndian", static: "be"}
+			default:
+				return nil, fmt.Errorf("%s: unknown endian %q", fieldPath, endian)
+			}
+			fields = append(fields, field{name: name, typ: f.Type, len: parseLen(g.tag(tag, "len")), order: fo})
+		}
+	}
+	return fields, nil
+}
+
+// tag mirrors the Encoder's lookup of field options: the namespace tag
+// first, then the tag of the same name.
+func (g *generator) tag(tag reflect.StructTag, key string) string {
+	if ns, ok := tag.Lookup(g.tagName); ok {
+		for _, opt := range namespaceOptions(ns) {
+			if opt.name == key {
+				return opt.val
+			}
+		}
+	}
+	return tag.Get(key)
+}
+
+// namespaceKeys are the options of the namespace tag besides
+// unsupportedTags.
+var namespaceKeys = []string{"len", "endian", "sizeof", "der", "pbf"}
+
+// checkNamespace rejects the items of the namespace tag that set no
+// option, as the Encoder does: misspelled keys, or the rest of a value cut
+// at a comma.
+func (g *generator) checkNamespace(tag reflect.StructTag) error {
+	ns, ok := tag.Lookup(g.tagName)
+	if !ok {
+		return nil
+	}
+	for _, opt := range namespaceOptions(ns) {
+		if !contains(namespaceKeys, opt.name) && !contains(unsupportedTags, opt.name) {
+			return fmt.Errorf("unknown option %q in %s tag", opt.item, g.tagName)
+		}
+	}
+	return nil
+}
+
+type namespaceOption struct {
+	name, val, item string
+}
+
+// namespaceOptions splits the value of a namespace tag into its options.
+// Bare items after the first have an empty name.
+func namespaceOptions(ns string) []namespaceOption {
+	var opts []namespaceOption
+	for i, item := range strings.Split(ns, ",") {
+		item = strings.TrimSpace(item)
+		opt := namespaceOption{val: item, item: item}
+		if j := strings.IndexByte(item, '='); j >= 0 {
+			opt.name, opt.val = item[:j], item[j+1:]
+		} else if i == 0 {
+			opt.name = "len"
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func parseLen(tag string) int {
+	if tag == "-" {
+		return -1
+	}
+	n, err := strconv.Atoi(tag)
+	if err != nil || n < 0 {
+		return inheritLen
+	}
+	return n
+}
+
+// resolve follows named types of the package to a basic type, an array or
+// a struct. conv is the type name values are converted to on decoding.
+func (g *generator) resolve(typ ast.Expr, path string) (under ast.Expr, conv string, err error) {
+	for {
+		id, ok := typ.(*ast.Ident)
+		if !ok {
+			return typ, conv, nil
+		}
+		if conv == "" {
+			conv = id.Name
+		}
+		if _, ok := basics[id.Name]; ok {
+			return id, conv, nil
+		}
+		ts, ok := g.types[id.Name]
+		if !ok {
+			return nil, "", fmt.Errorf("%s: unsupported type %s", path, id.Name)
+		}
+		if err := g.checkLayout(id.Name, path); err != nil {
+			return nil, "", err
+		}
+		typ = ts.Type
+	}
+}
+
+func (g *generator) encode(w *bytes.Buffer, expr string, typ ast.Expr, bytesLen int, order byteOrder, depth int, path string) error {
+	under, conv, err := g.resolve(typ, path)
+	if err != nil {
+		return err
+	}
+	switch t := under.(type) {
+	case *ast.StructType:
+		fields, err := g.fields(t, order, path)
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			n := f.len
+			if n == -1 {
+				continue
+			}
+			if n == inheritLen {
+				n = bytesLen
+			}
+			if err := g.encode(w, expr+"."+f.name, f.typ, n, f.order, depth, path+"."+f.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.ArrayType:
+		if isByteSlice(t) && bytesLen != 0 {
+			return fmt.Errorf("%s: len on []byte is not supported", path)
+		}
+		i := fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(w, "for %s := range %s {\n", i, expr)
+		if err := g.encode(w, expr+"["+i+"]", t.Elt, bytesLen, order, depth+1, path+"[]"); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "}\n")
+		return nil
+	case *ast.Ident:
+		b := basics[t.Name]
+		if t.Name == "string" {
+			return g.encodeString(w, expr, bytesLen, order, path)
+		}
+		if bytesLen != 0 && bytesLen < b.size {
+			return fmt.Errorf("%s: len %d is shorter than %s", path, bytesLen, t.Name)
+		}
+		var put string
+		switch {
+		case t.Name == "bool":
+			put = fmt.Sprintf("if %s {\nb = append(b, 1)\n} else {\nb = append(b, 0)\n}\n", expr)
+		case b.size == 1:
+			put = fmt.Sprintf("b = append(b, %s)\n", convert(conv, "byte", expr))
+		default:
+			bits := b.size * 8
+			put = fmt.Sprintf("b = append(b, %s)\n%s.PutUint%d(b[len(b)-%d:], %s)\n",
+				strings.TrimSuffix(strings.Repeat("0, ", b.size), ", "), order.ints(), bits, b.size,
+				convert(conv, fmt.Sprintf("uint%d", bits), expr))
+		}
+		pad := 0
+		if bytesLen != 0 {
+			pad = bytesLen - b.size
+		}
+		g.padded(w, put, fmt.Sprint(pad), pad > 0, order)
+		return nil
+	default:
+		return fmt.Errorf("%s: unsupported type", path)
+	}
+}
+
+func (g *generator) encodeString(w *bytes.Buffer, expr string, bytesLen int, order byteOrder, path string) error {
+	put := fmt.Sprintf("b = append(b, %s...)\n", expr)
+	if bytesLen == 0 {
+		w.WriteString(put)
+		return nil
+	}
+	g.imports["fmt"] = true
+	g.imports[binencoderPath] = true
+	fmt.Fprintf(w, "if len(%s) > %d {\nreturn fmt.Errorf(\"%%w: %s\", binencoder.ErrFieldTooLong)\n}\n",
+		expr, bytesLen, strings.TrimPrefix(path[strings.IndexByte(path, '.')+1:], "."))
+	g.padded(w, put, fmt.Sprintf("%d-len(%s)", bytesLen, expr), true, order)
+	return nil
+}
+
+// padded emits put with pad zero bytes after it for little-endian output
+// and before it otherwise.
+func (g *generator) padded(w *bytes.Buffer, put, pad string, needPad bool, order byteOrder) {
+	if !needPad {
+		w.WriteString(put)
+		return
+	}
+	padding := fmt.Sprintf("b = append(b, make([]byte, %s)...)\n", pad)
+	switch order.static {
+	case "le":
+		w.WriteString(put + padding)
+	case "be":
+		w.WriteString(padding + put)
+	default:
+		fmt.Fprintf(w, "if %s == binary.LittleEndian {\n%s%s} else {\n%s%s}\n", order.expr, put, padding, padding, put)
+	}
+}
+
+func (g *generator) decode(w *bytes.Buffer, expr string, typ ast.Expr, bytesLen int, order byteOrder, depth int, path string) error {
+	under, conv, err := g.resolve(typ, path)
+	if err != nil {
+		return err
+	}
+	switch t := under.(type) {
+	case *ast.StructType:
+		fields, err := g.fields(t, order, path)
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			n := f.len
+			if n == -1 {
+				continue
+			}
+			if n == inheritLen {
+				n = bytesLen
+			}
+			if err := g.decode(w, expr+"."+f.name, f.typ, n, f.order, depth, path+"."+f.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.ArrayType:
+		if isByteSlice(t) && bytesLen != 0 {
+			return fmt.Errorf("%s: len on []byte is not supported", path)
+		}
+		i := fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(w, "for %s := range %s {\n", i, expr)
+		if err := g.decode(w, expr+"["+i+"]", t.Elt, bytesLen, order, depth+1, path+"[]"); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "}\n")
+		return nil
+	case *ast.Ident:
+		if t.Name == "string" {
+			g.decodeString(w, expr, conv, bytesLen, order)
+			return nil
+		}
+		b := basics[t.Name]
+		width := b.size
+		if bytesLen != 0 {
+			width = bytesLen
+		}
+		g.read(w, fmt.Sprintf("buf[:%d]", width), width)
+		p, first := fmt.Sprintf("buf[:%d]", b.size), "buf[0]"
+		if width > b.size {
+			alt := fmt.Sprintf("buf[%d:%d]", width-b.size, width)
+			switch order.static {
+			case "le":
+			case "be":
+				p, first = alt, fmt.Sprintf("buf[%d]", width-b.size)
+			default:
+				fmt.Fprintf(w, "{\np := %s\nif %s != binary.LittleEndian {\np = %s\n}\n", p, order.expr, alt)
+				p, first = "p", "p[0]"
+			}
+		}
+		var val, natural string
+		switch {
+		case t.Name == "bool":
+			val, natural = first+" != 0", "bool"
+		case b.size == 1:
+			val, natural = first, "uint8"
+		default:
+			bits := b.size * 8
+			val, natural = fmt.Sprintf("%s.Uint%d(%s)", order.ints(), bits, p), fmt.Sprintf("uint%d", bits)
+			if b.signed {
+				val, natural = fmt.Sprintf("int%d(%s)", bits, val), fmt.Sprintf("int%d", bits)
+			}
+		}
+		fmt.Fprintf(w, "%s = %s\n", expr, convert(natural, conv, val))
+		if p == "p" {
+			w.WriteString("}\n")
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s: unsupported type", path)
+	}
+}
+
+// isByteSlice reports whether t is []byte, which the package encodes as a
+// whole when it has a len.
+func isByteSlice(t *ast.ArrayType) bool {
+	id, ok := t.Elt.(*ast.Ident)
+	return t.Len == nil && ok && (id.Name == "byte" || id.Name == "uint8")
+}
+
+// convert returns expr converted from type from to type to.
+func convert(from, to, expr string) string {
+	if from == to || from == "uint8" && to == "byte" || from == "byte" && to == "uint8" {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+func (g *generator) decodeString(w *bytes.Buffer, expr, conv string, bytesLen int, order byteOrder) {
+	if bytesLen == 0 {
+		fmt.Fprintf(w, "{\np := make([]byte, len(%s))\n", expr)
+		g.readInto(w, "p")
+		fmt.Fprintf(w, "%s = %s(p)\n}\n", expr, conv)
+		return
+	}
+	g.imports["bytes"] = true
+	g.read(w, fmt.Sprintf("buf[:%d]", bytesLen), bytesLen)
+	trim := func(fn string) string {
+		return fmt.Sprintf("%s = %s(bytes.%s(buf[:%d], \"\\x00\"))\n", expr, conv, fn, bytesLen)
+	}
+	switch order.static {
+	case "le":
+		w.WriteString(trim("TrimRight"))
+	case "be":
+		w.WriteString(trim("TrimLeft"))
+	default:
+		fmt.Fprintf(w, "if %s == binary.LittleEndian {\n%s} else {\n%s}\n", order.expr, trim("TrimRight"), trim("TrimLeft"))
+	}
+}
+
+// read emits reading n bytes into the scratch buffer slice p.
+func (g *generator) read(w *bytes.Buffer, p string, n int) {
+	if n > g.bufLen {
+		g.bufLen = n
+	}
+	g.readInto(w, p)
+}
+
+func (g *generator) readInto(w *bytes.Buffer, p string) {
+	fmt.Fprintf(w, "if _, err := io.ReadFull(r, %s); err != nil {\nreturn err\n}\n", p)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	got, err := generate(dir, []string{"Message"}, "bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(dir, "message_bin.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Error("internal/gentest/message_bin.go is out of date, run go generate")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"type M struct{ A float32 }":                    "unsupported type float32",
+		"type M struct{ A uint32 `unit:\"ms->s\"` }":    `tag "unit" is not supported`,
+		"type M struct{ A uint32 `len:\"2\"` }":         "len 2 is shorter than uint32",
+		"type M struct{ A uint32 `bin:\"endain=be\"` }": `unknown option "endain=be" in bin tag`,
+		"type M struct{ A *uint32 }":                    "unsupported type",
+		"type M int":                                    "is not a struct",
+	} {
+		_, err := generateSource(t, src)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", src, err, want)
+		}
+	}
+}
+
+// Types that declare their own layout through methods would be encoded
+// differently by the generated code than by an Encoder, so they are
+// rejected.
+func TestGenerateLayoutMethods(t *testing.T) {
+	for _, tc := range []struct {
+		src, want string
+	}{
+		{`type M struct{ A uint16 }
+func (M) ByteOrder() binary.ByteOrder { return binary.BigEndian }`, "M: type M implements binencoder.ByteOrderer"},
+		{`type H struct{ A uint16 }
+func (*H) ByteOrder() binary.ByteOrder { return binary.BigEndian }
+type M struct{ H H }`, "M.H: type H implements binencoder.ByteOrderer"},
+		{`type M struct{ A uint16 }
+func (M) BlockSize() int { return 512 }`, "M: type M implements binencoder.BlockSizer"},
+		{`type S struct{ A uint16 }
+func (S) BlockSize() int { return 512 }
+type M struct{ Sectors [2]S }`, "M.Sectors[]: type S implements binencoder.BlockSizer"},
+	} {
+		_, err := generateSource(t, tc.src)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+// generateSource generates the methods of type M declared in src.
+func generateSource(t *testing.T, src string) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "binencoder-gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "m.go"), []byte("package p\n"+src+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return generate(dir, []string{"M"}, "bin")
+}
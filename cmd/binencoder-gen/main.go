@@ -0,0 +1,59 @@
+// Command binencoder-gen generates reflection-free EncodeBin and DecodeBin
+// methods for struct types, implementing binencoder.Marshaler and
+// binencoder.Unmarshaler with the same layout Encoder produces from the
+// struct tags. It is meant to be run by go generate:
+//
+//	//go:generate binencoder-gen -type Message,Header
+//
+// Supported are bool, uint8, uint16, int16, uint32, int32, uint64, int64
+// and string fields, named types of them, arrays, slices and nested structs
+// of the same package, which are inlined. Of the tags only `len` and the
+// endian option are supported; padding is always zero bytes, and numbers
+// of fields without an endian option are little-endian, as by an Encoder
+// without binencoder.WithIntByteOrder. The generated methods do not see the
+// options of the Encoder calling them, so they also implement
+// binencoder.Generated, and Encoders whose number byte order or pad byte
+// differ from the defaults refuse the types. The -tag flag must match
+// binencoder.WithTagName. Struct types implementing
+// binencoder.ByteOrderer or binencoder.BlockSizer are rejected, as the
+// generated code would not follow their byte order or block size.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <type>_bin.go")
+	tagName := flag.String("tag", "bin", "namespace tag key, as set with binencoder.WithTagName")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	src, err := generate(dir, types, *tagName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "binencoder-gen:", err)
+		os.Exit(1)
+	}
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_bin.go")
+	}
+	if err := ioutil.WriteFile(name, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "binencoder-gen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+// Command binencoder converts messages between JSON and the binary layout
+// of binencoder, so that test messages can be crafted and inspected without
+// writing Go programs:
+//
+//	binencoder -schema message.json encode < msg.json > msg.bin
+//	binencoder -schema message.json decode < msg.bin
+//
+// The schema file describes the message struct as a JSON object:
+//
+//	{"fields": [
+//		{"name": "Version", "type": "uint8"},
+//		{"name": "Flags", "type": "uint16", "tag": "endian=be"},
+//		{"name": "Name", "type": "string", "tag": "8"},
+//		{"name": "Points", "type": "[2]struct", "fields": [
+//			{"name": "X", "type": "int16"},
+//			{"name": "Y", "type": "int16"}
+//		]}
+//	]}
+//
+// Field names must be exported Go names; JSON messages use them as keys.
+// Types are bool, int8 to int64, uint8 to uint64, byte, float32, float64,
+// string and struct, optionally prefixed with [N] or []. A tag is the value
+// of the field's bin tag, written as in Go code.
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/milQA/binencoder"
+)
+
+func main() {
+	schema := flag.String("schema", "", "schema file describing the message; required")
+	order := flag.String("order", "le", "byte order of the binary layout, le or be")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: binencoder -schema file [-order le|be] encode|decode")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *schema == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*schema, *order, flag.Arg(0), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "binencoder:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schema, order, cmd string, in io.Reader, out io.Writer) error {
+	f, err := os.Open(schema)
+	if err != nil {
+		return err
+	}
+	t, err := loadSchema(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	opts := []binencoder.Option{binencoder.WithIntByteOrder(true)}
+	switch order {
+	case "le":
+		opts = append(opts, binencoder.WithByteOrder(binary.LittleEndian))
+	case "be":
+		opts = append(opts, binencoder.WithByteOrder(binary.BigEndian))
+	default:
+		return fmt.Errorf("unknown byte order %q", order)
+	}
+	switch cmd {
+	case "encode":
+		return encode(t, opts, in, out)
+	case "decode":
+		return decode(t, opts, in, out)
+	}
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+// encode reads a JSON message of type t from in and writes its encoding.
+func encode(t reflect.Type, opts []binencoder.Option, in io.Reader, out io.Writer) error {
+	v := reflect.New(t)
+	dec := json.NewDecoder(in)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v.Interface()); err != nil {
+		return err
+	}
+	return binencoder.NewEncoder(out, opts...).Encode(v.Elem().Interface(), 0)
+}
+
+// decode reads an encoded message of type t from in and writes it as JSON.
+func decode(t reflect.Type, opts []binencoder.Option, in io.Reader, out io.Writer) error {
+	v := reflect.New(t)
+	if err := binencoder.NewDecoder(in, opts...).Decode(v.Interface(), 0); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v.Interface())
+}
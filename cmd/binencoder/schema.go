@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// schemaField describes a struct field in a schema file.
+type schemaField struct {
+	// Name is the exported Go field name, which JSON messages use as key.
+	Name string `json:"name"`
+	// Type is a Go type: bool, a sized integer, float32, float64, byte,
+	// string or struct, optionally prefixed with [N] or [].
+	Type string `json:"type"`
+	// Tag is the value of the field's bin tag, e.g. "8" or "4,endian=be".
+	Tag string `json:"tag"`
+	// Fields are the fields of a struct type.
+	Fields []schemaField `json:"fields"`
+}
+
+var schemaTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeOf(false),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"byte":    reflect.TypeOf(byte(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+	"string":  reflect.TypeOf(""),
+}
+
+// loadSchema reads a schema file, a JSON object with the fields of the
+// message, and builds the struct type it describes.
+func loadSchema(r io.Reader) (reflect.Type, error) {
+	var s struct {
+		Fields []schemaField `json:"fields"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	return structType(s.Fields, "")
+}
+
+func structType(fields []schemaField, path string) (reflect.Type, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("schema: struct %s has no fields", path)
+	}
+	sf := make([]reflect.StructField, 0, len(fields))
+	for _, f := range fields {
+		name := f.Name
+		if path != "" {
+			name = path + "." + f.Name
+		}
+		if r, _ := utf8.DecodeRuneInString(f.Name); !unicode.IsUpper(r) {
+			return nil, fmt.Errorf("schema: field name %q is not exported", name)
+		}
+		t, err := fieldType(f, f.Type, name)
+		if err != nil {
+			return nil, err
+		}
+		field := reflect.StructField{Name: f.Name, Type: t}
+		if f.Tag != "" {
+			field.Tag = reflect.StructTag(`bin:` + strconv.Quote(f.Tag))
+		}
+		sf = append(sf, field)
+	}
+	return reflect.StructOf(sf), nil
+}
+
+// fieldType returns the Go type typ, the type of field f or a part of it.
+func fieldType(f schemaField, typ, name string) (reflect.Type, error) {
+	if strings.HasPrefix(typ, "[") {
+		i := strings.IndexByte(typ, ']')
+		if i < 0 {
+			return nil, fmt.Errorf("schema: field %s: invalid type %q", name, f.Type)
+		}
+		elem, err := fieldType(f, typ[i+1:], name)
+		if err != nil {
+			return nil, err
+		}
+		if i == 1 {
+			return reflect.SliceOf(elem), nil
+		}
+		n, err := strconv.Atoi(typ[1:i])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("schema: field %s: invalid type %q", name, f.Type)
+		}
+		return reflect.ArrayOf(n, elem), nil
+	}
+	if typ == "struct" {
+		return structType(f.Fields, name)
+	}
+	if t, ok := schemaTypes[typ]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("schema: field %s: unsupported type %q", name, f.Type)
+}
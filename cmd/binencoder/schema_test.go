@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{"fields": [
+	{"name": "Version", "type": "uint8"},
+	{"name": "Flags", "type": "uint16", "tag": "endian=be"},
+	{"name": "Name", "type": "string", "tag": "4"},
+	{"name": "Points", "type": "[2]struct", "fields": [
+		{"name": "X", "type": "int16"},
+		{"name": "Y", "type": "int16"}
+	]}
+]}`
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binencoder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schema := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(schema, []byte(testSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var bin bytes.Buffer
+	msg := `{"Version": 1, "Flags": 2, "Name": "ab", "Points": [{"X": 3, "Y": -1}, {"X": 4, "Y": 5}]}`
+	if err := run(schema, "le", "encode", strings.NewReader(msg), &bin); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{1, 0, 2, 'a', 'b', 0, 0, 3, 0, 0xff, 0xff, 4, 0, 5, 0}
+	if !bytes.Equal(bin.Bytes(), want) {
+		t.Fatalf("encode: got % x, want % x", bin.Bytes(), want)
+	}
+
+	var out bytes.Buffer
+	if err := run(schema, "le", "decode", bytes.NewReader(want), &out); err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := compact.String(), strings.Replace(msg, " ", "", -1); got != want {
+		t.Errorf("decode: got %s, want %s", got, want)
+	}
+
+	if err := run(schema, "le", "encode", strings.NewReader(`{"Unknown": 1}`), &bin); err == nil {
+		t.Error("expected an error for an unknown JSON field")
+	}
+	if err := run(schema, "me", "encode", strings.NewReader(msg), &bin); err == nil {
+		t.Error("expected an error for an unknown byte order")
+	}
+}
+
+func TestLoadSchemaErrors(t *testing.T) {
+	for schema, want := range map[string]string{
+		`{"fields": []}`: "has no fields",
+		`{"fields": [{"name": "a", "type": "uint8"}]}`:                                            `field name "a" is not exported`,
+		`{"fields": [{"name": "A", "type": "int"}]}`:                                              `field A: unsupported type "int"`,
+		`{"fields": [{"name": "A", "type": "[x]uint8"}]}`:                                         `field A: invalid type "[x]uint8"`,
+		`{"fields": [{"name": "A", "type": "struct", "fields": [{"name": "B", "type": "map"}]}]}`: `field A.B: unsupported type "map"`,
+		`{"fields": [], "extra": 1}`:                                                              "unknown field",
+	} {
+		_, err := loadSchema(strings.NewReader(schema))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", schema, err, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package binencoder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// COBSWriter writes frames encoded with Consistent Overhead Byte Stuffing,
+// each followed by a zero byte, so that frames can be delimited by zeros on
+// serial links. Data is buffered until EndFrame.
+type COBSWriter struct {
+	w   io.Writer
+	buf []byte
+	out []byte
+}
+
+// NewCOBSWriter returns a COBSWriter writing to w. An Encoder writing to it
+// emits every value as a frame.
+func NewCOBSWriter(w io.Writer) *COBSWriter {
+	return &COBSWriter{w: w}
+}
+
+func (cw *COBSWriter) Write(p []byte) (int, error) {
+	cw.buf = append(cw.buf, p...)
+	return len(p), nil
+}
+
+// EndFrame writes the buffered data as a frame.
+func (cw *COBSWriter) EndFrame() error {
+	cw.out = append(cobsEncode(cw.out[:0], cw.buf), 0)
+	cw.buf = cw.buf[:0]
+	_, err := cw.w.Write(cw.out)
+	return err
+}
+
+// AbortFrame discards the buffered data.
+func (cw *COBSWriter) AbortFrame() {
+	cw.buf = cw.buf[:0]
+}
+
+// COBSReader reads the frames written by COBSWriter. Its Read method serves
+// the decoded frames as one stream for a Decoder.
+type COBSReader struct {
+	r *bufio.Reader
+	frameReader
+}
+
+// NewCOBSReader returns a COBSReader reading from r.
+func NewCOBSReader(r io.Reader) *COBSReader {
+	cr := &COBSReader{r: bufio.NewReader(r)}
+	cr.next = cr.ReadFrame
+	return cr
+}
+
+// ReadFrame reads and decodes the next non-empty frame. It returns io.EOF
+// at the end of the input, an error matching ErrShortMessage if the input
+// ends within a frame and one matching ErrBadFrame for a malformed frame.
+func (cr *COBSReader) ReadFrame() ([]byte, error) {
+	for {
+		b, err := cr.r.ReadBytes(0)
+		if err == io.EOF && len(b) != 0 {
+			return nil, ErrShortMessage
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 1 {
+			return cobsDecode(b[:0], b[:len(b)-1])
+		}
+	}
+}
+
+// cobsEncode appends the COBS encoding of src to dst.
+func cobsEncode(dst, src []byte) []byte {
+	code := len(dst)
+	dst = append(dst, 1)
+	for _, c := range src {
+		if c != 0 {
+			dst = append(dst, c)
+			dst[code]++
+			if dst[code] < 0xff {
+				continue
+			}
+		}
+		code = len(dst)
+		dst = append(dst, 1)
+	}
+	return dst
+}
+
+// cobsDecode appends the decoding of the COBS block sequence src to dst.
+// dst may share the start of src's array.
+func cobsDecode(dst, src []byte) ([]byte, error) {
+	for i := 0; i < len(src); {
+		code := int(src[i])
+		if code == 0 || i+code > len(src) {
+			return nil, fmt.Errorf("%w: invalid COBS block at byte %d", ErrBadFrame, i)
+		}
+		dst = append(dst, src[i+1:i+code]...)
+		i += code
+		if code < 0xff && i < len(src) {
+			dst = append(dst, 0)
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,77 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestCOBS(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(binencoder.NewCOBSWriter(buf))
+	if err := enc.Encode(message{0x0011, "ab"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(message{2, "too long"}, 0); !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Fatalf("expected ErrFieldTooLong, got %v", err)
+	}
+	if err := enc.Encode(message{3, "c"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		2, 0x11, 3, 'a', 'b', 1, 1, 0,
+		2, 3, 2, 'c', 1, 1, 1, 0,
+	})
+
+	dec := binencoder.NewDecoder(binencoder.NewCOBSReader(buf))
+	for _, want := range []message{{0x0011, "ab"}, {3, "c"}} {
+		var out message
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != want {
+			t.Errorf("We have:\n%+v\n got:\n%+v\n", want, out)
+		}
+	}
+	if err := dec.Decode(&message{}, 0); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestCOBSLongRun(t *testing.T) {
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i%255) + 1
+	}
+	data[300] = 0
+	buf := new(bytes.Buffer)
+	w := binencoder.NewCOBSWriter(buf)
+	w.Write(data)
+	if err := w.EndFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.IndexByte(buf.Bytes()[:buf.Len()-1], 0) >= 0 {
+		t.Error("zero byte inside the frame")
+	}
+	frame, err := binencoder.NewCOBSReader(buf).ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, frame, data)
+
+	_, err = binencoder.NewCOBSReader(bytes.NewReader([]byte{5, 1, 0})).ReadFrame()
+	if !errors.Is(err, binencoder.ErrBadFrame) {
+		t.Errorf("expected ErrBadFrame, got %v", err)
+	}
+	_, err = binencoder.NewCOBSReader(bytes.NewReader([]byte{2, 1})).ReadFrame()
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// EncodeFunc returns the wire form of v for a registered type.
+type EncodeFunc func(v interface{}, order binary.ByteOrder) ([]byte, error)
+
+// DecodeFunc reads a value of a registered type from r. Unless the field has
+// a `len` or `prefix` tag, r is the decoder's stream and the function must
+// consume exactly the value's encoding.
+type DecodeFunc func(r io.Reader, order binary.ByteOrder) (interface{}, error)
+
+type codec struct {
+	enc EncodeFunc
+	dec DecodeFunc
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[reflect.Type]codec{}
+)
+
+// RegisterCodec makes Encoder and Decoder use enc and dec for values of type
+// t, taking precedence over the built-in handling. Either function may be
+// nil if only one direction is needed. The `len` and `prefix` tags apply to
+// the encoded bytes.
+func RegisterCodec(t reflect.Type, enc EncodeFunc, dec DecodeFunc) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[t] = codec{enc: enc, dec: dec}
+}
+
+func lookupCodec(t reflect.Type) (codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[t]
+	return c, ok
+}
+
+func (enc *Encoder) encodeCodec(fn EncodeFunc, v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	b, err := fn(v.Interface(), enc.byteOrder)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if err := enc.writeBytes(b, bytesLen, tags.prefix); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+func (dec *Decoder) decodeCodec(fn DecodeFunc, v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	var r io.Reader = decoderReader{dec}
+	if bytesLen != 0 || tags.prefix != "" {
+		b, err := dec.readBytes(bytesLen, tags.prefix)
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		r = bytes.NewReader(b)
+	}
+	val, err := fn(r, dec.byteOrder)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrShortMessage
+		}
+		return newDecodeError(path, v.Type(), err)
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+		return newDecodeError(path, v.Type(), fmt.Errorf("binencoder: codec returned %T", val))
+	}
+	v.Set(rv)
+	return nil
+}
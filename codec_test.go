@@ -0,0 +1,57 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+// money is a vendor-style decimal stored as units and cents.
+type money struct {
+	units int64
+	cents int8
+}
+
+func init() {
+	binencoder.RegisterCodec(reflect.TypeOf(money{}),
+		func(v interface{}, order binary.ByteOrder) ([]byte, error) {
+			m := v.(money)
+			b := make([]byte, 4)
+			order.PutUint32(b, uint32(m.units*100+int64(m.cents)))
+			return b, nil
+		},
+		func(r io.Reader, order binary.ByteOrder) (interface{}, error) {
+			b := make([]byte, 4)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return nil, err
+			}
+			total := int64(int32(order.Uint32(b)))
+			return money{units: total / 100, cents: int8(total % 100)}, nil
+		})
+}
+
+func TestRegisterCodec(t *testing.T) {
+	type invoice struct {
+		Total money
+		Tax   money `prefix:"u8"`
+	}
+	in := invoice{Total: money{12, 34}, Tax: money{1, 5}}
+
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0, 0, 0x04, 0xd2, 4, 0, 0, 0, 0x69})
+
+	var out invoice
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.BigEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+}
@@ -0,0 +1,107 @@
+package binencoder
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"unicode/utf8"
+)
+
+const ellipsis = "..."
+
+var (
+	abbrevMu      sync.RWMutex
+	abbreviations = map[string]string{}
+)
+
+// RegisterAbbreviation adds an entry to the table used by `compact:"abbrev"`
+// fields: a string that does not fit its field is replaced by short.
+func RegisterAbbreviation(full, short string) {
+	abbrevMu.Lock()
+	defer abbrevMu.Unlock()
+	abbreviations[full] = short
+}
+
+// compactString shortens s to at most width bytes according to policy.
+func compactString(s string, width int, policy string) (string, error) {
+	switch policy {
+	case "ellipsis":
+		if width <= len(ellipsis) {
+			return cutPrefix(s, width), nil
+		}
+		avail := width - len(ellipsis)
+		prefix := cutPrefix(s, avail-avail/2)
+		return prefix + ellipsis + cutSuffix(s, width-len(prefix)-len(ellipsis)), nil
+	case "hash":
+		h := fnv.New32a()
+		h.Write([]byte(s))
+		sum := fmt.Sprintf("#%08x", h.Sum32())
+		if width <= len(sum) {
+			return sum[len(sum)-width:], nil
+		}
+		return cutPrefix(s, width-len(sum)) + sum, nil
+	case "abbrev":
+		abbrevMu.RLock()
+		short, ok := abbreviations[s]
+		abbrevMu.RUnlock()
+		if !ok || len(short) > width {
+			return s, ErrFieldTooLong
+		}
+		return short, nil
+	default:
+		return s, fmt.Errorf("binencoder: unknown compact policy %q", policy)
+	}
+}
+
+// truncateString applies the `overflow` policy to s, which does not fit
+// width bytes: "truncate" and "truncate-right" keep its start and
+// "truncate-left" its end; "error" fails with ErrFieldTooLong.
+func truncateString(s string, width int, policy string) (string, error) {
+	left, err := truncateLeft(policy)
+	if err != nil {
+		return s, err
+	}
+	if left {
+		return cutSuffix(s, width), nil
+	}
+	return cutPrefix(s, width), nil
+}
+
+// truncateLeft reports whether the `overflow` policy keeps the end of a
+// string rather than its start.
+func truncateLeft(policy string) (bool, error) {
+	switch policy {
+	case "truncate", "truncate-right":
+		return false, nil
+	case "truncate-left":
+		return true, nil
+	case "error":
+		return false, ErrFieldTooLong
+	}
+	return false, fmt.Errorf("binencoder: unknown overflow policy %q", policy)
+}
+
+// cutPrefix returns the longest prefix of s that fits n bytes without
+// splitting a rune.
+func cutPrefix(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// cutSuffix returns the longest suffix of s that fits n bytes without
+// splitting a rune.
+func cutSuffix(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := len(s) - n
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
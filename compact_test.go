@@ -0,0 +1,79 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestCompactTag(t *testing.T) {
+	binencoder.RegisterAbbreviation("Temperature sensor", "TEMP")
+
+	buf := new(bytes.Buffer)
+	encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	err := encoder.Encode(struct {
+		Ellipsis string    `len:"9" compact:"ellipsis"`
+		Hash     string    `len:"12" compact:"hash"`
+		Abbrev   string    `len:"6" compact:"abbrev"`
+		Short    string    `len:"6" compact:"ellipsis"`
+		Labels   [2]string `len:"5" compact:"ellipsis"`
+		Unicode  string    `len:"7" compact:"ellipsis"`
+	}{
+		Ellipsis: "abcdefghijklmnop",
+		Hash:     "abcdefghijklmnop",
+		Abbrev:   "Temperature sensor",
+		Short:    "abc",
+		Labels:   [2]string{"ok", "toolong"},
+		Unicode:  "ёжикёжик",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("abc...nop")
+	want = append(want, "abc#068bb1f5"...)
+	want = append(want, "TEMP\x00\x00"...)
+	want = append(want, "abc\x00\x00\x00"...)
+	want = append(want, "ok\x00\x00\x00"...)
+	want = append(want, "t...g"...)
+	want = append(want, "ё...к"...)
+	equalByte(t, buf.Bytes(), want)
+
+	err = encoder.Encode(struct {
+		Abbrev string `len:"4" compact:"abbrev"`
+	}{Abbrev: "Pressure sensor"}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+}
+
+func TestOverflowTag(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := binencoder.NewEncoder(buf).Encode(struct {
+		Right   string `len:"4" overflow:"truncate"`
+		Left    string `len:"4" overflow:"truncate-left"`
+		Unicode string `len:"3" overflow:"truncate-right"`
+		Wide    string `len:"4" strenc:"utf16le" overflow:"truncate-left"`
+	}{
+		Right:   "abcdef",
+		Left:    "abcdef",
+		Unicode: "ёж",
+		Wide:    "xyz",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("abcdcdef")
+	want = append(want, "ё\x00"...)
+	want = append(want, 'y', 0, 'z', 0)
+	equalByte(t, buf.Bytes(), want)
+
+	err = binencoder.NewEncoder(buf).Encode(struct {
+		Name string `len:"2" overflow:"error"`
+	}{Name: "abc"}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package binencoder
+
+import (
+	"bytes"
+	"io"
+)
+
+// Message is a value encoded ahead of time by Compile. It can be written
+// any number of times, e.g. broadcast to many connections, without
+// encoding the value again, and is safe for concurrent use.
+type Message struct {
+	b []byte
+}
+
+// Compile encodes v with opts once and returns the result as a Message.
+// Later changes to v do not affect it.
+func Compile(v interface{}, opts ...Option) (Message, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, opts...).Encode(v, 0); err != nil {
+		return Message{}, err
+	}
+	return Message{b: buf.Bytes()}, nil
+}
+
+// WriteTo writes the message to w with a single call. If w is a
+// FrameWriter, the message ends a frame, like a value written by Encode.
+func (m Message) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(m.b)
+	if fw, ok := w.(FrameWriter); ok {
+		if err != nil {
+			fw.AbortFrame()
+			return int64(n), err
+		}
+		err = fw.EndFrame()
+	}
+	return int64(n), err
+}
+
+// Bytes returns the encoding of the message. It must not be modified.
+func (m Message) Bytes() []byte {
+	return m.b
+}
+
+// Len returns the length of the message in bytes.
+func (m Message) Len() int {
+	return len(m.b)
+}
@@ -0,0 +1,45 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestCompile(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	in := message{1, "ab"}
+	m, err := binencoder.Compile(in, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.ID = 2
+	want := []byte{0, 1, 0, 0, 'a', 'b'}
+	for i := 0; i < 2; i++ {
+		buf := new(bytes.Buffer)
+		n, err := m.WriteTo(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(want)) || m.Len() != len(want) {
+			t.Errorf("wrote %d bytes, Len is %d, want %d", n, m.Len(), len(want))
+		}
+		equalByte(t, buf.Bytes(), want)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := m.WriteTo(binencoder.NewCOBSWriter(buf)); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 2, 1, 1, 3, 'a', 'b', 0})
+
+	if _, err := binencoder.Compile(message{3, "too long"}); !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package binencoder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+// parseCompressTag parses the value of a `compress` tag on a field of type
+// t: "zlib" or "gzip" for byte slices, strings and structs.
+func parseCompressTag(tag string, t reflect.Type) (string, error) {
+	switch tag {
+	case "":
+		return "", nil
+	case "zlib", "gzip":
+	default:
+		return "", fmt.Errorf("binencoder: invalid compress tag %q", tag)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.String, t.Kind() == reflect.Struct:
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+	default:
+		return "", fmt.Errorf("%w: compress tag on %s", ErrUnknownType, t)
+	}
+	return tag, nil
+}
+
+// deflate returns the compressed encoding of v, the value of compressed
+// field f.
+func (enc *Encoder) deflate(v reflect.Value, f *fieldPlan, path int) ([]byte, error) {
+	var raw []byte
+	switch {
+	case v.Kind() == reflect.String:
+		raw = []byte(v.String())
+	case v.Kind() == reflect.Slice:
+		raw = v.Bytes()
+	default:
+		var body bytes.Buffer
+		w, n, trace, layout := enc.w, enc.n, enc.trace, enc.layout
+		enc.w, enc.trace, enc.layout = &body, nil, nil
+		err := enc.encode(v, 0, f.tags, path)
+		enc.w, enc.n, enc.trace, enc.layout = w, n, trace, layout
+		if err != nil {
+			return nil, err
+		}
+		raw = body.Bytes()
+	}
+	var out bytes.Buffer
+	var zw io.WriteCloser
+	if f.compress == "gzip" {
+		zw = gzip.NewWriter(&out)
+	} else {
+		zw = zlib.NewWriter(&out)
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return nil, enc.fail(path, v.Type(), err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, enc.fail(path, v.Type(), err)
+	}
+	return out.Bytes(), nil
+}
+
+// encodeCompressedSize encodes the `sizeof` field f of struct v with the
+// size of the compressed field it names, compressing that field ahead of
+// time for encodeCompressed.
+func (enc *Encoder) encodeCompressedSize(v reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	target := f.sizeTo
+	b, err := enc.deflate(v.Field(target.index), target, path)
+	if err != nil {
+		return err
+	}
+	field := v.Field(f.index)
+	size := reflect.New(field.Type()).Elem()
+	switch field.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if size.OverflowUint(uint64(len(b))) {
+			return enc.fail(path, field.Type(), fmt.Errorf("%w: size %d of %s", ErrOverflow, len(b), target.name))
+		}
+		size.SetUint(uint64(len(b)))
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if size.OverflowInt(int64(len(b))) {
+			return enc.fail(path, field.Type(), fmt.Errorf("%w: size %d of %s", ErrOverflow, len(b), target.name))
+		}
+		size.SetInt(int64(len(b)))
+	default:
+		return enc.fail(path, field.Type(), fmt.Errorf("%w: %s cannot hold a size", ErrUnknownType, field.Type()))
+	}
+	if enc.deflated == nil {
+		enc.deflated = map[*fieldPlan][][]byte{}
+	}
+	enc.deflated[target] = append(enc.deflated[target], b)
+	return enc.encodeFieldValue(size, f, bytesLen, path)
+}
+
+// encodeCompressed writes compressed field f, prepared when its `sizeof`
+// field was encoded.
+func (enc *Encoder) encodeCompressed(v reflect.Value, f *fieldPlan, path int) error {
+	pending := enc.deflated[f]
+	b := pending[len(pending)-1]
+	enc.deflated[f] = pending[:len(pending)-1]
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeCompressed reads n bytes compressed from field f into v.
+func (dec *Decoder) decodeCompressed(v reflect.Value, f *fieldPlan, n int, path string) error {
+	if err := dec.checkLen(n, reflect.Slice); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	var body bytes.Buffer
+	copied, err := io.CopyN(&body, dec.r, int64(n))
+	dec.n += int(copied)
+	if err == io.EOF {
+		err = ErrShortMessage
+	}
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	var zr io.Reader
+	if f.compress == "gzip" {
+		zr, err = gzip.NewReader(&body)
+	} else {
+		zr, err = zlib.NewReader(&body)
+	}
+	if err != nil {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: %v", ErrBadFrame, err))
+	}
+	if v.Kind() == reflect.Struct || v.Kind() == reflect.Ptr {
+		sub := *dec
+		sub.r = zr
+		err := sub.decode(v, 0, f.tags, path)
+		dec.steps = sub.steps
+		return err
+	}
+	// Inflated data is held to the length limits, so that a small
+	// payload cannot claim unbounded memory.
+	max := dec.maxSliceLen
+	if v.Kind() == reflect.String {
+		max = dec.maxStringLen
+	}
+	if max > 0 {
+		zr = io.LimitReader(zr, int64(max)+1)
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: %v", ErrBadFrame, err))
+	}
+	if err := dec.checkLen(len(raw), v.Kind()); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(string(raw))
+	} else {
+		v.SetBytes(raw)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestCompress(t *testing.T) {
+	type body struct {
+		Level uint8
+		Text  string `len:"64"`
+	}
+	type record struct {
+		Time     uint32
+		TextSize uint16 `sizeof:"Text"`
+		BodySize uint32 `sizeof:"Body"`
+		Text     string `compress:"zlib"`
+		Body     body   `compress:"gzip"`
+	}
+	in := record{Time: 7, Text: strings.Repeat("abc", 100), Body: body{2, strings.Repeat("x", 64)}}
+	var buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 200 {
+		t.Errorf("got %d bytes, expected the payloads to be compressed", buf.Len())
+	}
+
+	var out record
+	if err := binencoder.NewDecoder(&buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	in.TextSize, in.BodySize = out.TextSize, out.BodySize
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes left", buf.Len())
+	}
+}
+
+func TestCompressLimits(t *testing.T) {
+	type record struct {
+		Size uint16 `sizeof:"Data"`
+		Data []byte `compress:"zlib"`
+	}
+	var buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf).Encode(record{Data: make([]byte, 1000)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	err := binencoder.NewDecoder(&buf, binencoder.WithMaxSliceLen(100)).Decode(&record{}, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	type alone struct {
+		Data []byte `compress:"zlib"`
+	}
+	if err := binencoder.NewEncoder(&buf).Encode(alone{}, 0); err == nil {
+		t.Error("expected an error for a compressed field without a sizeof field")
+	}
+}
@@ -0,0 +1,117 @@
+package binencoder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Decimal is the exact decimal number Coefficient × 10^Exponent.
+//
+// Without tags a Decimal is encoded like any struct, as the coefficient and
+// the exponent. With a `scale` tag, a power of ten optionally followed by
+// the wire type, e.g. `scale:"100 i64"`, it is written as the integer
+// number of hundredths; a value with more decimal places than the scale is
+// an error rather than rounded.
+type Decimal struct {
+	Coefficient int64
+	Exponent    int32
+}
+
+var decimalType = reflect.TypeOf(Decimal{})
+
+// String formats d without an exponent, e.g. "-12.50".
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.Coefficient, 10)
+	if d.Exponent >= 0 {
+		for i := int32(0); i < d.Exponent && d.Coefficient != 0; i++ {
+			s += "0"
+		}
+		return s
+	}
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	places := int(-d.Exponent)
+	for len(s) <= places {
+		s = "0" + s
+	}
+	return sign + s[:len(s)-places] + "." + s[len(s)-places:]
+}
+
+// decimalPlaces returns the number of decimal places of a scale factor,
+// which must be a power of ten.
+func decimalPlaces(factor float64) (int32, error) {
+	places := int32(math.Round(math.Log10(factor)))
+	if places < 0 || places > 18 || math.Pow(10, float64(places)) != factor {
+		return 0, fmt.Errorf("binencoder: decimal scale %g is not a power of ten", factor)
+	}
+	return places, nil
+}
+
+// rescale returns the coefficient of d with the exponent -places.
+func (d Decimal) rescale(places int32) (int64, error) {
+	c := d.Coefficient
+	for e := d.Exponent + places; e != 0 && c != 0; {
+		switch {
+		case e > 0:
+			if c > math.MaxInt64/10 || c < math.MinInt64/10 {
+				return 0, fmt.Errorf("%w: %s at %d decimal places", ErrOverflow, d, places)
+			}
+			c *= 10
+			e--
+		case c%10 != 0:
+			return 0, fmt.Errorf("%w: %s has more than %d decimal places", ErrInvalidValue, d, places)
+		default:
+			c /= 10
+			e++
+		}
+	}
+	return c, nil
+}
+
+// isScaledDecimal reports whether v is a Decimal with a `scale` tag.
+func isScaledDecimal(v reflect.Value, tags fieldTags) bool {
+	return v.Type() == decimalType && tags.scale != ""
+}
+
+// decimalToWire returns the wire value of a Decimal with a `scale` tag.
+func decimalToWire(v reflect.Value, tags fieldTags) (reflect.Value, error) {
+	s, err := parseScaled(fieldTags{scale: tags.scale})
+	if err != nil {
+		return v, err
+	}
+	places, err := decimalPlaces(s.factor)
+	if err != nil {
+		return v, err
+	}
+	c, err := v.Interface().(Decimal).rescale(places)
+	if err != nil {
+		return v, err
+	}
+	wire, err := s.intToWire(c)
+	if err != nil {
+		return v, err
+	}
+	return wire, nil
+}
+
+// decodeDecimal decodes a Decimal with a `scale` tag.
+func (dec *Decoder) decodeDecimal(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	s, err := parseScaled(fieldTags{scale: tags.scale})
+	var places int32
+	if err == nil {
+		places, err = decimalPlaces(s.factor)
+	}
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	wire := reflect.New(s.wireType()).Elem()
+	if err := dec.decode(wire, bytesLen, fieldTags{}, path); err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(Decimal{Coefficient: s.intFromWire(wire), Exponent: -places}))
+	return nil
+}
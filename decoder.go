@@ -0,0 +1,590 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Decoder reads values written by an Encoder. Like an Encoder, it must not
+// be used by several goroutines at once.
+type Decoder struct {
+	r io.Reader
+	config
+	n int
+	// end is the furthest position read from before an `offset` tag moved
+	// back.
+	end int
+
+	maxSteps int
+	timeout  time.Duration
+	steps    int
+	deadline time.Time
+
+	// truncated is set once optional fields were missing from the input.
+	truncated bool
+
+	// reported is the position of the last WithProgress report.
+	reported int
+
+	// src is the reader of the buffer set with ResetBytes.
+	src bytesReader
+
+	// delta is set while ApplyDelta runs.
+	delta bool
+}
+
+// NewDecoder returns a Decoder reading from r. It accepts the same options
+// as NewEncoder.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	dec := &Decoder{r: r}
+	dec.config.init(opts)
+	return dec
+}
+
+// SetLogger sets the logger for diagnostics. A nil logger discards them,
+// which is the default.
+func (dec *Decoder) SetLogger(l Logger) {
+	WithLogger(l)(&dec.config)
+}
+
+// Clone returns a new Decoder reading from r with the options and
+// watchdog limits of dec. It may run concurrently with dec's Decode calls.
+func (dec *Decoder) Clone(r io.Reader) *Decoder {
+	return &Decoder{r: r, config: dec.config, maxSteps: dec.maxSteps, timeout: dec.timeout}
+}
+
+// SetWatchdog limits the work a single Decode call may do: maxSteps bounds
+// the number of values visited and timeout the time spent. Exceeding either
+// aborts decoding with an error matching ErrLimitExceeded that names the
+// field being decoded. Zero disables the corresponding limit.
+func (dec *Decoder) SetWatchdog(maxSteps int, timeout time.Duration) {
+	dec.maxSteps = maxSteps
+	dec.timeout = timeout
+}
+
+// Decode reads the binary form written by Encode into the value pointed to
+// by data, applying the same tags and bytesLen rules. Slices and strings
+// without a length are read with the length they already have, so
+// variable-size fields must be sized by the caller beforehand.
+//
+// Decode returns io.EOF if the input ends before the first byte of the
+// value and an error matching ErrShortMessage if it ends in the middle.
+func (dec *Decoder) Decode(data interface{}, bytesLen int) error {
+	if dec.metrics != nil {
+		start := time.Now()
+		err := dec.decodeData(data, bytesLen)
+		if err != io.EOF {
+			t := reflect.TypeOf(data)
+			if t != nil && t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			dec.metrics.ObserveDecode(t, dec.n, time.Since(start), err)
+		}
+		return err
+	}
+	return dec.decodeData(data, bytesLen)
+}
+
+func (dec *Decoder) decodeData(data interface{}, bytesLen int) error {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return newDecodeError("", reflect.TypeOf(data), errors.New("binencoder: Decode needs a non-nil pointer"))
+	}
+	if dec.format != FormatRaw {
+		return newDecodeError("", reflect.TypeOf(data), fmt.Errorf("binencoder: decoding %s is not supported", dec.format))
+	}
+	if dec.sealKey != nil {
+		return dec.decodeEnvelope(v, bytesLen)
+	}
+	return dec.decodeMessage(v, bytesLen, dec.trailer)
+}
+
+// decodeMessage decodes a message into the value v points to, followed by
+// a checksum with trailer, if not nil.
+func (dec *Decoder) decodeMessage(v reflect.Value, bytesLen int, trailer *checksum) error {
+	dec.n, dec.end, dec.reported = 0, 0, 0
+	dec.steps = 0
+	dec.truncated = false
+	if dec.timeout > 0 {
+		dec.deadline = time.Now().Add(dec.timeout)
+	}
+	var hr *hashReader
+	if trailer != nil {
+		h, err := trailer.hash()
+		if err != nil {
+			return newDecodeError("", v.Type(), err)
+		}
+		hr = &hashReader{r: dec.r, h: h}
+		dec.r = hr
+	}
+	var err error
+	if dec.delta {
+		err = dec.applyDelta(v.Elem())
+	} else {
+		err = dec.decode(v.Elem(), bytesLen, fieldTags{}, "")
+	}
+	if err == nil {
+		err = dec.seekEnd()
+	}
+	if hr != nil {
+		dec.r = hr.r
+		if err == nil {
+			err = dec.readTrailer(hr.h, v.Type())
+		}
+	}
+	if dec.n == 0 && (errors.Is(err, ErrShortMessage) || err == nil && dec.truncated) {
+		return io.EOF
+	}
+	return err
+}
+
+// Unmarshal decodes data into the value pointed to by v, in the given
+// byte order with opts applied after it. All of data must be consumed.
+func Unmarshal(data []byte, v interface{}, order binary.ByteOrder, opts ...Option) error {
+	r := bytes.NewReader(data)
+	err := NewDecoder(r, append([]Option{WithByteOrder(order)}, opts...)...).Decode(v, 0)
+	if err == io.EOF {
+		return ErrShortMessage
+	}
+	if err == nil && r.Len() != 0 {
+		return fmt.Errorf("binencoder: %d bytes left after decoding %T", r.Len(), v)
+	}
+	return err
+}
+
+func (dec *Decoder) decode(v reflect.Value, bytesLen int, tags fieldTags, path string) (err error) {
+	if bytesLen == -1 {
+		return nil
+	}
+	if err := dec.tick(); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if dec.progress != nil {
+		dec.reportProgress(path)
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && implements(v, afterDecoderType) {
+		defer func() {
+			if err == nil {
+				if err = afterDecode(v); err != nil {
+					err = newDecodeError(path, v.Type(), err)
+				}
+			}
+		}()
+	}
+	if c, ok := lookupCodec(v.Type()); ok && c.dec != nil {
+		return dec.decodeCodec(c.dec, v, bytesLen, tags, path)
+	}
+	if isAsText(v.Type(), tags) {
+		return dec.decodeAsText(v, bytesLen, tags, path)
+	}
+	if v.Kind() == reflect.Interface && tags.typeid != "" {
+		return dec.decodeTypeID(v, bytesLen, tags, path)
+	}
+	if tags.uuid != "" {
+		b := make([]byte, uuidLen)
+		if err := dec.readFull(b); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		if err := uuidFromWire(v, b, tags.uuid); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		return nil
+	}
+	if tags.encoding != "" && v.Kind() == reflect.String {
+		return dec.decodeText(v, bytesLen, tags, path)
+	}
+	if isVarint(v, tags) {
+		return dec.decodeVarint(v, tags, path)
+	}
+	if tags.strenc != "" && v.Kind() == reflect.String {
+		return dec.decodeStrenc(v, bytesLen, tags, path)
+	}
+	if tags.dns != "" && v.Kind() == reflect.String {
+		return dec.decodeDNSName(v, tags, path)
+	}
+	if isScaled(v, tags) {
+		return dec.decodeScaled(v, bytesLen, tags, path)
+	}
+	if isScaledDecimal(v, tags) {
+		return dec.decodeDecimal(v, bytesLen, tags, path)
+	}
+	if v.Type() == bigIntType {
+		return dec.decodeBigInt(v, bytesLen, tags, path)
+	}
+	if isByteBlob(v.Type(), bytesLen, tags) {
+		return dec.decodeByteBlob(v, bytesLen, tags, path)
+	}
+	switch v.Type() {
+	case timeType, durationType:
+		return dec.decodeWire(v, bytesLen, tags, path)
+	case ipType, ipNetType, hardwareAddrType:
+		size, err := netWireSize(v, tags.ip)
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		b := make([]byte, size)
+		if err := dec.readFull(b); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		netFromWire(v, b)
+		return nil
+	}
+	if v.Type() == readerType {
+		return dec.decodeReader(v, bytesLen, path)
+	}
+	if u, ok := binUnmarshaler(v); ok {
+		return dec.decodeBin(u, bytesLen, tags, path, v.Type())
+	}
+	if u, ok := unmarshaler(v); ok {
+		return dec.decodeUnmarshaler(u, bytesLen, tags, path, v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if dec.borrowing() && isByteView(v, bytesLen, tags) {
+			b, err := dec.readView(v.Len())
+			if err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		l := v.Len()
+		for i := 0; i < l; i++ {
+			err := dec.decode(v.Index(i), bytesLen, tags, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if order := structByteOrder(v); order != nil {
+			defer dec.useOrder(order)()
+		}
+		if b, ok := dec.memoryBytes(v, bytesLen); ok {
+			if err := dec.readFull(b); err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+			return nil
+		}
+		plan := dec.structPlan(v.Type())
+		block, start := structBlockSize(v), dec.n
+		presence, err := dec.decodePresence(v, plan, path)
+		if err != nil {
+			return err
+		}
+		bit := 0
+		for i := range plan {
+			f := &plan[i]
+			if !dec.present(f) {
+				continue
+			}
+			if presence != nil && f.nullable {
+				bit++
+				if absent(presence, bit-1) {
+					field := v.Field(f.index)
+					field.Set(reflect.Zero(field.Type()))
+					continue
+				}
+			}
+			if f.tlv >= 0 && f.err == nil {
+				return dec.decodeTLV(v, plan[i:], bytesLen, path)
+			}
+			fieldPath := joinPath(path, f.name)
+			if f.offset >= 0 && f.err == nil {
+				if err := dec.seek(f.offset); err != nil {
+					return newDecodeError(fieldPath, v.Type().Field(f.index).Type, err)
+				}
+			}
+			n := dec.n
+			err := dec.decodeField(v, f, bytesLen, fieldPath)
+			if err != nil && f.optional && presence == nil && dec.n == n && errors.Is(err, ErrShortMessage) {
+				// The input ended before the field: it and the optional
+				// fields after it were not sent.
+				field := v.Field(f.index)
+				field.Set(reflect.Zero(field.Type()))
+				dec.truncated = true
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if block > 0 {
+			if err := dec.skipBlock(start, block); err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+		}
+	case reflect.Map:
+		return dec.decodeMap(v, bytesLen, tags, path)
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return dec.decode(v.Elem(), bytesLen, tags, path)
+	default:
+		size, ok := baseTypeSize(v)
+		if !ok {
+			err := newDecodeError(path, v.Type(), fmt.Errorf("%w: %s", ErrUnknownType, v.Kind()))
+			if dec.strict {
+				return err
+			}
+			dec.logger.Printf("[decodeBaseType] Error: %s", err)
+			return nil
+		}
+		width := size
+		if bytesLen != 0 {
+			if bytesLen < size && dec.narrows(v.Type(), bytesLen) {
+				b, err := dec.readView(bytesLen)
+				if err != nil {
+					return newDecodeError(path, v.Type(), err)
+				}
+				decodeBaseType(v, widenInt(b, v, size, dec.intOrder()), dec.intOrder())
+				return nil
+			}
+			if bytesLen < size {
+				return newDecodeError(path, v.Type(), ErrFieldTooLong)
+			}
+			width = bytesLen
+			if v.Kind() == reflect.String {
+				size = bytesLen
+			}
+		}
+		b, err := dec.readView(width)
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		if dec.byteOrder == binary.LittleEndian {
+			b = b[:size]
+		} else {
+			b = b[width-size:]
+		}
+		if v.Kind() == reflect.String && bytesLen != 0 {
+			if dec.byteOrder == binary.LittleEndian {
+				b = bytes.TrimRight(b, string(dec.padByte))
+			} else {
+				b = bytes.TrimLeft(b, string(dec.padByte))
+			}
+		}
+		if v.Kind() == reflect.String && dec.borrowing() {
+			v.SetString(viewString(b))
+			return nil
+		}
+		decodeBaseType(v, b, dec.intOrder())
+	}
+	return nil
+}
+
+// decodeField is the inverse of Encoder.encodeField.
+func (dec *Decoder) decodeField(v reflect.Value, f *fieldPlan, bytesLen int, path string) error {
+	field := v.Field(f.index)
+	if err := dec.fieldErr(f); err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	if f.flagged {
+		return nil
+	}
+	if f.flagBits != nil {
+		return dec.decodeFlags(v, f, path)
+	}
+	if f.sizeFrom >= 0 {
+		n, err := sizeValue(v.Field(f.sizeFrom))
+		if err != nil {
+			return newDecodeError(path, field.Type(), err)
+		}
+		if f.compress != "" {
+			return dec.decodeCompressed(field, f, n, path)
+		}
+		return dec.decodeReader(field, n, path)
+	}
+	if f.lenFrom >= 0 {
+		n, err := sizeValue(v.Field(f.lenFrom))
+		if err == nil {
+			err = dec.checkLen(n, field.Kind())
+		}
+		if err != nil {
+			return newDecodeError(path, field.Type(), err)
+		}
+		if n == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		bytesLen = n
+	}
+	if f.klv != nil {
+		return dec.decodeKLV(field, f, bytesLen, path)
+	}
+	if f.until != nil {
+		if err := dec.decodeUntil(field, f, bytesLen, path); err != nil {
+			return err
+		}
+		return dec.validate(field, f, path)
+	}
+	if err := dec.decodeFieldValue(field, f, bytesLen, path); err != nil {
+		return err
+	}
+	return dec.validate(field, f, path)
+}
+
+// decodeFieldValue is the inverse of Encoder.encodeFieldValue.
+func (dec *Decoder) decodeFieldValue(field reflect.Value, f *fieldPlan, bytesLen int, path string) (err error) {
+	if f.transform != "" {
+		defer func() {
+			if err == nil {
+				err = dec.untransformField(field, f, path)
+			}
+		}()
+	}
+	tag := f.fieldLen(bytesLen)
+	if f.order != nil {
+		defer dec.useOrder(f.order)()
+	}
+	if f.sizedEmbed() {
+		return dec.decodeEmbedded(field, f, path)
+	}
+	if f.ratio == 0 || tag == -1 {
+		return dec.decode(field, tag, f.tags, path)
+	}
+	tmp := reflect.New(field.Type()).Elem()
+	tmp.Set(field)
+	if err := dec.decode(tmp, tag, f.tags, path); err != nil {
+		return err
+	}
+	tmp, err = convertUnit(tmp, 1/f.ratio)
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	field.Set(tmp)
+	return nil
+}
+
+// decodeWire decodes a value of a type with a dedicated wire representation
+// (see toWire) and converts it back.
+func (dec *Decoder) decodeWire(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	var wireType reflect.Type
+	var err error
+	switch v.Type() {
+	case timeType:
+		wireType, err = timeWireType(tags.timefmt)
+	case durationType:
+		wireType = reflect.TypeOf(int64(0))
+	}
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	wire := reflect.New(wireType).Elem()
+	if err := dec.decode(wire, bytesLen, tags, path); err != nil {
+		return err
+	}
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(wireToTime(wire, tags.timefmt)))
+	case durationType:
+		d, err := wireToDuration(wire, tags.durfmt)
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		v.SetInt(int64(d))
+	}
+	return nil
+}
+
+// tick accounts for one visited value and enforces the watchdog limits.
+func (dec *Decoder) tick() error {
+	dec.steps++
+	if dec.maxSteps > 0 && dec.steps > dec.maxSteps {
+		return fmt.Errorf("%w: step budget of %d exhausted after %d bytes", ErrLimitExceeded, dec.maxSteps, dec.n)
+	}
+	if dec.timeout > 0 && time.Now().After(dec.deadline) {
+		return fmt.Errorf("%w: deadline of %s passed after %d bytes", ErrLimitExceeded, dec.timeout, dec.n)
+	}
+	return nil
+}
+
+// reserve checks that n more bytes may be read under WithMaxMessageSize.
+func (dec *Decoder) reserve(n int) error {
+	if dec.maxMessageSize > 0 && dec.n+n > dec.maxMessageSize {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d", ErrMessageTooLarge, dec.n+n, dec.maxMessageSize)
+	}
+	return nil
+}
+
+// checkLen checks a length of kind t read from the input against the
+// limits set by WithMaxSliceLen, WithMaxStringLen and WithMaxMessageSize.
+func (dec *Decoder) checkLen(n int, t reflect.Kind) error {
+	max := dec.maxSliceLen
+	if t == reflect.String {
+		max = dec.maxStringLen
+	}
+	if n < 0 || max > 0 && n > max {
+		return fmt.Errorf("%w: length %d exceeds the limit of %d", ErrLimitExceeded, n, max)
+	}
+	return dec.reserve(n)
+}
+
+func (dec *Decoder) readFull(b []byte) error {
+	if err := dec.reserve(len(b)); err != nil {
+		return err
+	}
+	n, err := io.ReadFull(dec.r, b)
+	dec.n += n
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrShortMessage
+	}
+	return err
+}
+
+// baseTypeSize returns the natural encoded size of a base type value. The
+// size of a string is its current length.
+func baseTypeSize(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.Bool, reflect.Uint8:
+		return 1, true
+	case reflect.Uint16, reflect.Int16:
+		return 2, true
+	case reflect.Uint32, reflect.Int32:
+		return 4, true
+	case reflect.Uint64, reflect.Int64, reflect.Complex64:
+		return 8, true
+	case reflect.Complex128:
+		return 16, true
+	case reflect.String:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// decodeBaseType is the inverse of encodeBaseType.
+func decodeBaseType(v reflect.Value, b []byte, order binary.ByteOrder) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(b[0] != 0)
+	case reflect.Uint8:
+		v.SetUint(uint64(b[0]))
+	case reflect.Uint16:
+		v.SetUint(uint64(order.Uint16(b)))
+	case reflect.Int16:
+		v.SetInt(int64(int16(order.Uint16(b))))
+	case reflect.Uint32:
+		v.SetUint(uint64(order.Uint32(b)))
+	case reflect.Int32:
+		v.SetInt(int64(int32(order.Uint32(b))))
+	case reflect.Uint64:
+		v.SetUint(order.Uint64(b))
+	case reflect.Int64:
+		v.SetInt(int64(order.Uint64(b)))
+	case reflect.Complex64:
+		re := math.Float32frombits(order.Uint32(b))
+		im := math.Float32frombits(order.Uint32(b[4:]))
+		v.SetComplex(complex(float64(re), float64(im)))
+	case reflect.Complex128:
+		v.SetComplex(complex(math.Float64frombits(order.Uint64(b)), math.Float64frombits(order.Uint64(b[8:]))))
+	case reflect.String:
+		v.SetString(string(b))
+	}
+}
@@ -0,0 +1,273 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+type roundTrip struct {
+	InM      [3]uint16
+	InSlice  []uint32
+	InPoint  *[]uint32
+	InBool   bool
+	InUint8  uint8
+	InInt16  int16
+	InInt32  int32
+	InUint64 uint64
+	InInt64  int64
+	InString string    `len:"10"`
+	InSkip   string    `len:"-"`
+	InTime   time.Time `timefmt:"unix32"`
+	InDur    time.Duration
+	InDurMs  time.Duration `durfmt:"ms"`
+	InUnit   uint16        `unit:"ms->s"`
+	InPad    []uint16      `len:"4"`
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	in := roundTrip{
+		InM:      [3]uint16{1, 2, 3},
+		InSlice:  []uint32{4, 5},
+		InPoint:  &[]uint32{6, 7},
+		InBool:   true,
+		InUint8:  15,
+		InInt16:  -255,
+		InInt32:  -70000,
+		InUint64: 1 << 40,
+		InInt64:  -1 << 40,
+		InString: "test",
+		InSkip:   "skipped",
+		InTime:   time.Unix(1600000000, 0).UTC(),
+		InDur:    1500 * time.Microsecond,
+		InDurMs:  1500 * time.Microsecond,
+		InUnit:   3000,
+		InPad:    []uint16{0xfffe},
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(order)).Encode(in, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		out := roundTrip{
+			InSlice: make([]uint32, 2),
+			InPoint: &[]uint32{0, 0},
+			InPad:   make([]uint16, 1),
+		}
+		if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(order)).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		want := in
+		want.InSkip = ""
+		want.InDurMs = 2 * time.Millisecond
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("%v: we have:\n%+v\n got:\n%+v\n", order, want, out)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%v: %d bytes left unread", order, buf.Len())
+		}
+	}
+}
+
+func TestDecodeShortMessage(t *testing.T) {
+	var v struct {
+		A uint16
+		B uint32
+	}
+	dec := binencoder.NewDecoder(bytes.NewReader([]byte{1, 0, 2}), binencoder.WithByteOrder(binary.LittleEndian))
+	err := dec.Decode(&v, 0)
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+	var decErr *binencoder.DecodeError
+	if !errors.As(err, &decErr) || decErr.Path != "B" {
+		t.Errorf("expected *DecodeError for B, got %v", err)
+	}
+
+	err = dec.Decode(&v, 0)
+	if err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	if err := dec.Decode(v, 0); err == nil {
+		t.Error("expected an error for a non-pointer")
+	}
+}
+
+func TestDecodeComplex(t *testing.T) {
+	type spectrum struct {
+		Peak complex64
+		Bins [2]complex128
+	}
+	in := spectrum{Peak: complex(1, -2), Bins: [2]complex128{complex(0.5, 0), complex(-1, 3)}}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 8+2*16 {
+		t.Fatalf("wrote %d bytes", buf.Len())
+	}
+	equalByte(t, buf.Bytes()[:8], []byte{0x3f, 0x80, 0, 0, 0xc0, 0, 0, 0})
+
+	var out spectrum
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %v, want %v", out, in)
+	}
+}
+
+func TestDecodeWatchdog(t *testing.T) {
+	data := make([]uint8, 1000)
+	dec := binencoder.NewDecoder(bytes.NewReader(make([]byte, len(data))), binencoder.WithByteOrder(binary.LittleEndian))
+	dec.SetWatchdog(100, 0)
+	err := dec.Decode(&struct{ Data []uint8 }{data}, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding Data[98] (uint8): limit exceeded: step budget of 100 exhausted after 98 bytes")
+
+	dec = binencoder.NewDecoder(bytes.NewReader(make([]byte, len(data))), binencoder.WithByteOrder(binary.LittleEndian))
+	dec.SetWatchdog(0, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	err = dec.Decode(&struct{ Data []uint8 }{data}, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	dec = binencoder.NewDecoder(bytes.NewReader(make([]byte, len(data))), binencoder.WithByteOrder(binary.LittleEndian))
+	dec.SetWatchdog(1002, time.Minute)
+	if err := dec.Decode(&struct{ Data []uint8 }{data}, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	type message struct {
+		Kind uint8
+		Name string `tlv:"1"`
+		Data []byte `tlv:"2"`
+	}
+	// A hostile length of 1 GiB for Data.
+	input := []byte{1, 1, 3, 'a', 'b', 'c', 2, 0x84, 0x40, 0, 0, 0}
+	decode := func(opts ...binencoder.Option) error {
+		var m message
+		opts = append(opts, binencoder.WithTLV(1, 0))
+		return binencoder.NewDecoder(bytes.NewReader(input), opts...).Decode(&m, 0)
+	}
+
+	err := decode(binencoder.WithMaxSliceLen(1024))
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding Data ([]uint8): limit exceeded: length 1073741824 exceeds the limit of 1024")
+
+	err = decode(binencoder.WithMaxStringLen(2))
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding Name (string): limit exceeded: length 3 exceeds the limit of 2")
+
+	err = decode(binencoder.WithMaxMessageSize(64))
+	if !errors.Is(err, binencoder.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding binencoder_test.message: message too large: 1073741836 bytes exceed the limit of 64")
+
+	err = binencoder.NewDecoder(bytes.NewReader(make([]byte, 8)), binencoder.WithMaxMessageSize(4)).Decode(new(uint64), 0)
+	if !errors.Is(err, binencoder.ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	type sized struct {
+		Len  uint32 `sizeof:"Body"`
+		Body io.Reader
+	}
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0x7f}), binencoder.WithMaxSliceLen(16)).Decode(new(sized), 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestDecodeOptional(t *testing.T) {
+	type record struct {
+		ID      uint8
+		Temp    int16
+		Battery uint16 `optional:"true"`
+		Name    string `len:"4" optional:"true"`
+	}
+	for _, c := range []struct {
+		input []byte
+		want  record
+	}{
+		{[]byte{1, 2, 0}, record{ID: 1, Temp: 2}},
+		{[]byte{1, 2, 0, 3, 0}, record{ID: 1, Temp: 2, Battery: 3}},
+		{[]byte{1, 2, 0, 3, 0, 'a', 'b', 0, 0}, record{1, 2, 3, "ab"}},
+	} {
+		out := record{Battery: 9, Name: "x"}
+		if err := binencoder.Unmarshal(c.input, &out, binary.LittleEndian); err != nil {
+			t.Fatal(err)
+		}
+		if out != c.want {
+			t.Errorf("% x: got %+v, want %+v", c.input, out, c.want)
+		}
+	}
+
+	var out record
+	if err := binencoder.Unmarshal([]byte{1, 2, 0, 3}, &out, binary.LittleEndian); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage for a truncated optional field, got %v", err)
+	}
+	if err := binencoder.Unmarshal([]byte{1}, &out, binary.LittleEndian); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage for a missing required field, got %v", err)
+	}
+	type trailer struct {
+		Battery uint16 `optional:"true"`
+	}
+	if err := binencoder.NewDecoder(bytes.NewReader(nil)).Decode(&trailer{}, 0); err != io.EOF {
+		t.Errorf("expected io.EOF for empty input, got %v", err)
+	}
+
+	type bad struct {
+		A uint8 `optional:"true"`
+		B uint8
+	}
+	if err := binencoder.Unmarshal([]byte{1, 2}, &bad{}, binary.LittleEndian); err == nil {
+		t.Error("expected an error for a required field after an optional one")
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	in := message{ID: 0x0102, Name: "ab"}
+	b, err := binencoder.Marshal(in, binary.BigEndian, binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0x01, 0x02, 0, 0, 'a', 'b'})
+
+	var out message
+	if err := binencoder.Unmarshal(b, &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	if err := binencoder.Unmarshal(nil, &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("empty input: got %v", err)
+	}
+	if err := binencoder.Unmarshal(append(b, 0), &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); err == nil {
+		t.Error("expected an error for trailing bytes")
+	}
+}
@@ -0,0 +1,144 @@
+package binencoder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// EncodeDelta writes the fields of struct curr that differ from prev, a
+// previous snapshot of the same type, for state synchronization where
+// sending the whole state each time is too costly. The message starts with
+// a bitmap holding a bit per field in declaration order, least significant
+// bit first, set for the fields that follow. Fields are compared with
+// reflect.DeepEqual and encoded with their tags.
+//
+// Fields with `offset`, `tlv` or `flags` tags cannot be sent on their own
+// and make EncodeDelta fail. Decoder.ApplyDelta reads the message.
+func (enc *Encoder) EncodeDelta(prev, curr interface{}) error {
+	if enc.format != FormatRaw {
+		return newEncodeError("", reflect.TypeOf(curr), fmt.Errorf("binencoder: deltas in %s are not supported", enc.format))
+	}
+	enc.prev = reflect.ValueOf(prev)
+	defer func() { enc.prev = reflect.Value{} }()
+	_, err := enc.EncodeN(curr, 0)
+	return err
+}
+
+// ApplyDelta reads a message written by Encoder.EncodeDelta and sets the
+// fields it holds in the struct data points to, which must hold the
+// snapshot the delta was computed from.
+func (dec *Decoder) ApplyDelta(data interface{}) error {
+	dec.delta = true
+	defer func() { dec.delta = false }()
+	return dec.Decode(data, 0)
+}
+
+// deltaStruct returns the struct v holds, following pointers.
+func deltaStruct(v reflect.Value) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("%w: delta of %s, not a struct", ErrUnknownType, typeOf(v))
+	}
+	return v, nil
+}
+
+// checkDeltaField reports whether field f can be sent on its own.
+func (c *config) checkDeltaField(f *fieldPlan) error {
+	if err := c.fieldErr(f); err != nil {
+		return err
+	}
+	switch {
+	case f.offset >= 0:
+		return errors.New("binencoder: offset fields cannot be delta-encoded")
+	case f.tlv >= 0:
+		return errors.New("binencoder: tlv fields cannot be delta-encoded")
+	case f.flagged || f.flagBits != nil:
+		return errors.New("binencoder: flags fields cannot be delta-encoded")
+	}
+	return nil
+}
+
+// encodeDelta encodes the bitmap of the fields of curr that differ from
+// prev, followed by those fields.
+func (enc *Encoder) encodeDelta(prev, curr reflect.Value) error {
+	curr, err := deltaStruct(curr)
+	if err != nil {
+		return enc.fail(0, typeOf(curr), err)
+	}
+	prev, err = deltaStruct(prev)
+	if err != nil || prev.Type() != curr.Type() {
+		return enc.fail(0, curr.Type(), fmt.Errorf("%w: previous snapshot of type %s", ErrUnknownType, typeOf(prev)))
+	}
+	if order := structByteOrder(curr); order != nil {
+		defer enc.useOrder(order)()
+	}
+	plan := enc.structPlan(curr.Type())
+	bitmap := make([]byte, (len(plan)+7)/8)
+	for i := range plan {
+		f := &plan[i]
+		if !enc.present(f) || reflect.DeepEqual(prev.Field(f.index).Interface(), curr.Field(f.index).Interface()) {
+			continue
+		}
+		if err := enc.checkDeltaField(f); err != nil {
+			return enc.fail(enc.childPath(0, f.name), curr.Type().Field(f.index).Type, err)
+		}
+		bitmap[i/8] |= 1 << uint(i%8)
+	}
+	if err := enc.write(bitmap); err != nil {
+		return enc.fail(0, curr.Type(), err)
+	}
+	for i := range plan {
+		if bitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		f := &plan[i]
+		fieldPath := enc.childPath(0, f.name)
+		if len(enc.interceptors) > 0 {
+			err = enc.interceptField(curr, f, 0, fieldPath)
+		} else {
+			err = enc.encodeField(curr, f, 0, fieldPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDelta is the inverse of Encoder.encodeDelta.
+func (dec *Decoder) applyDelta(v reflect.Value) error {
+	v, err := deltaStruct(v)
+	if err != nil {
+		return newDecodeError("", typeOf(v), err)
+	}
+	if order := structByteOrder(v); order != nil {
+		defer dec.useOrder(order)()
+	}
+	plan := dec.structPlan(v.Type())
+	bitmap := make([]byte, (len(plan)+7)/8)
+	if err := dec.readFull(bitmap); err != nil {
+		return newDecodeError("", v.Type(), err)
+	}
+	if n := len(plan) % 8; n != 0 && bitmap[len(bitmap)-1]>>uint(n) != 0 {
+		return newDecodeError("", v.Type(), fmt.Errorf("%w: delta bitmap names fields beyond the %d of %s", ErrInvalidValue, len(plan), v.Type()))
+	}
+	for i := range plan {
+		if bitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		f := &plan[i]
+		if !dec.present(f) {
+			return newDecodeError(f.name, v.Type().Field(f.index).Type, fmt.Errorf("%w: delta holds a field absent from version %d", ErrInvalidValue, dec.version))
+		}
+		if err := dec.checkDeltaField(f); err != nil {
+			return newDecodeError(f.name, v.Type().Field(f.index).Type, err)
+		}
+		if err := dec.decodeField(v, f, 0, f.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
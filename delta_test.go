@@ -0,0 +1,63 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestDelta(t *testing.T) {
+	type vec struct {
+		X, Y int16
+	}
+	type player struct {
+		ID     uint32
+		Pos    vec
+		Health uint8
+		Name   string `len:"4"`
+		Items  []uint16
+	}
+	prev := player{ID: 1, Pos: vec{10, 20}, Health: 100, Name: "bob", Items: []uint16{1}}
+	curr := prev
+	curr.Pos.X = 11
+	curr.Health = 90
+	curr.Items = []uint16{1}
+
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	if err := enc.EncodeDelta(prev, &curr); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x06, 11, 0, 20, 0, 90})
+
+	state := prev
+	state.Items = []uint16{1}
+	if err := binencoder.NewDecoder(bytes.NewReader(buf.Bytes())).ApplyDelta(&state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Pos != curr.Pos || state.Health != 90 || state.ID != 1 || state.Name != "bob" {
+		t.Errorf("got %+v", state)
+	}
+
+	buf.Reset()
+	if err := enc.EncodeDelta(curr, curr); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x00})
+	if err := enc.Encode(curr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() == 1 {
+		t.Error("Encode after EncodeDelta still wrote a delta")
+	}
+
+	if err := enc.EncodeDelta(vec{}, curr); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+	err := binencoder.NewDecoder(bytes.NewReader([]byte{0x40})).ApplyDelta(&state)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
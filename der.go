@@ -0,0 +1,140 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ASN.1 identifiers written by FormatDER.
+const (
+	derBoolean     = 0x01
+	derInteger     = 0x02
+	derOctetString = 0x04
+	derNull        = 0x05
+	derSequence    = 0x30
+
+	derContext     = 0x80
+	derConstructed = 0x20
+)
+
+// appendDER appends the DER encoding of v to b.
+func (enc *Encoder) appendDER(b []byte, v reflect.Value, tags fieldTags, path string) ([]byte, error) {
+	w, err := enc.formatValue(v, tags)
+	if err != nil {
+		return nil, newEncodeError(path, v.Type(), err)
+	}
+	v = w
+	if !v.IsValid() {
+		return append(b, derNull, 0), nil
+	}
+	var content []byte
+	id := byte(derSequence)
+	switch {
+	case v.Kind() == reflect.Bool:
+		id, content = derBoolean, []byte{0}
+		if v.Bool() {
+			content[0] = 0xff
+		}
+	case isInt(v.Kind()):
+		id, content = derInteger, appendDERInteger(nil, uint64(v.Int()), v.Int() < 0)
+	case isUint(v.Kind()):
+		id, content = derInteger, appendDERInteger(nil, v.Uint(), false)
+	case isBytes(v):
+		id, content = derOctetString, bytesOf(v)
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if content, err = enc.appendDER(content, v.Index(i), tags, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			}
+		}
+	case v.Kind() == reflect.Struct:
+		fields, err := enc.formatFields(v, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if content, err = enc.appendDERField(content, f); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, newEncodeError(path, v.Type(), fmt.Errorf("%w: %s in DER", ErrUnknownType, v.Kind()))
+	}
+	b = append(b, id)
+	b, _ = enc.appendLength(b, len(content), 0)
+	return append(b, content...), nil
+}
+
+// appendDERField appends a struct field, omitting nil pointers and
+// retagging the field with its `der` tag, if any.
+func (enc *Encoder) appendDERField(b []byte, f formatField) ([]byte, error) {
+	if f.value.Kind() == reflect.Ptr && f.value.IsNil() {
+		return b, nil
+	}
+	tag := enc.tag(f.field, "der")
+	if tag == "" {
+		return enc.appendDER(b, f.value, f.plan.tags, f.path)
+	}
+	n, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil {
+		return nil, newEncodeError(f.path, f.field.Type, fmt.Errorf("binencoder: invalid der tag %q", tag))
+	}
+	item, err := enc.appendDER(nil, f.value, f.plan.tags, f.path)
+	if err != nil {
+		return nil, err
+	}
+	id := derContext | item[0]&derConstructed
+	if n < 0x1f {
+		b = append(b, id|byte(n))
+	} else {
+		b = append(b, id|0x1f)
+		b = appendBase128(b, n)
+	}
+	return append(b, item[1:]...), nil
+}
+
+// appendDERInteger appends the minimal two's complement form of x, the
+// bits of a negative number if negative is set.
+func appendDERInteger(b []byte, x uint64, negative bool) []byte {
+	var w [9]byte
+	binary.BigEndian.PutUint64(w[1:], x)
+	if negative {
+		w[0] = 0xff
+	}
+	i := 0
+	for i < 8 && (w[i] == 0 && w[i+1]&0x80 == 0 || w[i] == 0xff && w[i+1]&0x80 != 0) {
+		i++
+	}
+	return append(b, w[i:]...)
+}
+
+// appendBase128 appends x in base 128, most significant group first, with
+// the high bit set on all but the last byte.
+func appendBase128(b []byte, x uint64) []byte {
+	var w [10]byte
+	i := len(w) - 1
+	w[i] = byte(x & 0x7f)
+	for x >>= 7; x != 0; x >>= 7 {
+		i--
+		w[i] = byte(x&0x7f) | 0x80
+	}
+	return append(b, w[i:]...)
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFormatDER(t *testing.T) {
+	type inner struct {
+		Values []int32
+	}
+	type message struct {
+		Version int64
+		Serial  uint64
+		Data    []byte
+		Valid   bool
+		Skipped uint8 `len:"-"`
+		Inner   inner
+		Neg     int16
+	}
+	in := message{
+		Version: 2,
+		Serial:  0xffffffffffffffff,
+		Data:    []byte("abc"),
+		Valid:   true,
+		Skipped: 9,
+		Inner:   inner{Values: []int32{0, 127, 128, -129}},
+		Neg:     -1,
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatDER)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// encoding/asn1 writes the same subset.
+	type asn1Message struct {
+		Version int64
+		Serial  asn1.RawValue
+		Data    []byte
+		Valid   bool
+		Inner   struct{ Values []int32 }
+		Neg     int16
+	}
+	serial := asn1.RawValue{Tag: asn1.TagInteger, Bytes: []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}}
+	want, err := asn1.Marshal(asn1Message{
+		Version: 2,
+		Serial:  serial,
+		Data:    []byte("abc"),
+		Valid:   true,
+		Inner:   struct{ Values []int32 }{[]int32{0, 127, 128, -129}},
+		Neg:     -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), want)
+}
+
+func TestFormatDERTags(t *testing.T) {
+	type message struct {
+		ID    uint8   `der:"0"`
+		Name  string  `der:"1"`
+		Opt   *uint8  `der:"2"`
+		Inner []uint8 `der:"40"`
+	}
+	b := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(b, binencoder.WithFormat(binencoder.FormatDER))
+	if err := enc.Encode(message{ID: 5, Name: "a", Inner: []uint8{1}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b.Bytes(), []byte{0x30, 10, 0x80, 1, 5, 0x81, 1, 'a', 0x9f, 40, 1, 1})
+
+	err := enc.Encode(struct{ F float32 }{}, 0)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+	dec := binencoder.NewDecoder(b, binencoder.WithFormat(binencoder.FormatDER))
+	if err := dec.Decode(&message{}, 0); err == nil {
+		t.Error("expected an error decoding DER")
+	}
+}
@@ -0,0 +1,101 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"reflect"
+)
+
+// Layout describes the encoding of a value field by field.
+type Layout struct {
+	// Size is the number of bytes the value is encoded with.
+	Size int
+	// Fields are the top-level struct fields, in encoding order.
+	Fields []*LayoutField
+}
+
+// LayoutField describes the encoding of a struct field.
+type LayoutField struct {
+	// Name is the Go field name and Path the field's path from the
+	// described value, e.g. "Points[1].X".
+	Name string
+	Path string
+	// Offset is the position of the field's first byte in the encoding and
+	// Size the number of bytes it takes, including padding.
+	Offset int
+	Size   int
+	Type   reflect.Type
+	Tag    reflect.StructTag
+	// ByteOrder is the byte order the field is encoded in.
+	ByteOrder binary.ByteOrder
+	// Fields are the fields of a struct field or of the struct elements of
+	// an array or slice field. TLV and KLV items have none.
+	Fields []*LayoutField
+}
+
+// Describe encodes v with opts and returns its layout, so that layout
+// tables can be rendered and offsets asserted in tests. Slices and strings
+// without a `len` tag are described with the length they have in v.
+func Describe(v interface{}, opts ...Option) (*Layout, error) {
+	enc := NewEncoder(ioutil.Discard, opts...)
+	enc.layout = new(layoutBuilder)
+	n, err := enc.EncodeN(v, 0)
+	if err != nil {
+		return nil, err
+	}
+	enc.layout.root.Size = n
+	return &enc.layout.root, nil
+}
+
+// Field returns the field at path, e.g. "Header.Flags" or "Points[1].X",
+// or nil if there is none.
+func (l *Layout) Field(path string) *LayoutField {
+	return findLayoutField(l.Fields, path)
+}
+
+func findLayoutField(fields []*LayoutField, path string) *LayoutField {
+	for _, f := range fields {
+		if f.Path == path {
+			return f
+		}
+		if len(path) > len(f.Path) && path[:len(f.Path)] == f.Path {
+			if found := findLayoutField(f.Fields, path); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// layoutBuilder builds a Layout from the fields an Encoder enters and
+// exits.
+type layoutBuilder struct {
+	root  Layout
+	stack []*LayoutField
+}
+
+func (b *layoutBuilder) enter(enc *Encoder, field reflect.StructField, f *fieldPlan, path int) {
+	lf := &LayoutField{
+		Name:      field.Name,
+		Path:      string(enc.pathBuf[:path]),
+		Offset:    enc.n,
+		Type:      field.Type,
+		Tag:       field.Tag,
+		ByteOrder: enc.byteOrder,
+	}
+	if f.order != nil {
+		lf.ByteOrder = f.order
+	}
+	if n := len(b.stack); n > 0 {
+		b.stack[n-1].Fields = append(b.stack[n-1].Fields, lf)
+	} else {
+		b.root.Fields = append(b.root.Fields, lf)
+	}
+	b.stack = append(b.stack, lf)
+}
+
+func (b *layoutBuilder) exit(end int) {
+	lf := b.stack[len(b.stack)-1]
+	lf.Size = end - lf.Offset
+	b.stack = b.stack[:len(b.stack)-1]
+}
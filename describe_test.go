@@ -0,0 +1,74 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestDescribe(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type message struct {
+		Header struct {
+			Version uint8
+			Flags   uint16 `endian:"be"`
+		}
+		Points []point
+		Name   string `len:"6"`
+		Extra  []byte `tlv:"1"`
+	}
+	l, err := binencoder.Describe(message{Points: make([]point, 2), Extra: []byte{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Size != 21 {
+		t.Errorf("size: got %d, want 21", l.Size)
+	}
+	if len(l.Fields) != 4 {
+		t.Fatalf("got %d top-level fields, want 4", len(l.Fields))
+	}
+	for _, c := range []struct {
+		path         string
+		offset, size int
+		order        binary.ByteOrder
+	}{
+		{"Header", 0, 3, binary.LittleEndian},
+		{"Header.Version", 0, 1, binary.LittleEndian},
+		{"Header.Flags", 1, 2, binary.BigEndian},
+		{"Points", 3, 8, binary.LittleEndian},
+		{"Points[1].Y", 9, 2, binary.LittleEndian},
+		{"Name", 11, 6, binary.LittleEndian},
+		{"Extra", 17, 4, binary.LittleEndian},
+	} {
+		f := l.Field(c.path)
+		if f == nil {
+			t.Errorf("%s: not found", c.path)
+			continue
+		}
+		if f.Offset != c.offset || f.Size != c.size || f.ByteOrder != c.order {
+			t.Errorf("%s: got offset %d, size %d, %v; want %d, %d, %v", c.path, f.Offset, f.Size, f.ByteOrder, c.offset, c.size, c.order)
+		}
+	}
+	name := l.Field("Name")
+	if name.Name != "Name" || name.Type != reflect.TypeOf("") || name.Tag.Get("len") != "6" {
+		t.Errorf("unexpected field %+v", name)
+	}
+	if len(l.Field("Points").Fields) != 2*2 || len(l.Field("Extra").Fields) != 0 {
+		t.Error("unexpected nested fields")
+	}
+	if l.Field("Missing") != nil {
+		t.Error("found a missing field")
+	}
+
+	type bad struct {
+		A uint8 `endian:"middle"`
+	}
+	if _, err := binencoder.Describe(bad{}); err == nil || !errors.As(err, new(*binencoder.EncodeError)) {
+		t.Errorf("expected an EncodeError, got %v", err)
+	}
+}
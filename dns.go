@@ -0,0 +1,169 @@
+package binencoder
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Limits of DNS names (RFC 1035).
+const (
+	dnsMaxLabel   = 63
+	dnsMaxName    = 255
+	dnsPointer    = 0xc0
+	dnsMaxPointer = 0x3fff
+)
+
+// parseDNSTag parses the value of a `dns` tag, "name" or "name compress",
+// reporting whether names are compressed.
+func parseDNSTag(tag string) (bool, error) {
+	switch tag {
+	case "name":
+		return false, nil
+	case "name compress":
+		return true, nil
+	}
+	return false, fmt.Errorf("binencoder: invalid dns tag %q", tag)
+}
+
+// dnsLabels splits a name such as "www.example.com." into its labels. The
+// root is "" or ".".
+func dnsLabels(name string) ([]string, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return nil, nil
+	}
+	labels := strings.Split(name, ".")
+	size := 1
+	for _, l := range labels {
+		if l == "" || len(l) > dnsMaxLabel {
+			return nil, fmt.Errorf("%w: DNS name %q has a label of %d bytes", ErrInvalidValue, name, len(l))
+		}
+		size += 1 + len(l)
+	}
+	if size > dnsMaxName {
+		return nil, fmt.Errorf("%w: DNS name %q is longer than %d bytes", ErrInvalidValue, name, dnsMaxName)
+	}
+	return labels, nil
+}
+
+// encodeDNSName encodes a string with a `dns` tag as length-prefixed labels
+// ending with the root label. With compression, the longest suffix already
+// written in the message is replaced by a pointer to it.
+func (enc *Encoder) encodeDNSName(v reflect.Value, tags fieldTags, path int) error {
+	compress, err := parseDNSTag(tags.dns)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	labels, err := dnsLabels(v.String())
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	// Offsets are only known in the writer of the message, not in the
+	// buffers of TLV items, embedded structs and the like.
+	compress = compress && enc.w == enc.namesW
+	var b []byte
+	for i := range labels {
+		suffix := strings.ToLower(strings.Join(labels[i:], "."))
+		if compress {
+			if off, ok := enc.names[suffix]; ok {
+				b = append(b, dnsPointer|byte(off>>8), byte(off))
+				return enc.writeDNSName(b, v, path)
+			}
+			if off := enc.n + len(b); off <= dnsMaxPointer {
+				if enc.names == nil {
+					enc.names = map[string]int{}
+				}
+				enc.names[suffix] = off
+			}
+		}
+		b = append(b, byte(len(labels[i])))
+		b = append(b, labels[i]...)
+	}
+	return enc.writeDNSName(append(b, 0), v, path)
+}
+
+func (enc *Encoder) writeDNSName(b []byte, v reflect.Value, path int) error {
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeDNSName is the inverse of Encoder.encodeDNSName. Following a
+// compression pointer needs a reader that implements io.Seeker, as it
+// points back to a name already read.
+func (dec *Decoder) decodeDNSName(v reflect.Value, tags fieldTags, path string) error {
+	if _, err := parseDNSTag(tags.dns); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	var labels []string
+	size := 1
+	b := make([]byte, dnsMaxLabel)
+	// The bytes after the first pointer are read from earlier in the
+	// message and not counted; jumped holds the position to return to.
+	var seeker io.Seeker
+	jumped, start := int64(-1), int64(0)
+	read := func(p []byte) error {
+		if jumped < 0 {
+			return dec.readFull(p)
+		}
+		if _, err := io.ReadFull(dec.r, p); err != nil {
+			return ErrShortMessage
+		}
+		return nil
+	}
+	for {
+		if err := read(b[:1]); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		n := int(b[0])
+		if n&dnsPointer == dnsPointer {
+			if err := read(b[1:2]); err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+			if jumped < 0 {
+				s, ok := dec.r.(io.Seeker)
+				if !ok {
+					return newDecodeError(path, v.Type(), fmt.Errorf("binencoder: DNS compression pointer needs a reader that can seek"))
+				}
+				pos, err := s.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return newDecodeError(path, v.Type(), err)
+				}
+				seeker, jumped, start = s, pos, pos-int64(dec.n)
+			}
+			// Pointers must point back, which also rules out loops.
+			off := int64(n&^dnsPointer)<<8 | int64(b[1])
+			pos, _ := seeker.Seek(0, io.SeekCurrent)
+			if start+off >= pos-2 {
+				return newDecodeError(path, v.Type(), fmt.Errorf("%w: DNS compression pointer %#x does not point back", ErrInvalidValue, off))
+			}
+			if _, err := seeker.Seek(start+off, io.SeekStart); err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+			continue
+		}
+		if n == 0 {
+			break
+		}
+		if n > dnsMaxLabel {
+			return newDecodeError(path, v.Type(), fmt.Errorf("%w: DNS label length %#x", ErrInvalidValue, n))
+		}
+		if size += 1 + n; size > dnsMaxName {
+			return newDecodeError(path, v.Type(), fmt.Errorf("%w: DNS name is longer than %d bytes", ErrInvalidValue, dnsMaxName))
+		}
+		if err := read(b[:n]); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		labels = append(labels, string(b[:n]))
+	}
+	if jumped >= 0 {
+		if _, err := seeker.Seek(jumped, io.SeekStart); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+	}
+	v.SetString(strings.Join(labels, "."))
+	return nil
+}
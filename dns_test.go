@@ -0,0 +1,56 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestDNSName(t *testing.T) {
+	type names struct {
+		Host  string `dns:"name compress"`
+		Mail  string `dns:"name compress"`
+		Type  uint16 `endian:"be"`
+		Plain string `dns:"name"`
+		Root  string `dns:"name"`
+	}
+	in := names{Host: "www.example.com", Mail: "mail.Example.com.", Type: 1, Plain: "example.com"}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}
+	want = append(want, 4, 'm', 'a', 'i', 'l', 0xc0, 4)
+	want = append(want, 0, 1)
+	want = append(want, 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0)
+	want = append(want, 0)
+	equalByte(t, buf.Bytes(), want)
+
+	var out names
+	if err := binencoder.NewDecoder(bytes.NewReader(want)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Host != "www.example.com" || out.Mail != "mail.example.com" || out.Type != 1 || out.Plain != "example.com" || out.Root != "" {
+		t.Errorf("got %+v", out)
+	}
+
+	if err := binencoder.NewDecoder(bytes.NewBuffer(want)).Decode(&out, 0); err == nil {
+		t.Error("expected an error following a pointer without io.Seeker")
+	}
+	forward := []byte{0xc0, 2, 0}
+	var one struct {
+		Name string `dns:"name"`
+	}
+	if err := binencoder.NewDecoder(bytes.NewReader(forward)).Decode(&one, 0); !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for a forward pointer, got %v", err)
+	}
+	for _, name := range []string{"a..b", strings.Repeat("a", 64) + ".com"} {
+		one.Name = name
+		if err := binencoder.NewEncoder(buf).Encode(one, 0); !errors.Is(err, binencoder.ErrInvalidValue) {
+			t.Errorf("%q: expected ErrInvalidValue, got %v", name, err)
+		}
+	}
+}
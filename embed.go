@@ -0,0 +1,117 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// defaultEmbedPrefix is the width of the size of `embed:"prefix"` structs
+// without a `prefix` tag.
+const defaultEmbedPrefix = "u32"
+
+// parseEmbedTag parses the value of an `embed` tag on a field of type t:
+// "inline", "prefix" or "skip".
+func parseEmbedTag(tag string, t reflect.Type) (string, error) {
+	switch tag {
+	case "", "inline", "prefix", "skip":
+	default:
+		return "", fmt.Errorf("binencoder: invalid embed tag %q", tag)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if tag != "" && t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("binencoder: embed tag on non-struct type %s", t)
+	}
+	return tag, nil
+}
+
+// sizedEmbed reports whether the field is a struct encoded as one block:
+// with a size prefix or padded to its `len` tag.
+func (f *fieldPlan) sizedEmbed() bool {
+	return f.embed == "prefix" || f.embed == "inline" && f.len > 0
+}
+
+// encodeEmbedded encodes a sizedEmbed struct field: the struct, padded at
+// the end to the field's length, if any, and preceded by its size for
+// `embed:"prefix"`.
+func (enc *Encoder) encodeEmbedded(field reflect.Value, f *fieldPlan, path int) error {
+	tags := f.tags
+	tags.prefix = ""
+	var body bytes.Buffer
+	w, n, trace, layout := enc.w, enc.n, enc.trace, enc.layout
+	enc.w, enc.trace, enc.layout = &body, nil, nil
+	err := enc.encode(field, 0, tags, path)
+	enc.w, enc.n, enc.trace, enc.layout = w, n, trace, layout
+	if err != nil {
+		return err
+	}
+	b := body.Bytes()
+	if f.len > 0 {
+		if len(b) > f.len {
+			return enc.fail(path, field.Type(), fmt.Errorf("%w: %d bytes exceed len %d", ErrFieldTooLong, len(b), f.len))
+		}
+		for len(b) < f.len {
+			b = append(b, enc.padByte)
+		}
+	}
+	if f.embed == "prefix" {
+		p, err := encodePrefix(len(b), embedPrefix(f.tags), enc.byteOrder)
+		if err != nil {
+			return enc.fail(path, field.Type(), err)
+		}
+		if err := enc.write(p); err != nil {
+			return enc.fail(path, field.Type(), err)
+		}
+	}
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	return nil
+}
+
+// decodeEmbedded is the inverse of Encoder.encodeEmbedded. The struct is
+// decoded from its block alone and bytes it leaves, such as padding or
+// fields of a newer revision, are skipped.
+func (dec *Decoder) decodeEmbedded(field reflect.Value, f *fieldPlan, path string) error {
+	size := f.len
+	if f.embed == "prefix" {
+		width, err := prefixWidth(embedPrefix(f.tags))
+		if err != nil {
+			return newDecodeError(path, field.Type(), err)
+		}
+		p := make([]byte, width)
+		if err := dec.readFull(p); err != nil {
+			return newDecodeError(path, field.Type(), err)
+		}
+		size = decodePrefix(p, dec.byteOrder)
+	}
+	if err := dec.checkLen(size, reflect.Struct); err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	var body bytes.Buffer
+	copied, err := io.CopyN(&body, dec.r, int64(size))
+	dec.n += int(copied)
+	if err == io.EOF {
+		err = ErrShortMessage
+	}
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	tags := f.tags
+	tags.prefix = ""
+	sub := *dec
+	sub.r = bytes.NewReader(body.Bytes())
+	err = sub.decode(field, 0, tags, path)
+	dec.steps = sub.steps
+	return err
+}
+
+func embedPrefix(tags fieldTags) string {
+	if tags.prefix == "" {
+		return defaultEmbedPrefix
+	}
+	return tags.prefix
+}
@@ -0,0 +1,86 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type EmbedHeader struct {
+	Version uint8
+	Kind    uint16
+}
+
+type EmbedTrailer struct {
+	CRC uint16
+}
+
+func TestEmbed(t *testing.T) {
+	type message struct {
+		EmbedHeader  `embed:"prefix" prefix:"u8"`
+		EmbedTrailer `embed:"skip"`
+		Body         uint8
+	}
+	in := message{EmbedHeader{1, 0x0203}, EmbedTrailer{9}, 4}
+	b, err := binencoder.Marshal(in, binary.BigEndian, binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{3, 1, 2, 3, 4})
+
+	var out message
+	if err := binencoder.Unmarshal(b, &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); err != nil {
+		t.Fatal(err)
+	}
+	in.EmbedTrailer = EmbedTrailer{}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %#v, want %#v", out, in)
+	}
+
+	// Bytes a newer revision appends to the prefixed struct are skipped.
+	if err := binencoder.Unmarshal([]byte{4, 1, 2, 3, 0xff, 4}, &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %#v, want %#v", out, in)
+	}
+}
+
+func TestEmbedLen(t *testing.T) {
+	type message struct {
+		EmbedHeader `embed:"inline" len:"5"`
+		Body        uint8
+	}
+	in := message{EmbedHeader{1, 0x0203}, 4}
+	b, err := binencoder.Marshal(in, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{1, 3, 2, 0, 0, 4})
+
+	var out message
+	if err := binencoder.Unmarshal(b, &out, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %#v, want %#v", out, in)
+	}
+
+	type short struct {
+		EmbedHeader `embed:"inline" len:"2"`
+	}
+	_, err = binencoder.Marshal(short{}, binary.LittleEndian)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+
+	type bad struct {
+		ID uint8 `embed:"inline"`
+	}
+	if _, err := binencoder.Marshal(bad{}, binary.LittleEndian); err == nil {
+		t.Error("expected an error for embed on a non-struct field")
+	}
+}
@@ -0,0 +1,58 @@
+package binencoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// textToWire decodes s, a string in the text encoding named by an
+// `encoding` tag, to the raw bytes it is written as.
+func textToWire(s, encoding string) ([]byte, error) {
+	var b []byte
+	var err error
+	switch encoding {
+	case "hex":
+		b, err = hex.DecodeString(s)
+	case "base64":
+		b, err = base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("binencoder: unknown encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidValue, encoding, err)
+	}
+	return b, nil
+}
+
+// textFromWire is the inverse of textToWire.
+func textFromWire(b []byte, encoding string) (string, error) {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(b), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+	return "", fmt.Errorf("binencoder: unknown encoding %q", encoding)
+}
+
+// decodeText decodes a string with an `encoding` tag: the raw bytes are
+// read like a string, with the length of the raw value v holds if bytesLen
+// is 0, and stored in the text encoding.
+func (dec *Decoder) decodeText(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	raw := reflect.New(v.Type()).Elem()
+	if bytesLen == 0 {
+		b, _ := textToWire(v.String(), tags.encoding)
+		raw.SetString(string(b))
+	}
+	if err := dec.decode(raw, bytesLen, fieldTags{}, path); err != nil {
+		return err
+	}
+	s, err := textFromWire([]byte(raw.String()), tags.encoding)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	v.SetString(s)
+	return nil
+}
@@ -0,0 +1,37 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestEncodingTag(t *testing.T) {
+	type config struct {
+		Key   string   `encoding:"hex" len:"4"`
+		Token string   `encoding:"base64"`
+		IDs   []string `encoding:"hex" len:"2"`
+	}
+	in := config{Key: "deadbeef", Token: "AQI=", IDs: []string{"0102", "0304"}}
+	b, err := binencoder.Marshal(in, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0xde, 0xad, 0xbe, 0xef, 1, 2, 1, 2, 3, 4})
+
+	out := config{Token: "AAA=", IDs: make([]string, 2)}
+	if err := binencoder.Unmarshal(b, &out, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	_, err = binencoder.Marshal(config{Key: "xyz"}, binary.LittleEndian)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for bad hex, got %v", err)
+	}
+}
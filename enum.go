@@ -0,0 +1,81 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// enum holds the named values of a registered enum type.
+type enum struct {
+	t     reflect.Type
+	names map[int64]string
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type]*enum{}
+	// enumsByName indexes enums by type name for `enum` tags.
+	enumsByName = map[string]*enum{}
+)
+
+// RegisterEnum registers the named values of the integer type t. Fields of
+// type t, and integer fields naming t with an `enum:"Mode"` tag, fail to
+// encode and decode with an error matching ErrInvalidValue if they hold
+// another value, and WithTrace shows the names of their values. Enums must
+// be registered before the types using them are first encoded or decoded.
+func RegisterEnum(t reflect.Type, values map[string]int64) {
+	e := &enum{t: t, names: make(map[int64]string, len(values))}
+	for name, v := range values {
+		e.names[v] = name
+	}
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[t] = e
+	enumsByName[t.Name()] = e
+}
+
+// fieldEnum returns the enum of a field of type t with the given `enum` tag,
+// or nil.
+func fieldEnum(t reflect.Type, tag string) (*enum, error) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	if tag == "" {
+		return enums[t], nil
+	}
+	e, ok := enumsByName[tag]
+	if !ok {
+		return nil, fmt.Errorf("binencoder: unknown enum %q", tag)
+	}
+	return e, nil
+}
+
+// value returns the integer held by v and whether v is an integer.
+func (e *enum) value(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// check returns an error matching ErrInvalidValue unless v holds one of the
+// enum's values.
+func (e *enum) check(v reflect.Value) error {
+	x, ok := e.value(v)
+	if !ok {
+		return fmt.Errorf("%w: enum %s on a %s", ErrInvalidValue, e.t, v.Type())
+	}
+	if _, ok := e.names[x]; !ok {
+		return fmt.Errorf("%w: %s is not a %s value", ErrInvalidValue, formatScalar(v), e.t)
+	}
+	return nil
+}
+
+// name returns the name of the value of v, or "".
+func (e *enum) name(v reflect.Value) string {
+	x, _ := e.value(v)
+	return e.names[x]
+}
@@ -0,0 +1,56 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type enumMode uint8
+
+func init() {
+	binencoder.RegisterEnum(reflect.TypeOf(enumMode(0)), map[string]int64{"idle": 0, "run": 1})
+}
+
+func TestEnum(t *testing.T) {
+	type message struct {
+		Mode enumMode
+		Raw  uint16 `enum:"enumMode"`
+	}
+	var trace, buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf, binencoder.WithTrace(&trace)).Encode(message{1, 0}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, trace.String(), "     0    1 Mode: 01 (run)\n     1    2 Raw: 00 00 (idle)\n")
+
+	err := binencoder.NewEncoder(&buf).Encode(message{Mode: 2}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: encoding Mode (binencoder_test.enumMode): invalid value: 2 is not a binencoder_test.enumMode value")
+
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0, 5, 0})).Decode(&message{}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+
+	// Enum types are checked in the fast path for fixed layouts, too.
+	type fixed struct {
+		Mode enumMode
+		Pad  uint8
+	}
+	err = binencoder.NewEncoder(&buf, binencoder.WithUnsafe(true)).Encode(fixed{Mode: 3}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue with WithUnsafe, got %v", err)
+	}
+
+	type unknown struct {
+		A uint8 `enum:"Missing"`
+	}
+	if err := binencoder.NewEncoder(&buf).Encode(unknown{}, 0); err == nil {
+		t.Error("expected an error for an unknown enum")
+	}
+}
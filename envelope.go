@@ -0,0 +1,138 @@
+package binencoder
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+// WithAESGCM makes an Encoder seal every message in an AES-GCM envelope
+// with key, 16, 24 or 32 bytes long: a random nonce followed by the
+// ciphertext and the authentication tag. A Decoder with the same key opens
+// the envelope, returning an error matching ErrBadChecksum if it was
+// tampered with. As the ciphertext carries no length, a Decoder takes the
+// next frame of a COBSReader, SLIPReader or HDLCReader or else the rest of
+// its input as the envelope; use it with FramedDecoder or Unmarshal on
+// streams. With WithTrailerChecksum the checksum covers the envelope.
+func WithAESGCM(key []byte) Option {
+	return func(c *config) {
+		c.sealKey = key
+	}
+}
+
+// WithRand sets the source of the random nonces of WithAESGCM envelopes,
+// crypto/rand.Reader by default. A fixed source makes the envelopes
+// reproducible for golden tests; it must never be used in production, as
+// AES-GCM is broken by reusing a nonce with the same key.
+func WithRand(r io.Reader) Option {
+	return func(c *config) {
+		c.rand = r
+	}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("binencoder: envelope key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeEnvelope seals plain, the message of type t, and writes the
+// envelope in its place.
+func (enc *Encoder) writeEnvelope(plain []byte, t reflect.Type) error {
+	aead, err := newAEAD(enc.sealKey)
+	if err != nil {
+		return enc.fail(0, t, err)
+	}
+	b := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	src := enc.rand
+	if src == nil {
+		src = rand.Reader
+	}
+	if _, err := io.ReadFull(src, b); err != nil {
+		return enc.fail(0, t, err)
+	}
+	b = aead.Seal(b, b, plain, nil)
+	enc.n = 0
+	if err := enc.write(b); err != nil {
+		return enc.fail(0, t, err)
+	}
+	return nil
+}
+
+// frameSource is implemented by the readers of delimited frames.
+type frameSource interface {
+	ReadFrame() ([]byte, error)
+}
+
+// decodeEnvelope reads an envelope sealed by Encoder.writeEnvelope, opens
+// it and decodes its message into v, which must take all of it.
+func (dec *Decoder) decodeEnvelope(v reflect.Value, bytesLen int) error {
+	t := v.Type()
+	var frame []byte
+	var err error
+	if fs, ok := dec.r.(frameSource); ok {
+		frame, err = fs.ReadFrame()
+	} else {
+		r := dec.r
+		if dec.maxMessageSize > 0 {
+			r = io.LimitReader(r, int64(dec.maxMessageSize)+1)
+		}
+		frame, err = ioutil.ReadAll(r)
+	}
+	if err != nil {
+		return err
+	}
+	if len(frame) == 0 {
+		return io.EOF
+	}
+	if dec.maxMessageSize > 0 && len(frame) > dec.maxMessageSize {
+		return newDecodeError("", t, fmt.Errorf("%w: envelope exceeds the limit of %d bytes", ErrMessageTooLarge, dec.maxMessageSize))
+	}
+	if dec.trailer != nil {
+		h, err := dec.trailer.hash()
+		if err != nil {
+			return newDecodeError("", t, err)
+		}
+		if len(frame) < h.Size() {
+			return newDecodeError("", t, ErrShortMessage)
+		}
+		n := len(frame) - h.Size()
+		h.Write(frame[:n])
+		if err := dec.checkTrailer(frame[n:], h, t); err != nil {
+			return err
+		}
+		frame = frame[:n]
+	}
+	aead, err := newAEAD(dec.sealKey)
+	if err != nil {
+		return newDecodeError("", t, err)
+	}
+	if len(frame) < aead.NonceSize()+aead.Overhead() {
+		return newDecodeError("", t, ErrShortMessage)
+	}
+	nonce, sealed := frame[:aead.NonceSize()], frame[aead.NonceSize():]
+	plain, err := aead.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return newDecodeError("", t, fmt.Errorf("%w: envelope authentication failed", ErrBadChecksum))
+	}
+
+	r := dec.r
+	pr := bytes.NewReader(plain)
+	dec.r = pr
+	err = dec.decodeMessage(v, bytesLen, nil)
+	dec.r = r
+	if err == io.EOF {
+		return ErrShortMessage
+	}
+	if err == nil && pr.Len() != 0 {
+		return fmt.Errorf("binencoder: %d bytes left in envelope after decoding %s", pr.Len(), t)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestAESGCM(t *testing.T) {
+	type reading struct {
+		Sensor uint8
+		Value  int32
+	}
+	key := []byte("0123456789abcdef")
+	opts := []binencoder.Option{binencoder.WithAESGCM(key), binencoder.WithTrailerChecksum(crc32.IEEE)}
+	buf := new(bytes.Buffer)
+	n, err := binencoder.NewEncoder(buf, opts...).EncodeN(reading{1, -5}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 12 + 5 + 16 + 4; n != want || buf.Len() != want {
+		t.Fatalf("wrote %d bytes, buffer holds %d, want %d", n, buf.Len(), want)
+	}
+	if bytes.Contains(buf.Bytes(), []byte{0xfb, 0xff, 0xff, 0xff}) {
+		t.Error("the envelope holds the plaintext")
+	}
+	b := append([]byte(nil), buf.Bytes()...)
+
+	var out reading
+	if err := binencoder.NewDecoder(buf, opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != (reading{1, -5}) {
+		t.Errorf("got %+v", out)
+	}
+
+	b[len(b)-5] ^= 1
+	b = b[:len(b)-4]
+	err = binencoder.NewDecoder(bytes.NewReader(b), binencoder.WithAESGCM(key)).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrBadChecksum) {
+		t.Errorf("expected ErrBadChecksum for a tampered envelope, got %v", err)
+	}
+}
+
+func TestAESGCMFrames(t *testing.T) {
+	key := make([]byte, 32)
+	var stream bytes.Buffer
+	enc := binencoder.NewEncoder(binencoder.NewCOBSWriter(&stream), binencoder.WithAESGCM(key))
+	for i := uint16(1); i <= 2; i++ {
+		if err := enc.Encode(i, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dec := binencoder.NewDecoder(binencoder.NewCOBSReader(&stream), binencoder.WithAESGCM(key))
+	for i := uint16(1); i <= 2; i++ {
+		var out uint16
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != i {
+			t.Errorf("got %d, want %d", out, i)
+		}
+	}
+	var out uint16
+	if err := dec.Decode(&out, 0); err != io.EOF {
+		t.Errorf("expected io.EOF at the end, got %v", err)
+	}
+}
+
+func TestAESGCMRand(t *testing.T) {
+	type reading struct {
+		Sensor uint8
+		Value  int32
+	}
+	key := []byte("0123456789abcdef")
+	nonces := bytes.NewReader([]byte("nonce-000001nonce-000002"))
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithAESGCM(key), binencoder.WithRand(nonces))
+	for _, want := range [][]byte{
+		{
+			'n', 'o', 'n', 'c', 'e', '-', '0', '0', '0', '0', '0', '1',
+			0xf7, 0x06, 0x49, 0x4d, 0xbc,
+			0x0e, 0xc7, 0x26, 0x96, 0x13, 0xa3, 0xe3, 0x24, 0x38, 0xa4, 0x48, 0x9a, 0xe9, 0xb4, 0xa3, 0x65,
+		},
+		{
+			'n', 'o', 'n', 'c', 'e', '-', '0', '0', '0', '0', '0', '2',
+			0xc3, 0x7b, 0xa4, 0xe6, 0xdc,
+			0x07, 0xc0, 0x46, 0x9b, 0x74, 0x24, 0x93, 0xed, 0x4a, 0x0c, 0x4b, 0x23, 0x19, 0x96, 0x00, 0x15,
+		},
+	} {
+		buf.Reset()
+		if err := enc.Encode(reading{1, -5}, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), want)
+	}
+
+	if err := enc.Encode(reading{1, -5}, 0); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF from the exhausted source, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package binencoder
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Code classifies encoding and decoding failures so that callers can map them
+// to protocol-level reason codes (NAKs) without matching error strings.
+type Code int
+
+const (
+	CodeOK Code = iota
+	CodeUnknown
+	CodeOverflow
+	CodeUnknownType
+	CodeShortMessage
+	CodeBadChecksum
+	CodeBadMagic
+	CodeFieldTooLong
+	CodeLimitExceeded
+	CodeMessageTooLarge
+	CodeBadFrame
+	CodeInvalidValue
+)
+
+var codeNames = map[Code]string{
+	CodeOK:              "ok",
+	CodeUnknown:         "unknown",
+	CodeOverflow:        "overflow",
+	CodeUnknownType:     "unknown type",
+	CodeShortMessage:    "short message",
+	CodeBadChecksum:     "bad checksum",
+	CodeBadMagic:        "bad magic",
+	CodeFieldTooLong:    "field too long",
+	CodeLimitExceeded:   "limit exceeded",
+	CodeMessageTooLarge: "message too large",
+	CodeBadFrame:        "bad frame",
+	CodeInvalidValue:    "invalid value",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return "code(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Error is a classified error. Two *Error values with the same Code match
+// each other under errors.Is, so the exported sentinels can be used to test
+// any error of their class.
+type Error struct {
+	Code Code
+	msg  string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	ErrOverflow        = &Error{Code: CodeOverflow, msg: "binencoder: value overflows field"}
+	ErrUnknownType     = &Error{Code: CodeUnknownType, msg: "binencoder: unsupported type"}
+	ErrShortMessage    = &Error{Code: CodeShortMessage, msg: "binencoder: short message"}
+	ErrBadChecksum     = &Error{Code: CodeBadChecksum, msg: "binencoder: bad checksum"}
+	ErrBadMagic        = &Error{Code: CodeBadMagic, msg: "binencoder: bad magic"}
+	ErrFieldTooLong    = &Error{Code: CodeFieldTooLong, msg: "binencoder: field too long"}
+	ErrLimitExceeded   = &Error{Code: CodeLimitExceeded, msg: "binencoder: limit exceeded"}
+	ErrMessageTooLarge = &Error{Code: CodeMessageTooLarge, msg: "binencoder: message too large"}
+	ErrBadFrame        = &Error{Code: CodeBadFrame, msg: "binencoder: bad frame"}
+	ErrInvalidValue    = &Error{Code: CodeInvalidValue, msg: "binencoder: invalid value"}
+)
+
+// EncodeError describes a failure to encode a value, giving the path to the
+// offending field (e.g. "Header.Options[3].Name") and its Go type.
+type EncodeError struct {
+	Path string
+	Type reflect.Type
+	Err  error
+}
+
+func newEncodeError(path string, t reflect.Type, err error) error {
+	var e *EncodeError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &EncodeError{Path: path, Type: t, Err: err}
+}
+
+func (e *EncodeError) Error() string {
+	return "binencoder: encoding " + describeField(e.Path, e.Type) + ": " + strings.TrimPrefix(e.Err.Error(), "binencoder: ")
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError describes a failure to decode a value, giving the path to the
+// offending field and its Go type.
+type DecodeError struct {
+	Path string
+	Type reflect.Type
+	Err  error
+}
+
+func newDecodeError(path string, t reflect.Type, err error) error {
+	var e *DecodeError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &DecodeError{Path: path, Type: t, Err: err}
+}
+
+func (e *DecodeError) Error() string {
+	return "binencoder: decoding " + describeField(e.Path, e.Type) + ": " + strings.TrimPrefix(e.Err.Error(), "binencoder: ")
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func describeField(path string, t reflect.Type) string {
+	typ := "nil"
+	if t != nil {
+		typ = t.String()
+	}
+	if path == "" {
+		return typ
+	}
+	return path + " (" + typ + ")"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// ErrorCode returns the Code of the first classified error in err's chain,
+// CodeOK for a nil error and CodeUnknown for errors from other sources.
+func ErrorCode(err error) Code {
+	if err == nil {
+		return CodeOK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeUnknown
+}
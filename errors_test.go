@@ -0,0 +1,83 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestErrorCode(t *testing.T) {
+	buf := new(bytes.Buffer)
+	encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	err := encoder.Encode(struct {
+		S string `len:"2"`
+	}{S: "test"}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("We have:\n%v\n got:\n%v\n", binencoder.ErrFieldTooLong, err)
+	}
+
+	codes := map[error]binencoder.Code{
+		nil:                        binencoder.CodeOK,
+		err:                        binencoder.CodeFieldTooLong,
+		binencoder.ErrOverflow:     binencoder.CodeOverflow,
+		errors.New("other"):        binencoder.CodeUnknown,
+		binencoder.ErrShortMessage: binencoder.CodeShortMessage,
+		fmt.Errorf("wrapped: %w", binencoder.ErrBadChecksum): binencoder.CodeBadChecksum,
+	}
+	for e, code := range codes {
+		if got := binencoder.ErrorCode(e); got != code {
+			t.Errorf("ErrorCode(%v): we have %s, got %s", e, code, got)
+		}
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	type option struct {
+		Name string `len:"4"`
+	}
+	type header struct {
+		Options []option
+	}
+	buf := new(bytes.Buffer)
+	encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	err := encoder.Encode(struct{ Header header }{
+		Header: header{Options: []option{{"a"}, {"b"}, {"c"}, {"long"}, {"longer"}}},
+	}, 0)
+
+	var encErr *binencoder.EncodeError
+	if !errors.As(err, &encErr) {
+		t.Fatalf("expected *EncodeError, got %T", err)
+	}
+	equalErr(t, encErr.Path, "Header.Options[4].Name")
+	equalErr(t, encErr.Type.String(), "string")
+	equalErr(t, err.Error(), "binencoder: encoding Header.Options[4].Name (string): field too long")
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+}
+
+func TestEncodeNilPointer(t *testing.T) {
+	type point struct {
+		X, Y uint8
+	}
+	b, err := binencoder.Marshal(struct{ P *point }{}, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0, 0})
+
+	type node struct {
+		V    uint8
+		Next *node
+	}
+	_, err = binencoder.Marshal(node{V: 1, Next: &node{V: 2}}, binary.LittleEndian)
+	var encErr *binencoder.EncodeError
+	if !errors.As(err, &encErr) || !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Fatalf("expected an EncodeError matching ErrUnknownType, got %v", err)
+	}
+	equalErr(t, encErr.Path, "Next.Next.Next")
+}
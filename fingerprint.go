@@ -0,0 +1,124 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
+)
+
+// Fingerprint returns a hash of the wire layout of v's type under opts:
+// field order, widths, byte orders, tags and the options that change the
+// encoding. Field names and values do not take part, so peers can exchange
+// fingerprints at a handshake and refuse to talk on schema drift instead of
+// misparsing each other's messages.
+func Fingerprint(v interface{}, opts ...Option) uint64 {
+	var c config
+	c.init(opts)
+	h := fnv.New64a()
+	var trailer string
+	if c.trailer != nil {
+		trailer = c.trailer.name
+	}
+	fmt.Fprintf(h, "order=%v pad=%d tlv=%d/%d presence=%t version=%d format=%v trailer=%q sealed=%t ints=%t\n",
+		c.byteOrder, c.padByte, c.tlvTag, c.tlvLen, c.presence, c.version, c.format, trailer, c.sealKey != nil, c.orderedInts)
+	c.fingerprint(h, reflect.TypeOf(v), 0, fieldTags{}, c.byteOrder, map[reflect.Type]int{})
+	return h.Sum64()
+}
+
+// fingerprint writes the layout of type t, encoded with length bytesLen,
+// tags and byte order to h. A struct met again is written as a reference
+// to its first appearance.
+func (c *config) fingerprint(h hash.Hash64, t reflect.Type, bytesLen int, tags fieldTags, order binary.ByteOrder, seen map[reflect.Type]int) {
+	if t == nil {
+		fmt.Fprintln(h, "nil")
+		return
+	}
+	switch t {
+	case timeType, durationType, ipType, ipNetType, hardwareAddrType, bigIntType, readerType, rawBytesType:
+		fmt.Fprintf(h, "%s len=%d order=%v\n", t, bytesLen, order)
+		return
+	}
+	if customEncoding(t) && t.Kind() != reflect.Struct {
+		fmt.Fprintf(h, "custom %s len=%d order=%v\n", t, bytesLen, order)
+		return
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		fmt.Fprintf(h, "[%d]\n", t.Len())
+		c.fingerprint(h, t.Elem(), bytesLen, tags, order, seen)
+	case reflect.Slice:
+		fmt.Fprintln(h, "[]")
+		c.fingerprint(h, t.Elem(), bytesLen, tags, order, seen)
+	case reflect.Ptr:
+		c.fingerprint(h, t.Elem(), bytesLen, tags, order, seen)
+	case reflect.Map:
+		fmt.Fprintf(h, "map prefix=%q\n", tags.prefix)
+		tags.prefix = ""
+		c.fingerprint(h, t.Key(), bytesLen, tags, order, seen)
+		c.fingerprint(h, t.Elem(), bytesLen, tags, order, seen)
+	case reflect.Interface:
+		fmt.Fprintf(h, "interface typeid=%q\n", tags.typeid)
+	case reflect.Struct:
+		if n, ok := seen[t]; ok {
+			fmt.Fprintf(h, "ref %d\n", n)
+			return
+		}
+		seen[t] = len(seen)
+		if customEncoding(t) {
+			// Custom encoders may depend on the type's own layout, which
+			// follows.
+			fmt.Fprintf(h, "custom %s\n", t)
+		}
+		if o := structByteOrder(reflect.New(t).Elem()); o != nil {
+			order = o
+		}
+		if n := structBlockSize(reflect.New(t).Elem()); n > 0 {
+			fmt.Fprintf(h, "block %d\n", n)
+		}
+		plan := c.structPlan(t)
+		fmt.Fprintf(h, "struct %d len=%d\n", len(plan), bytesLen)
+		for i := range plan {
+			f := &plan[i]
+			if !c.present(f) {
+				continue
+			}
+			c.fingerprintField(h, plan, f)
+			fieldOrder := order
+			if f.order != nil {
+				fieldOrder = f.order
+			}
+			c.fingerprint(h, t.Field(f.index).Type, f.fieldLen(bytesLen), f.tags, fieldOrder, seen)
+		}
+	default:
+		fmt.Fprintf(h, "%s len=%d order=%v\n", t.Kind(), bytesLen, order)
+	}
+}
+
+// fingerprintField writes the settings of field f of plan to h. Links
+// between fields are written as positions in plan.
+func (c *config) fingerprintField(h hash.Hash64, plan []fieldPlan, f *fieldPlan) {
+	pos := func(index int) int {
+		for i := range plan {
+			if plan[i].index == index {
+				return i
+			}
+		}
+		return -1
+	}
+	var u until
+	if f.until != nil {
+		u = *f.until
+	}
+	flags := make([]int, len(f.flagBits))
+	for i, index := range f.flagBits {
+		flags[i] = pos(index)
+	}
+	var msg string
+	if err := c.fieldErr(f); err != nil {
+		msg = err.Error()
+	}
+	fmt.Fprintf(h, "field tags=%+v ratio=%g size=%d len=%d compress=%q tlv=%d klv=%x optional=%t until=%+v embed=%q offset=%d flags=%v/%d flagged=%t err=%q\n",
+		f.tags, f.ratio, pos(f.sizeFrom), pos(f.lenFrom), f.compress, f.tlv, f.klv, f.optional, u, f.embed, f.offset, flags, f.flagWidth, f.flagged, msg)
+}
@@ -0,0 +1,54 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFingerprint(t *testing.T) {
+	type v1 struct {
+		ID    uint32
+		Name  string `len:"8"`
+		Temps []int16
+	}
+	type renamed struct {
+		Key   uint32
+		Label string `len:"8"`
+		Data  []int16
+	}
+	type wider struct {
+		ID    uint64
+		Name  string `len:"8"`
+		Temps []int16
+	}
+	type bigEndian struct {
+		ID    uint32 `endian:"be"`
+		Name  string `len:"8"`
+		Temps []int16
+	}
+	type node struct {
+		Value uint8
+		Next  *node
+	}
+
+	fp := binencoder.Fingerprint(v1{})
+	if fp != binencoder.Fingerprint(&v1{Name: "x", Temps: []int16{1}}) {
+		t.Error("fingerprint depends on values")
+	}
+	if fp != binencoder.Fingerprint(renamed{}) {
+		t.Error("fingerprint depends on field names")
+	}
+	for name, other := range map[string]uint64{
+		"wider field":    binencoder.Fingerprint(wider{}),
+		"endian tag":     binencoder.Fingerprint(bigEndian{}),
+		"byte order":     binencoder.Fingerprint(v1{}, binencoder.WithByteOrder(binary.BigEndian)),
+		"presence":       binencoder.Fingerprint(v1{}, binencoder.WithPresence(true)),
+		"recursive type": binencoder.Fingerprint(node{}),
+	} {
+		if other == fp {
+			t.Errorf("%s does not change the fingerprint", name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package binencoder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// scaled is a parsed `fixed` or `scale` tag: a float is written as the
+// integer nearest to the value times factor, bits wide.
+type scaled struct {
+	factor float64
+	bits   uint
+	signed bool
+}
+
+// parseScaled parses the `fixed` tag, a Q format such as "Q8.8" (signed,
+// the integer part counting the sign bit) or "UQ8.8", or else the `scale`
+// tag, a factor optionally followed by the wire type, e.g. "100 i16". The
+// wire type of a scale defaults to i32.
+func parseScaled(tags fieldTags) (scaled, error) {
+	if tags.fixed != "" {
+		return parseQFormat(tags.fixed)
+	}
+	words := strings.Fields(tags.scale)
+	if len(words) == 0 || len(words) > 2 {
+		return scaled{}, fmt.Errorf("binencoder: invalid scale tag %q", tags.scale)
+	}
+	factor, err := strconv.ParseFloat(words[0], 64)
+	if err != nil || !(factor > 0) || math.IsInf(factor, 0) {
+		return scaled{}, fmt.Errorf("binencoder: invalid scale tag %q", tags.scale)
+	}
+	s := scaled{factor: factor, bits: 32, signed: true}
+	if len(words) == 2 {
+		switch words[1] {
+		case "i8", "u8":
+			s.bits = 8
+		case "i16", "u16":
+			s.bits = 16
+		case "i32", "u32":
+			s.bits = 32
+		case "i64", "u64":
+			s.bits = 64
+		default:
+			return scaled{}, fmt.Errorf("binencoder: unknown scale type %q", words[1])
+		}
+		s.signed = words[1][0] == 'i'
+	}
+	return s, nil
+}
+
+func parseQFormat(tag string) (scaled, error) {
+	s := scaled{signed: true}
+	q := tag
+	if strings.HasPrefix(q, "U") {
+		s.signed, q = false, q[1:]
+	}
+	dot := strings.IndexByte(q, '.')
+	if !strings.HasPrefix(q, "Q") || dot < 0 {
+		return scaled{}, fmt.Errorf("binencoder: invalid fixed tag %q", tag)
+	}
+	m, errM := strconv.Atoi(q[1:dot])
+	n, errN := strconv.Atoi(q[dot+1:])
+	if errM != nil || errN != nil || m < 0 || n < 0 {
+		return scaled{}, fmt.Errorf("binencoder: invalid fixed tag %q", tag)
+	}
+	switch m + n {
+	case 8, 16, 32, 64:
+	default:
+		return scaled{}, fmt.Errorf("binencoder: fixed tag %q is not 8, 16, 32 or 64 bits wide", tag)
+	}
+	s.bits, s.factor = uint(m+n), math.Ldexp(1, n)
+	return s, nil
+}
+
+// wireType returns the unsigned integer type the wire value is held in;
+// signed values are stored as their two's complement.
+func (s scaled) wireType() reflect.Type {
+	switch s.bits {
+	case 8:
+		return reflect.TypeOf(uint8(0))
+	case 16:
+		return reflect.TypeOf(uint16(0))
+	case 32:
+		return reflect.TypeOf(uint32(0))
+	}
+	return reflect.TypeOf(uint64(0))
+}
+
+// toWire returns the wire value of x.
+func (s scaled) toWire(x float64) (reflect.Value, error) {
+	if math.IsNaN(x) {
+		return reflect.Value{}, fmt.Errorf("%w: NaN cannot be scaled", ErrInvalidValue)
+	}
+	r := math.Round(x * s.factor)
+	lo, hi := 0.0, math.Ldexp(1, int(s.bits))
+	if s.signed {
+		lo, hi = -math.Ldexp(1, int(s.bits)-1), math.Ldexp(1, int(s.bits)-1)
+	}
+	if r < lo || r >= hi {
+		return reflect.Value{}, fmt.Errorf("%w: %g does not fit %d bits scaled by %g", ErrOverflow, x, s.bits, s.factor)
+	}
+	if s.signed {
+		return s.wire(uint64(int64(r))), nil
+	}
+	return s.wire(uint64(r)), nil
+}
+
+// intToWire returns the wire value of x, an already scaled integer.
+func (s scaled) intToWire(x int64) (reflect.Value, error) {
+	fits := x >= 0 && (s.bits == 64 || x < 1<<s.bits)
+	if s.signed {
+		fits = s.bits == 64 || -1<<(s.bits-1) <= x && x < 1<<(s.bits-1)
+	}
+	if !fits {
+		return reflect.Value{}, fmt.Errorf("%w: %d does not fit %d bits", ErrOverflow, x, s.bits)
+	}
+	return s.wire(uint64(x)), nil
+}
+
+// wire returns the low bits of x as a value of the wire type.
+func (s scaled) wire(x uint64) reflect.Value {
+	wire := reflect.New(s.wireType()).Elem()
+	wire.SetUint(x & (1<<s.bits - 1))
+	return wire
+}
+
+// fromWire is the inverse of toWire.
+func (s scaled) fromWire(wire reflect.Value) float64 {
+	if !s.signed {
+		return float64(wire.Uint()) / s.factor
+	}
+	return float64(s.intFromWire(wire)) / s.factor
+}
+
+// intFromWire is the inverse of intToWire.
+func (s scaled) intFromWire(wire reflect.Value) int64 {
+	if !s.signed {
+		return int64(wire.Uint())
+	}
+	shift := 64 - s.bits
+	return int64(wire.Uint()<<shift) >> shift
+}
+
+// isScaled reports whether v is a float with a `fixed` or `scale` tag.
+func isScaled(v reflect.Value, tags fieldTags) bool {
+	k := v.Kind()
+	return (k == reflect.Float32 || k == reflect.Float64) && (tags.fixed != "" || tags.scale != "")
+}
+
+// scaledToWire returns the wire value of a float with a `fixed` or `scale`
+// tag.
+func scaledToWire(v reflect.Value, tags fieldTags) (reflect.Value, error) {
+	s, err := parseScaled(tags)
+	if err != nil {
+		return v, err
+	}
+	wire, err := s.toWire(v.Float())
+	if err != nil {
+		return v, err
+	}
+	return wire, nil
+}
+
+// decodeScaled decodes a float with a `fixed` or `scale` tag.
+func (dec *Decoder) decodeScaled(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	s, err := parseScaled(tags)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	wire := reflect.New(s.wireType()).Elem()
+	if err := dec.decode(wire, bytesLen, fieldTags{}, path); err != nil {
+		return err
+	}
+	v.SetFloat(s.fromWire(wire))
+	return nil
+}
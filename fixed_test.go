@@ -0,0 +1,83 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFixedScaleTags(t *testing.T) {
+	type frame struct {
+		Temp    float64    `fixed:"Q8.8"`
+		Level   float32    `fixed:"UQ0.8"`
+		Voltage float64    `scale:"100 u16"`
+		Offset  float64    `scale:"1000"`
+		Samples [2]float32 `scale:"10 i8"`
+	}
+	in := frame{Temp: -1.5, Level: 0.5, Voltage: 12.34, Offset: -0.25, Samples: [2]float32{-1.2, 3}}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x80, 0xfe,
+		0x80,
+		0xd2, 0x04,
+		0x06, 0xff, 0xff, 0xff,
+		0xf4, 0x1e,
+	})
+
+	var out frame
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	err := binencoder.NewEncoder(buf).Encode(struct {
+		V float64 `fixed:"Q8.8"`
+	}{V: 128}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestDecimalScale(t *testing.T) {
+	type payment struct {
+		Amount binencoder.Decimal `scale:"100 i64"`
+		Fee    binencoder.Decimal `scale:"1000 u16"`
+		Raw    binencoder.Decimal
+	}
+	in := payment{
+		Amount: binencoder.Decimal{Coefficient: -1250, Exponent: -3},
+		Fee:    binencoder.Decimal{Coefficient: 3, Exponent: 0},
+		Raw:    binencoder.Decimal{Coefficient: 7, Exponent: -1},
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x83, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xb8, 0x0b,
+		7, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff,
+	})
+
+	var out payment
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Amount.String() != "-1.25" || out.Fee.String() != "3.000" || out.Raw != in.Raw {
+		t.Errorf("got %s %s %s", out.Amount, out.Fee, out.Raw)
+	}
+
+	err := binencoder.NewEncoder(buf).Encode(struct {
+		V binencoder.Decimal `scale:"100"`
+	}{binencoder.Decimal{Coefficient: 1001, Exponent: -3}}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
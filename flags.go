@@ -0,0 +1,82 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// parseFlagsTag parses the value of a `flags` tag on a field of type t: the
+// width in bits, 8, 16, 32 or 64, of the bitmask the bool field starts.
+func parseFlagsTag(tag string, t reflect.Type) (int, error) {
+	if t.Kind() != reflect.Bool {
+		return 0, fmt.Errorf("%w: flags tag on %s", ErrUnknownType, t)
+	}
+	switch tag {
+	case "8", "16", "32", "64":
+		n, _ := strconv.Atoi(tag)
+		return n, nil
+	}
+	return 0, fmt.Errorf("binencoder: invalid flags tag %q", tag)
+}
+
+// groupFlags makes each bool field with a `flags` tag in plan, a plan of
+// struct type t, the head of a bitmask. The bool fields right after it,
+// without a `flags` tag of their own, take the following bits, the first
+// field taking the lowest.
+func (c *config) groupFlags(t reflect.Type, plan []fieldPlan) {
+	for i := range plan {
+		f := &plan[i]
+		tag := c.tag(t.Field(f.index), "flags")
+		if tag == "" || f.err != nil {
+			continue
+		}
+		if f.flagWidth, f.err = parseFlagsTag(tag, t.Field(f.index).Type); f.err != nil {
+			continue
+		}
+		f.flagBits = []int{f.index}
+		for j := i + 1; j < len(plan) && len(f.flagBits) < f.flagWidth; j++ {
+			g := &plan[j]
+			field := t.Field(g.index)
+			if field.Type.Kind() != reflect.Bool || c.tag(field, "flags") != "" || g.len == -1 || g.err != nil {
+				break
+			}
+			g.flagged = true
+			f.flagBits = append(f.flagBits, g.index)
+		}
+	}
+}
+
+// flagsWire describes the unsigned integer the bitmask of f is written as.
+func (f *fieldPlan) flagsWire() scaled {
+	return scaled{factor: 1, bits: uint(f.flagWidth)}
+}
+
+// encodeFlags encodes the bitmask started by field f of struct v.
+func (enc *Encoder) encodeFlags(v reflect.Value, f *fieldPlan, path int) error {
+	var mask uint64
+	for bit, index := range f.flagBits {
+		if v.Field(index).Bool() {
+			mask |= 1 << uint(bit)
+		}
+	}
+	if f.order != nil {
+		defer enc.useOrder(f.order)()
+	}
+	return enc.encode(f.flagsWire().wire(mask), 0, fieldTags{}, path)
+}
+
+// decodeFlags is the inverse of Encoder.encodeFlags.
+func (dec *Decoder) decodeFlags(v reflect.Value, f *fieldPlan, path string) error {
+	if f.order != nil {
+		defer dec.useOrder(f.order)()
+	}
+	wire := reflect.New(f.flagsWire().wireType()).Elem()
+	if err := dec.decode(wire, 0, fieldTags{}, path); err != nil {
+		return err
+	}
+	for bit, index := range f.flagBits {
+		v.Field(index).SetBool(wire.Uint()&(1<<uint(bit)) != 0)
+	}
+	return nil
+}
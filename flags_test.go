@@ -0,0 +1,40 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFlagsTag(t *testing.T) {
+	type status struct {
+		Ready   bool `flags:"8"`
+		Busy    bool
+		Fault   bool
+		Code    uint8
+		Enabled bool `flags:"16"`
+		Locked  bool
+	}
+	in := status{Ready: true, Fault: true, Code: 7, Locked: true}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x05, 7, 0x00, 0x02})
+
+	var out status
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	if err := binencoder.NewEncoder(buf).Encode(struct {
+		N uint8 `flags:"8"`
+	}{}, 0); err == nil {
+		t.Error("expected an error for flags on a non-bool field")
+	}
+}
@@ -0,0 +1,212 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Format selects the wire format an Encoder writes.
+type Format int
+
+const (
+	// FormatRaw is the fixed layout described by the field tags.
+	FormatRaw Format = iota
+	// FormatDER is a subset of ASN.1 DER: integers are INTEGER, booleans
+	// BOOLEAN, strings and byte sequences OCTET STRING, and structs, slices
+	// and arrays SEQUENCE. A `der:"N"` tag gives a field the implicit
+	// context-specific tag [N].
+	FormatDER
+	// FormatProtobuf is the protocol buffers wire format. Fields tagged
+	// with a field number, `pbf:"1"`, are written as varints, fixed32 or
+	// fixed64 values or length-delimited bytes; `pbf:"1,zigzag"` selects
+	// the sint encoding and `pbf:"1,fixed"` the fixed-size one. Other
+	// fields and zero values are omitted and slices of scalars are packed.
+	FormatProtobuf
+	// FormatMsgpack is MessagePack. Structs are written as maps keyed by
+	// field name, maps as maps sorted by the encoding of the keys and byte
+	// slices and arrays as binary.
+	FormatMsgpack
+	// FormatCBOR is CBOR (RFC 8949). Structs are written as maps keyed by
+	// field name, maps as maps sorted by the encoding of the keys and byte
+	// slices and arrays as byte strings.
+	FormatCBOR
+)
+
+var formatNames = map[Format]string{
+	FormatRaw:      "raw",
+	FormatDER:      "DER",
+	FormatProtobuf: "protobuf",
+	FormatMsgpack:  "MessagePack",
+	FormatCBOR:     "CBOR",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return "format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// WithFormat selects the format values are encoded in, FormatRaw by
+// default. The other formats are encode-only: they follow the struct
+// fields and the `len:"-"`, `unit`, `timefmt`, `durfmt`, `ip` and `uuid`
+// tags but not the layout tags such as `len` and `endian`, and nil
+// pointer fields are omitted. A Decoder with a format other than
+// FormatRaw fails.
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = f
+	}
+}
+
+// encodeFormat encodes v in a format other than FormatRaw.
+func (enc *Encoder) encodeFormat(v reflect.Value) error {
+	var b []byte
+	var err error
+	switch enc.format {
+	case FormatDER:
+		b, err = enc.appendDER(enc.scratch[:0], v, fieldTags{}, "")
+	case FormatProtobuf:
+		b, err = enc.appendProto(enc.scratch[:0], v, "")
+	case FormatMsgpack:
+		b, err = enc.appendMsgpack(enc.scratch[:0], v, fieldTags{}, "")
+	case FormatCBOR:
+		b, err = enc.appendCBOR(enc.scratch[:0], v, fieldTags{}, "")
+	default:
+		return fmt.Errorf("binencoder: unknown format %s", enc.format)
+	}
+	if err != nil {
+		return err
+	}
+	enc.scratch = b
+	if err := enc.write(b); err != nil {
+		return newEncodeError("", reflect.TypeOf(b), err)
+	}
+	return nil
+}
+
+// formatValue resolves v for a format backend: pointers and interfaces are
+// followed, a nil one giving the zero Value, types with a wire
+// representation are converted to it and values with custom encodings are
+// replaced by the bytes they encode to.
+func (enc *Encoder) formatValue(v reflect.Value, tags fieldTags) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return v, nil
+	}
+	if c, ok := lookupCodec(v.Type()); ok && c.enc != nil {
+		b, err := c.enc(v.Interface(), enc.byteOrder)
+		return reflect.ValueOf(b), err
+	}
+	v, err := toWire(v, tags)
+	if err != nil {
+		return v, err
+	}
+	if m, ok := binMarshaler(v); ok {
+		var buf bytes.Buffer
+		err := m.EncodeBin(&buf, enc.byteOrder)
+		return reflect.ValueOf(buf.Bytes()), err
+	}
+	if m, ok := marshaler(v); ok {
+		b, err := m.MarshalBinary()
+		return reflect.ValueOf(b), err
+	}
+	return v, nil
+}
+
+// formatField is a struct field as seen by a format backend.
+type formatField struct {
+	plan  *fieldPlan
+	field reflect.StructField
+	value reflect.Value
+	path  string
+}
+
+// formatFields returns the fields of struct v a format backend encodes,
+// with units converted.
+func (enc *Encoder) formatFields(v reflect.Value, path string) ([]formatField, error) {
+	plan := enc.structPlan(v.Type())
+	fields := make([]formatField, 0, len(plan))
+	for i := range plan {
+		f := &plan[i]
+		ff := formatField{
+			plan:  f,
+			field: v.Type().Field(f.index),
+			value: v.Field(f.index),
+			path:  joinPath(path, f.name),
+		}
+		if err := enc.fieldErr(f); err != nil {
+			return nil, newEncodeError(ff.path, ff.field.Type, err)
+		}
+		if f.len == -1 || !enc.present(f) {
+			continue
+		}
+		if f.ratio != 0 {
+			var err error
+			if ff.value, err = convertUnit(ff.value, f.ratio); err != nil {
+				return nil, newEncodeError(ff.path, ff.field.Type, err)
+			}
+		}
+		fields = append(fields, ff)
+	}
+	return fields, nil
+}
+
+// isBytes reports whether v is a string or a slice or array of bytes,
+// which the formats write as byte strings.
+func isBytes(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice, reflect.Array:
+		return v.Type().Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// bytesOf returns the contents of a value isBytes accepts.
+func bytesOf(v reflect.Value) []byte {
+	switch {
+	case v.Kind() == reflect.String:
+		return []byte(v.String())
+	case v.Kind() == reflect.Slice:
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// formatEntry is a map entry as encoded by a format backend.
+type formatEntry struct {
+	key, value []byte
+}
+
+// formatEntries encodes the keys and values of map v with appendItem,
+// sorted by the encoding of the keys so that the output is deterministic.
+func formatEntries(v reflect.Value, tags fieldTags, path string, appendItem func([]byte, reflect.Value, fieldTags, string) ([]byte, error)) ([]formatEntry, error) {
+	entries := make([]formatEntry, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		key, err := appendItem(nil, iter.Key(), tags, path)
+		if err != nil {
+			return nil, err
+		}
+		value, err := appendItem(nil, iter.Value(), tags, path+"["+fmt.Sprint(iter.Key().Interface())+"]")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, formatEntry{key, value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	return entries, nil
+}
@@ -0,0 +1,143 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// FrameWriter is implemented by writers that delimit frames, such as
+// COBSWriter. An Encoder writing to a FrameWriter ends the frame after each
+// successful Encode call and aborts it after a failed one, so every frame
+// holds exactly one value.
+type FrameWriter interface {
+	io.Writer
+	// EndFrame writes the data written since the last frame as a frame.
+	EndFrame() error
+	// AbortFrame discards the data written since the last frame.
+	AbortFrame()
+}
+
+// FramedEncoder writes every value as a frame: a length header followed by
+// the encoding of the value.
+type FramedEncoder struct {
+	w      io.Writer
+	header string
+	order  binary.ByteOrder
+	enc    *Encoder
+	buf    bytes.Buffer
+}
+
+// NewFramedEncoder returns a FramedEncoder writing to w. The header is an
+// unsigned integer named like the `prefix` tag values ("u8", "u16", "u32"
+// or "u64") in the given byte order; it does not count itself. The values
+// are encoded with opts.
+func NewFramedEncoder(w io.Writer, header string, order binary.ByteOrder, opts ...Option) *FramedEncoder {
+	fe := &FramedEncoder{w: w, header: header, order: order}
+	fe.enc = NewEncoder(&fe.buf, opts...)
+	return fe
+}
+
+// Encode encodes data like Encoder.Encode and writes it as one frame with
+// a single call to the underlying writer.
+func (fe *FramedEncoder) Encode(data interface{}, bytesLen int) error {
+	width, err := prefixWidth(fe.header)
+	if err != nil {
+		return err
+	}
+	fe.buf.Reset()
+	fe.buf.Write(make([]byte, width))
+	n, err := fe.enc.EncodeN(data, bytesLen)
+	if err != nil {
+		return err
+	}
+	p, err := encodePrefix(n, fe.header, fe.order)
+	if err != nil {
+		return err
+	}
+	b := fe.buf.Bytes()
+	copy(b, p)
+	_, err = fe.w.Write(b)
+	return err
+}
+
+// FramedDecoder reads the frames written by FramedEncoder.
+type FramedDecoder struct {
+	r      io.Reader
+	header string
+	order  binary.ByteOrder
+	dec    *Decoder
+	buf    bytes.Buffer
+	frame  bytes.Reader
+}
+
+// NewFramedDecoder returns a FramedDecoder reading from r, with the header
+// and options of the matching NewFramedEncoder.
+func NewFramedDecoder(r io.Reader, header string, order binary.ByteOrder, opts ...Option) *FramedDecoder {
+	fd := &FramedDecoder{r: r, header: header, order: order}
+	fd.dec = NewDecoder(&fd.frame, opts...)
+	return fd
+}
+
+// Decode reads exactly one frame and decodes it into the value pointed to
+// by data like Decoder.Decode. The whole frame must be consumed.
+//
+// Decode returns io.EOF if the input ends before the header and an error
+// matching ErrShortMessage if it ends within the frame.
+func (fd *FramedDecoder) Decode(data interface{}, bytesLen int) error {
+	width, err := prefixWidth(fd.header)
+	if err != nil {
+		return err
+	}
+	p := make([]byte, width)
+	if _, err := io.ReadFull(fd.r, p); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return ErrShortMessage
+		}
+		return err
+	}
+	n := decodePrefix(p, fd.order)
+	if max := fd.dec.maxMessageSize; max > 0 && n > max {
+		return fmt.Errorf("%w: frame of %d bytes exceeds the limit of %d", ErrMessageTooLarge, n, max)
+	}
+	fd.buf.Reset()
+	// Copying grows the buffer with the data actually read rather than
+	// trusting the header with the allocation.
+	copied, err := io.CopyN(&fd.buf, fd.r, int64(n))
+	if err == io.EOF {
+		return fmt.Errorf("%w: frame has %d of %d bytes", ErrShortMessage, copied, n)
+	}
+	if err != nil {
+		return err
+	}
+	fd.frame.Reset(fd.buf.Bytes())
+	err = fd.dec.Decode(data, bytesLen)
+	if err == io.EOF {
+		return ErrShortMessage
+	}
+	if err == nil && fd.frame.Len() != 0 {
+		return fmt.Errorf("binencoder: %d bytes left after decoding %T", fd.frame.Len(), data)
+	}
+	return err
+}
+
+// frameReader serves the frames returned by next as one stream, so that a
+// Decoder can read from a framing reader directly.
+type frameReader struct {
+	next    func() ([]byte, error)
+	pending []byte
+}
+
+func (fr *frameReader) Read(p []byte) (int, error) {
+	for len(fr.pending) == 0 {
+		frame, err := fr.next()
+		if err != nil {
+			return 0, err
+		}
+		fr.pending = frame
+	}
+	n := copy(p, fr.pending)
+	fr.pending = fr.pending[n:]
+	return n, nil
+}
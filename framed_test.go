@@ -0,0 +1,58 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFramed(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string `len:"4"`
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewFramedEncoder(buf, "u16", binary.BigEndian, binencoder.WithByteOrder(binary.LittleEndian))
+	for _, m := range []message{{1, "a"}, {2, "b"}} {
+		if err := enc.Encode(m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	equalByte(t, buf.Bytes(), []byte{0, 6, 1, 0, 'a', 0, 0, 0, 0, 6, 2, 0, 'b', 0, 0, 0})
+
+	dec := binencoder.NewFramedDecoder(buf, "u16", binary.BigEndian, binencoder.WithByteOrder(binary.LittleEndian))
+	for _, want := range []message{{1, "a"}, {2, "b"}} {
+		var out message
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != want {
+			t.Errorf("We have:\n%+v\n got:\n%+v\n", want, out)
+		}
+	}
+	if err := dec.Decode(&message{}, 0); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	dec = binencoder.NewFramedDecoder(bytes.NewReader([]byte{7, 0, 0, 0, 1, 0}), "u32", binary.LittleEndian)
+	if err := dec.Decode(&message{}, 0); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+	dec = binencoder.NewFramedDecoder(bytes.NewReader([]byte{7, 1, 0, 'a', 0, 0, 0, 9}), "u8", binary.LittleEndian)
+	if err := dec.Decode(&message{}, 0); err == nil {
+		t.Error("expected an error for a frame with trailing bytes")
+	}
+	dec = binencoder.NewFramedDecoder(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0x7f}), "u32", binary.LittleEndian, binencoder.WithMaxMessageSize(64))
+	if err := dec.Decode(&message{}, 0); !errors.Is(err, binencoder.ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	err := binencoder.NewFramedEncoder(buf, "u8", binary.LittleEndian).Encode(make([]byte, 300), 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package binencoder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// HDLC-like framing bytes (RFC 1662).
+const (
+	hdlcFlag = 0x7e
+	hdlcEsc  = 0x7d
+	hdlcXor  = 0x20
+)
+
+// HDLCWriter writes frames in HDLC-like framing (RFC 1662): the data is
+// followed by its FCS, a CRC-16/X.25 in little-endian order, flag and
+// escape bytes are escaped and every frame is enclosed in flag bytes. Data
+// is buffered until EndFrame.
+type HDLCWriter struct {
+	w   io.Writer
+	buf []byte
+	out []byte
+}
+
+// NewHDLCWriter returns an HDLCWriter writing to w. An Encoder writing to
+// it emits every value as a frame.
+func NewHDLCWriter(w io.Writer) *HDLCWriter {
+	return &HDLCWriter{w: w}
+}
+
+func (hw *HDLCWriter) Write(p []byte) (int, error) {
+	hw.buf = append(hw.buf, p...)
+	return len(p), nil
+}
+
+// EndFrame writes the buffered data as a frame.
+func (hw *HDLCWriter) EndFrame() error {
+	fcs := fcs16(hw.buf)
+	hw.buf = append(hw.buf, byte(fcs), byte(fcs>>8))
+	out := append(hw.out[:0], hdlcFlag)
+	for _, c := range hw.buf {
+		if c == hdlcFlag || c == hdlcEsc {
+			out = append(out, hdlcEsc, c^hdlcXor)
+			continue
+		}
+		out = append(out, c)
+	}
+	hw.out = append(out, hdlcFlag)
+	hw.buf = hw.buf[:0]
+	_, err := hw.w.Write(hw.out)
+	return err
+}
+
+// AbortFrame discards the buffered data.
+func (hw *HDLCWriter) AbortFrame() {
+	hw.buf = hw.buf[:0]
+}
+
+// HDLCReader reads HDLC-like frames and validates their FCS. Its Read
+// method serves the frame contents as one stream for a Decoder.
+type HDLCReader struct {
+	r *bufio.Reader
+	frameReader
+}
+
+// NewHDLCReader returns an HDLCReader reading from r.
+func NewHDLCReader(r io.Reader) *HDLCReader {
+	hr := &HDLCReader{r: bufio.NewReader(r)}
+	hr.next = hr.ReadFrame
+	return hr
+}
+
+// ReadFrame reads the next non-empty frame and returns its contents without
+// the FCS. It returns io.EOF at the end of the input, an error matching
+// ErrShortMessage if the input ends within a frame, one matching
+// ErrBadFrame for an invalid escape or a frame shorter than the FCS and one
+// matching ErrBadChecksum if the FCS does not match.
+func (hr *HDLCReader) ReadFrame() ([]byte, error) {
+	for {
+		b, err := hr.r.ReadBytes(hdlcFlag)
+		if err == io.EOF && len(b) != 0 {
+			return nil, ErrShortMessage
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 1 {
+			return hdlcDecode(b[:len(b)-1])
+		}
+	}
+}
+
+// hdlcDecode unescapes b in place and checks and strips the FCS.
+func hdlcDecode(b []byte) ([]byte, error) {
+	out := b[:0]
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == hdlcEsc {
+			i++
+			if i == len(b) {
+				return nil, fmt.Errorf("%w: HDLC escape at the end of the frame", ErrBadFrame)
+			}
+			c = b[i] ^ hdlcXor
+		}
+		out = append(out, c)
+	}
+	if len(out) < 2 {
+		return nil, fmt.Errorf("%w: HDLC frame of %d bytes has no FCS", ErrBadFrame, len(out))
+	}
+	data := out[:len(out)-2]
+	want := uint16(out[len(out)-2]) | uint16(out[len(out)-1])<<8
+	if got := fcs16(data); got != want {
+		return nil, fmt.Errorf("%w: FCS %#04x, computed %#04x", ErrBadChecksum, want, got)
+	}
+	return data, nil
+}
+
+// fcs16 returns the CRC-16/X.25 of b, the FCS of RFC 1662.
+func fcs16(b []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, c := range b {
+		crc ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
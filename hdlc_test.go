@@ -0,0 +1,64 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestHDLC(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := binencoder.NewHDLCWriter(buf)
+	w.Write([]byte("123456789"))
+	if err := w.EndFrame(); err != nil {
+		t.Fatal(err)
+	}
+	// 0x906e is the CRC-16/X.25 check value.
+	equalByte(t, buf.Bytes(), []byte{0x7e, '1', '2', '3', '4', '5', '6', '7', '8', '9', 0x6e, 0x90, 0x7e})
+
+	type message struct {
+		ID    uint16
+		Flags uint8
+	}
+	buf.Reset()
+	enc := binencoder.NewEncoder(binencoder.NewHDLCWriter(buf))
+	for _, m := range []message{{0x7d7e, 1}, {2, 3}} {
+		if err := enc.Encode(m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0x7e, 0x7d, 0x5e, 0x7d, 0x5d, 1}) {
+		t.Errorf("flag and escape bytes not stuffed: % x", buf.Bytes())
+	}
+
+	dec := binencoder.NewDecoder(binencoder.NewHDLCReader(bytes.NewReader(buf.Bytes())))
+	for _, want := range []message{{0x7d7e, 1}, {2, 3}} {
+		var out message
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != want {
+			t.Errorf("We have:\n%+v\n got:\n%+v\n", want, out)
+		}
+	}
+	if err := dec.Decode(&message{}, 0); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	corrupt := append([]byte(nil), buf.Bytes()...)
+	corrupt[len(corrupt)-5] ^= 1
+	r := binencoder.NewHDLCReader(bytes.NewReader(corrupt))
+	if _, err := r.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadFrame(); !errors.Is(err, binencoder.ErrBadChecksum) {
+		t.Errorf("expected ErrBadChecksum, got %v", err)
+	}
+	_, err := binencoder.NewHDLCReader(bytes.NewReader([]byte{0x7e, 1, 0x7e})).ReadFrame()
+	if !errors.Is(err, binencoder.ErrBadFrame) {
+		t.Errorf("expected ErrBadFrame, got %v", err)
+	}
+}
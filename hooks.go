@@ -0,0 +1,42 @@
+package binencoder
+
+import "reflect"
+
+// BeforeEncoder is implemented by types that prepare their value before it
+// is encoded, e.g. to fill in lengths, checksums or timestamps. An error
+// stops encoding. A method with a pointer receiver is only called on
+// addressable values, such as values reached through a pointer.
+type BeforeEncoder interface {
+	BeforeEncode() error
+}
+
+// AfterDecoder is implemented by types that finish or check their value
+// once it is decoded. An error stops decoding.
+type AfterDecoder interface {
+	AfterDecode() error
+}
+
+var (
+	beforeEncoderType = reflect.TypeOf((*BeforeEncoder)(nil)).Elem()
+	afterDecoderType  = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+)
+
+// beforeEncode calls the BeforeEncode method of v, if any. Pointers are
+// left to the value they point to.
+func beforeEncode(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr || !implements(v, beforeEncoderType) {
+		return nil
+	}
+	if h, ok := v.Interface().(BeforeEncoder); ok {
+		return h.BeforeEncode()
+	}
+	return v.Addr().Interface().(BeforeEncoder).BeforeEncode()
+}
+
+// afterDecode calls the AfterDecode method of v, if any.
+func afterDecode(v reflect.Value) error {
+	if h, ok := v.Addr().Interface().(AfterDecoder); ok {
+		return h.AfterDecode()
+	}
+	return nil
+}
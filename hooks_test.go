@@ -0,0 +1,55 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+var errBadLength = errors.New("length does not match the payload")
+
+type hookedFrame struct {
+	Length  uint8
+	Payload [3]byte
+	Sum     uint8
+}
+
+func (f *hookedFrame) BeforeEncode() error {
+	f.Length = uint8(len(f.Payload))
+	f.Sum = 0
+	for _, b := range f.Payload {
+		f.Sum += b
+	}
+	return nil
+}
+
+func (f *hookedFrame) AfterDecode() error {
+	if int(f.Length) != len(f.Payload) {
+		return errBadLength
+	}
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	in := &hookedFrame{Payload: [3]byte{1, 2, 3}}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{3, 1, 2, 3, 6})
+
+	var out hookedFrame
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != *in {
+		t.Errorf("got %+v, want %+v", out, *in)
+	}
+
+	err := binencoder.NewDecoder(bytes.NewReader([]byte{2, 1, 2, 3, 6})).Decode(&out, 0)
+	if !errors.Is(err, errBadLength) {
+		t.Errorf("expected the AfterDecode error, got %v", err)
+	}
+}
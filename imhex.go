@@ -0,0 +1,77 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ExportImHex returns an ImHex pattern describing the layout the struct v
+// is encoded with by an Encoder with opts, so that captured messages can be
+// opened in the hex editor with decoded field names and values. The pattern
+// places v at offset 0. Like ExportKaitai, slices and strings without a
+// `len` tag are described with the length they have in v.
+func ExportImHex(v interface{}, opts ...Option) (string, error) {
+	rv, ok := structValue(v)
+	if !ok {
+		return "", newEncodeError("", reflect.TypeOf(v), fmt.Errorf("%w: ExportImHex needs a struct", ErrUnknownType))
+	}
+	w := newLayoutWalker("ImHex", opts)
+	t, err := w.structType(rv, 0, "message", "")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by binencoder.ExportImHex.\n\n")
+	endian := "little"
+	if w.intOrder() == binary.BigEndian {
+		endian = "big"
+	}
+	b.WriteString("#pragma endian " + endian + "\n")
+	for _, t := range w.types {
+		b.WriteString("\nstruct " + t.name + " {\n")
+		for _, attr := range t.attrs {
+			writeImHexField(&b, attr, w.intOrder())
+		}
+		b.WriteString("};\n")
+	}
+	b.WriteString("\n" + t.name + " message @ 0x00;\n")
+	return b.String(), nil
+}
+
+func writeImHexField(b *strings.Builder, attr layoutAttr, meta binary.ByteOrder) {
+	var typ string
+	n := -1
+	switch attr.kind {
+	case layoutInt:
+		typ = "u"
+		if attr.signed {
+			typ = "s"
+		}
+		typ += strconv.Itoa(attr.size * 8)
+		if attr.size > 1 && attr.order != meta {
+			typ = ksyEndian(attr.order) + " " + typ
+		}
+	case layoutBytes:
+		typ, n = "u8", attr.size
+	case layoutString:
+		typ, n = "char", attr.size
+	case layoutStruct:
+		typ = attr.typ.name
+	}
+	if attr.repeated {
+		n = attr.count
+	}
+	if n == 0 {
+		b.WriteString("\t// " + attr.id + ": empty\n")
+		return
+	}
+	b.WriteString("\t" + typ + " " + attr.id)
+	if n > 0 {
+		b.WriteString("[" + strconv.Itoa(n) + "]")
+	}
+	b.WriteString(";\n")
+}
@@ -0,0 +1,51 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestExportImHex(t *testing.T) {
+	got, err := binencoder.ExportImHex(ksyMessage{}, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, got, `// Generated by binencoder.ExportImHex.
+
+#pragma endian big
+
+struct ksy_header {
+	u8 version;
+	u16 flags;
+};
+
+struct ksy_message {
+	ksy_header header;
+	u32 in_id;
+	char name[8];
+	u8 padded_pad[2];
+	s16 padded;
+	// values: empty
+	u8 raw[4];
+	s64 time;
+};
+
+ksy_message message @ 0x00;
+`)
+
+	got, err = binencoder.ExportImHex(&ksyHeader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "\tbe u16 flags;\n") {
+		t.Errorf("big-endian field not marked:\n%s", got)
+	}
+
+	if _, err := binencoder.ExportImHex([]int{}); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldInfo describes the struct field an Interceptor is called for.
+type FieldInfo struct {
+	// Path is the path of the field in the message, e.g. "Points[0].X".
+	Path  string
+	Field reflect.StructField
+	Value interface{}
+}
+
+// EncodeFn encodes v, a value of the field's type, in place of the field.
+type EncodeFn func(v interface{}) error
+
+// Interceptor is called for each struct field encoded. It encodes the
+// field by calling next, with the field's value or a replacement, or
+// leaves it out of the message by not calling it.
+type Interceptor func(info FieldInfo, next EncodeFn) error
+
+// Use adds interceptors for the fields the Encoder encodes, e.g. for
+// metrics, tracing or redaction of sensitive fields. The first interceptor
+// added is called first; the fields of nested structs are intercepted too.
+func (enc *Encoder) Use(interceptors ...Interceptor) {
+	enc.interceptors = append(enc.interceptors, interceptors...)
+}
+
+// interceptField encodes field f of struct v through the interceptors.
+func (enc *Encoder) interceptField(v reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	field := v.Type().Field(f.index)
+	info := FieldInfo{Path: string(enc.pathBuf[:path]), Field: field, Value: v.Field(f.index).Interface()}
+	var chain func(i int, x interface{}) error
+	chain = func(i int, x interface{}) error {
+		if i < len(enc.interceptors) {
+			info := info
+			info.Value = x
+			return enc.interceptors[i](info, func(x interface{}) error { return chain(i+1, x) })
+		}
+		rv := reflect.ValueOf(x)
+		if !rv.IsValid() || rv.Type() != field.Type {
+			return enc.fail(path, field.Type, fmt.Errorf("binencoder: interceptor passed %T for %s", x, field.Type))
+		}
+		// The field is encoded from a copy of the struct, as fields such as
+		// `sizeof` ones read their neighbours.
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		s.Field(f.index).Set(rv)
+		return enc.encodeField(s, f, bytesLen, path)
+	}
+	return chain(0, info.Value)
+}
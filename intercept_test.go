@@ -0,0 +1,50 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestInterceptors(t *testing.T) {
+	type inner struct {
+		PIN string `len:"4" sensitive:"true"`
+	}
+	type message struct {
+		ID   uint8
+		Card inner
+	}
+	var visited []string
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	enc.Use(func(info binencoder.FieldInfo, next binencoder.EncodeFn) error {
+		visited = append(visited, info.Path)
+		return next(info.Value)
+	}, func(info binencoder.FieldInfo, next binencoder.EncodeFn) error {
+		if info.Field.Tag.Get("sensitive") == "true" {
+			return next(strings.Repeat("*", len(info.Value.(string))))
+		}
+		return next(info.Value)
+	})
+	in := message{ID: 7, Card: inner{PIN: "1234"}}
+	if err := enc.Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte("\x07****"))
+	if got := strings.Join(visited, " "); got != "ID Card Card.PIN" {
+		t.Errorf("visited %s", got)
+	}
+	if in.Card.PIN != "1234" {
+		t.Error("the interceptor changed the message")
+	}
+
+	enc = binencoder.NewEncoder(buf)
+	enc.Use(func(info binencoder.FieldInfo, next binencoder.EncodeFn) error {
+		return next(int64(1))
+	})
+	if err := enc.Encode(in, 0); err == nil {
+		t.Error("expected an error for a replacement of the wrong type")
+	}
+}
@@ -0,0 +1,92 @@
+package gentest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+// plain has the layout of Message without the generated methods, so it is
+// encoded by reflection.
+type plain Message
+
+func sample() Message {
+	return Message{
+		ID:      1,
+		Flags:   0x0203,
+		Ok:      true,
+		Kind:    4,
+		Temp:    -5,
+		Name:    "node",
+		Tail:    "xyz",
+		Big:     0x0102030405060708,
+		Samples: [3]int16{-1, 0, 1},
+		Points:  []Point{{1, -1}, {2, -2}},
+		Wide:    [2]uint16{0x0a0b, 0x0c0d},
+		Skip:    "skipped",
+	}
+}
+
+func TestGeneratedMatchesReflection(t *testing.T) {
+	in := sample()
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		want, err := binencoder.Marshal(plain(in), order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := new(bytes.Buffer)
+		if err := in.EncodeBin(got, order); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want) {
+			t.Errorf("%v: we have:\n[% x]\n got:\n[% x]\n", order, want, got.Bytes())
+		}
+
+		out := Message{Tail: "...", Points: make([]Point, 2)}
+		if err := binencoder.Unmarshal(want, &out, order); err != nil {
+			t.Fatal(err)
+		}
+		expected := in
+		expected.Skip = ""
+		if !reflect.DeepEqual(out, expected) {
+			t.Errorf("%v: we have:\n%+v\n got:\n%+v\n", order, expected, out)
+		}
+	}
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	in := sample()
+	in.Name = "too long name"
+	if err := in.EncodeBin(new(bytes.Buffer), binary.LittleEndian); !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("got %v", err)
+	}
+
+	var out Message
+	err := binencoder.Unmarshal([]byte{1, 2, 3}, &out, binary.LittleEndian)
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestGeneratedOptions(t *testing.T) {
+	in := sample()
+	for _, opts := range [][]binencoder.Option{
+		{binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)},
+		{binencoder.WithPadByte(' ')},
+	} {
+		if _, err := binencoder.Marshal(in, binary.BigEndian, opts...); !errors.Is(err, binencoder.ErrUnknownType) {
+			t.Errorf("Marshal: got %v", err)
+		}
+		var out Message
+		if err := binencoder.Unmarshal(make([]byte, 64), &out, binary.BigEndian, opts...); !errors.Is(err, binencoder.ErrUnknownType) {
+			t.Errorf("Unmarshal: got %v", err)
+		}
+		if err := binencoder.CheckType(reflect.TypeOf(in), opts...); !errors.Is(err, binencoder.ErrUnknownType) {
+			t.Errorf("CheckType: got %v", err)
+		}
+	}
+}
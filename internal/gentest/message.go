@@ -0,0 +1,26 @@
+// Package gentest holds types with methods generated by binencoder-gen.
+package gentest
+
+//go:generate go run ../../cmd/binencoder-gen -type Message
+
+type Celsius int16
+
+type Point struct {
+	X, Y int32
+}
+
+type Message struct {
+	ID      uint32
+	Flags   uint16 `len:"4"`
+	Ok      bool
+	Kind    uint8
+	Temp    Celsius
+	Name    string `bin:"len=8"`
+	Tail    string
+	Big     uint64 `bin:"endian=be"`
+	Samples [3]int16
+	Points  []Point
+	Wide    [2]uint16 `len:"3"`
+	Skip    string    `len:"-"`
+	hidden  uint8
+}
@@ -0,0 +1,154 @@
+// Code generated by binencoder-gen; DO NOT EDIT.
+
+package gentest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/milQA/binencoder"
+)
+
+// EncodeBin implements binencoder.Marshaler.
+func (m Message) EncodeBin(w io.Writer, order binary.ByteOrder) error {
+	var b []byte
+	b = append(b, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[len(b)-4:], m.ID)
+	if order == binary.LittleEndian {
+		b = append(b, 0, 0)
+		binary.LittleEndian.PutUint16(b[len(b)-2:], m.Flags)
+		b = append(b, make([]byte, 2)...)
+	} else {
+		b = append(b, make([]byte, 2)...)
+		b = append(b, 0, 0)
+		binary.LittleEndian.PutUint16(b[len(b)-2:], m.Flags)
+	}
+	if m.Ok {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = append(b, m.Kind)
+	b = append(b, 0, 0)
+	binary.LittleEndian.PutUint16(b[len(b)-2:], uint16(m.Temp))
+	if len(m.Name) > 8 {
+		return fmt.Errorf("%w: Name", binencoder.ErrFieldTooLong)
+	}
+	if order == binary.LittleEndian {
+		b = append(b, m.Name...)
+		b = append(b, make([]byte, 8-len(m.Name))...)
+	} else {
+		b = append(b, make([]byte, 8-len(m.Name))...)
+		b = append(b, m.Name...)
+	}
+	b = append(b, m.Tail...)
+	b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(b[len(b)-8:], m.Big)
+	for i0 := range m.Samples {
+		b = append(b, 0, 0)
+		binary.LittleEndian.PutUint16(b[len(b)-2:], uint16(m.Samples[i0]))
+	}
+	for i0 := range m.Points {
+		b = append(b, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b[len(b)-4:], uint32(m.Points[i0].X))
+		b = append(b, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b[len(b)-4:], uint32(m.Points[i0].Y))
+	}
+	for i0 := range m.Wide {
+		if order == binary.LittleEndian {
+			b = append(b, 0, 0)
+			binary.LittleEndian.PutUint16(b[len(b)-2:], m.Wide[i0])
+			b = append(b, make([]byte, 1)...)
+		} else {
+			b = append(b, make([]byte, 1)...)
+			b = append(b, 0, 0)
+			binary.LittleEndian.PutUint16(b[len(b)-2:], m.Wide[i0])
+		}
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// DecodeBin implements binencoder.Unmarshaler.
+func (m *Message) DecodeBin(r io.Reader, order binary.ByteOrder) error {
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(r, buf[:4]); err != nil {
+		return err
+	}
+	m.ID = binary.LittleEndian.Uint32(buf[:4])
+	if _, err := io.ReadFull(r, buf[:4]); err != nil {
+		return err
+	}
+	{
+		p := buf[:2]
+		if order != binary.LittleEndian {
+			p = buf[2:4]
+		}
+		m.Flags = binary.LittleEndian.Uint16(p)
+	}
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return err
+	}
+	m.Ok = buf[0] != 0
+	if _, err := io.ReadFull(r, buf[:1]); err != nil {
+		return err
+	}
+	m.Kind = buf[0]
+	if _, err := io.ReadFull(r, buf[:2]); err != nil {
+		return err
+	}
+	m.Temp = Celsius(int16(binary.LittleEndian.Uint16(buf[:2])))
+	if _, err := io.ReadFull(r, buf[:8]); err != nil {
+		return err
+	}
+	if order == binary.LittleEndian {
+		m.Name = string(bytes.TrimRight(buf[:8], "\x00"))
+	} else {
+		m.Name = string(bytes.TrimLeft(buf[:8], "\x00"))
+	}
+	{
+		p := make([]byte, len(m.Tail))
+		if _, err := io.ReadFull(r, p); err != nil {
+			return err
+		}
+		m.Tail = string(p)
+	}
+	if _, err := io.ReadFull(r, buf[:8]); err != nil {
+		return err
+	}
+	m.Big = binary.BigEndian.Uint64(buf[:8])
+	for i0 := range m.Samples {
+		if _, err := io.ReadFull(r, buf[:2]); err != nil {
+			return err
+		}
+		m.Samples[i0] = int16(binary.LittleEndian.Uint16(buf[:2]))
+	}
+	for i0 := range m.Points {
+		if _, err := io.ReadFull(r, buf[:4]); err != nil {
+			return err
+		}
+		m.Points[i0].X = int32(binary.LittleEndian.Uint32(buf[:4]))
+		if _, err := io.ReadFull(r, buf[:4]); err != nil {
+			return err
+		}
+		m.Points[i0].Y = int32(binary.LittleEndian.Uint32(buf[:4]))
+	}
+	for i0 := range m.Wide {
+		if _, err := io.ReadFull(r, buf[:3]); err != nil {
+			return err
+		}
+		{
+			p := buf[:2]
+			if order != binary.LittleEndian {
+				p = buf[1:3]
+			}
+			m.Wide[i0] = binary.LittleEndian.Uint16(p)
+		}
+	}
+	return nil
+}
+
+// BinencoderGenerated implements binencoder.Generated.
+func (Message) BinencoderGenerated() {}
@@ -0,0 +1,84 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"unicode/utf16"
+)
+
+// appendJavaUTF8 appends s in the modified UTF-8 of Java's
+// DataOutput.writeUTF: UTF-16 units encoded like UTF-8, with NUL written
+// as two bytes and supplementary characters as two 3-byte surrogates.
+func appendJavaUTF8(b []byte, s string) []byte {
+	for _, u := range utf16.Encode([]rune(s)) {
+		switch {
+		case u != 0 && u < 0x80:
+			b = append(b, byte(u))
+		case u < 0x800:
+			b = append(b, 0xc0|byte(u>>6), 0x80|byte(u&0x3f))
+		default:
+			b = append(b, 0xe0|byte(u>>12), 0x80|byte(u>>6&0x3f), 0x80|byte(u&0x3f))
+		}
+	}
+	return b
+}
+
+// javaUTF8String is the inverse of appendJavaUTF8.
+func javaUTF8String(b []byte) (string, error) {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b) && b[i+1]&0xc0 == 0x80:
+			units = append(units, uint16(c&0x1f)<<6|uint16(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b) && b[i+1]&0xc0 == 0x80 && b[i+2]&0xc0 == 0x80:
+			units = append(units, uint16(c&0x0f)<<12|uint16(b[i+1]&0x3f)<<6|uint16(b[i+2]&0x3f))
+			i += 3
+		default:
+			return "", fmt.Errorf("%w: malformed modified UTF-8 at byte %d", ErrInvalidValue, i)
+		}
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// encodeJavaUTF encodes a string with `strenc:"jutf8"`: its length in
+// bytes as a big-endian uint16 followed by its modified UTF-8.
+func (enc *Encoder) encodeJavaUTF(v reflect.Value, path int) error {
+	b := appendJavaUTF8(make([]byte, 2, 2+len(v.String())), v.String())
+	if len(b)-2 > math.MaxUint16 {
+		return enc.fail(path, v.Type(), fmt.Errorf("%w: %d bytes of modified UTF-8 exceed 65535", ErrFieldTooLong, len(b)-2))
+	}
+	binary.BigEndian.PutUint16(b, uint16(len(b)-2))
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeJavaUTF is the inverse of Encoder.encodeJavaUTF.
+func (dec *Decoder) decodeJavaUTF(v reflect.Value, path string) error {
+	var n [2]byte
+	if err := dec.readFull(n[:]); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	size := int(binary.BigEndian.Uint16(n[:]))
+	if max := dec.maxStringLen; max > 0 && size > max {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: string of %d bytes exceeds the limit of %d", ErrLimitExceeded, size, max))
+	}
+	b := make([]byte, size)
+	if err := dec.readFull(b); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	s, err := javaUTF8String(b)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	v.SetString(s)
+	return nil
+}
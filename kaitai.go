@@ -0,0 +1,85 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ExportKaitai returns a Kaitai Struct description (.ksy) of the layout the
+// struct v is encoded with by an Encoder with opts, so that documentation
+// and reverse-engineering tools stay in sync with the Go types. Slices and
+// strings without a `len` tag are described with the length they have in
+// v. Values with custom encodings, io.Reader fields and TLV and KLV fields
+// cannot be described.
+func ExportKaitai(v interface{}, opts ...Option) (string, error) {
+	rv, ok := structValue(v)
+	if !ok {
+		return "", newEncodeError("", reflect.TypeOf(v), fmt.Errorf("%w: ExportKaitai needs a struct", ErrUnknownType))
+	}
+	w := newLayoutWalker("Kaitai", opts)
+	seq, err := w.structAttrs(rv, 0, "")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("meta:\n")
+	b.WriteString("  id: " + snakeCase(rv.Type().Name(), "message") + "\n")
+	b.WriteString("  endian: " + ksyEndian(w.intOrder()) + "\n")
+	writeKsySeq(&b, "", seq, w.intOrder())
+	if len(w.types) != 0 {
+		b.WriteString("types:\n")
+		for _, t := range w.types {
+			b.WriteString("  " + t.name + ":\n")
+			writeKsySeq(&b, "    ", t.attrs, w.intOrder())
+		}
+	}
+	return b.String(), nil
+}
+
+func writeKsySeq(b *strings.Builder, indent string, seq []layoutAttr, meta binary.ByteOrder) {
+	b.WriteString(indent + "seq:\n")
+	for _, attr := range seq {
+		key := func(k, v string) {
+			b.WriteString(indent + "    " + k + ": " + v + "\n")
+		}
+		b.WriteString(indent + "  - id: " + attr.id + "\n")
+		switch attr.kind {
+		case layoutInt:
+			typ := "u"
+			if attr.signed {
+				typ = "s"
+			}
+			typ += strconv.Itoa(attr.size)
+			if attr.size > 1 && attr.order != meta {
+				typ += ksyEndian(attr.order)
+			}
+			key("type", typ)
+		case layoutBytes:
+			key("size", strconv.Itoa(attr.size))
+		case layoutString:
+			key("type", "str")
+			key("size", strconv.Itoa(attr.size))
+			key("encoding", "ASCII")
+			if attr.padded {
+				key("pad-right", strconv.Itoa(int(attr.pad)))
+			}
+		case layoutStruct:
+			key("type", attr.typ.name)
+		}
+		if attr.repeated {
+			key("repeat", "expr")
+			key("repeat-expr", strconv.Itoa(attr.count))
+		}
+	}
+}
+
+func ksyEndian(order binary.ByteOrder) string {
+	if order == binary.BigEndian {
+		return "be"
+	}
+	return "le"
+}
@@ -0,0 +1,78 @@
+package binencoder_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+type ksyHeader struct {
+	Version uint8
+	Flags   uint16 `endian:"be"`
+}
+
+type ksyMessage struct {
+	Header  ksyHeader
+	InID    uint32
+	Name    string `len:"8"`
+	Padded  int16  `len:"4"`
+	Values  []uint16
+	Raw     [4]byte
+	Skipped uint8 `len:"-"`
+	Time    time.Time
+}
+
+func TestExportKaitai(t *testing.T) {
+	got, err := binencoder.ExportKaitai(ksyMessage{Values: make([]uint16, 3)}, binencoder.WithPadByte(' '))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, got, `meta:
+  id: ksy_message
+  endian: le
+seq:
+  - id: header
+    type: ksy_header
+  - id: in_id
+    type: u4
+  - id: name
+    type: str
+    size: 8
+    encoding: ASCII
+    pad-right: 32
+  - id: padded
+    type: s2
+  - id: padded_pad
+    size: 2
+  - id: values
+    type: u2
+    repeat: expr
+    repeat-expr: 3
+  - id: raw
+    size: 4
+  - id: time
+    type: s8
+types:
+  ksy_header:
+    seq:
+      - id: version
+        type: u1
+      - id: flags
+        type: u2be
+`)
+
+	got, err = binencoder.ExportKaitai(&ksyHeader{}, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, got, "meta:\n  id: ksy_header\n  endian: be\nseq:\n  - id: version\n    type: u1\n  - id: flags\n    type: u2\n")
+
+	_, err = binencoder.ExportKaitai(struct{ R io.Reader }{})
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
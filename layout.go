@@ -0,0 +1,242 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// layoutKind classifies the attributes of a layout description.
+type layoutKind int
+
+const (
+	layoutInt layoutKind = iota
+	layoutBytes
+	layoutString
+	layoutStruct
+)
+
+// layoutAttr is a run of bytes in the layout of a struct, as described to
+// schema exporters.
+type layoutAttr struct {
+	id   string
+	kind layoutKind
+	// size is the width of an integer or the length of bytes and strings.
+	size   int
+	signed bool
+	order  binary.ByteOrder
+	// repeated attributes describe count array elements.
+	repeated bool
+	count    int
+	typ      *layoutType
+	// padded strings are padded on the right with pad.
+	padded bool
+	pad    byte
+}
+
+// layoutType is a described struct type.
+type layoutType struct {
+	name  string
+	attrs []layoutAttr
+}
+
+type layoutKey struct {
+	t        reflect.Type
+	order    binary.ByteOrder
+	ints     bool
+	bytesLen int
+}
+
+// layoutWalker describes the layout of struct types as encoded with its
+// config. The struct types it meets are collected in types, each after the
+// types it uses.
+type layoutWalker struct {
+	config
+	exporter string
+	types    []*layoutType
+	names    map[layoutKey]*layoutType
+	used     map[string]bool
+}
+
+func newLayoutWalker(exporter string, opts []Option) *layoutWalker {
+	w := &layoutWalker{exporter: exporter, names: map[layoutKey]*layoutType{}, used: map[string]bool{}}
+	w.config.init(opts)
+	return w
+}
+
+// structAttrs returns the attributes of the fields of struct v encoded with
+// length bytesLen.
+func (w *layoutWalker) structAttrs(v reflect.Value, bytesLen int, path string) ([]layoutAttr, error) {
+	var attrs []layoutAttr
+	plan := w.structPlan(v.Type())
+	for i := range plan {
+		f := &plan[i]
+		field := v.Field(f.index)
+		fieldPath := joinPath(path, f.name)
+		if err := w.fieldErr(f); err != nil {
+			return nil, newEncodeError(fieldPath, field.Type(), err)
+		}
+		switch {
+		case f.len == -1 || !w.present(f):
+			continue
+		case f.sizeFrom >= 0 || f.tlv >= 0 || f.klv != nil || f.sizedEmbed() || f.offset >= 0 || f.flagBits != nil || f.flagged || f.lenFrom >= 0 || f.until != nil || w.presence && f.nullable:
+			return nil, newEncodeError(fieldPath, field.Type(), fmt.Errorf("%w: field cannot be described in %s", ErrUnknownType, w.exporter))
+		}
+		restore := func() {}
+		if f.order != nil {
+			restore = w.useOrder(f.order)
+		}
+		a, err := w.attrs(field, f.fieldLen(bytesLen), f.tags, snakeCase(f.name, "field"), fieldPath)
+		restore()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, a...)
+	}
+	return attrs, nil
+}
+
+// attrs returns the attributes describing v encoded with length bytesLen:
+// the value and, if it is padded, the padding.
+func (w *layoutWalker) attrs(v reflect.Value, bytesLen int, tags fieldTags, id, path string) ([]layoutAttr, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+		return w.attrs(v, bytesLen, tags, id, path)
+	}
+	unsupported := func() error {
+		return newEncodeError(path, v.Type(), fmt.Errorf("%w: %s cannot be described in %s", ErrUnknownType, v.Type(), w.exporter))
+	}
+	if _, ok := lookupCodec(v.Type()); ok || v.Type() == readerType || tags.dns != "" || isVarint(v, tags) || isAsText(v.Type(), tags) {
+		return nil, unsupported()
+	}
+	wire, err := toWire(v, tags)
+	if err != nil {
+		return nil, newEncodeError(path, v.Type(), err)
+	}
+	if wire.Type() == v.Type() && customEncoding(v.Type()) {
+		return nil, unsupported()
+	}
+	v = wire
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if v.Type() == rawBytesType {
+			return []layoutAttr{{id: id, kind: layoutBytes, size: v.Len()}}, nil
+		}
+		if isByteBlob(v.Type(), bytesLen, tags) {
+			if tags.prefix != "" {
+				return nil, unsupported()
+			}
+			size := v.Len()
+			if bytesLen != 0 {
+				size = bytesLen
+			}
+			return []layoutAttr{{id: id, kind: layoutBytes, size: size}}, nil
+		}
+		elem := reflect.Zero(v.Type().Elem())
+		if v.Len() != 0 {
+			elem = v.Index(0)
+		}
+		if elem.Kind() == reflect.Uint8 && bytesLen <= 1 && !customEncoding(elem.Type()) {
+			return []layoutAttr{{id: id, kind: layoutBytes, size: v.Len()}}, nil
+		}
+		item, err := w.attrs(elem, bytesLen, tags, id, path+"[0]")
+		if err != nil {
+			return nil, err
+		}
+		if len(item) != 1 {
+			return nil, newEncodeError(path, v.Type(), fmt.Errorf("%w: padded elements cannot be described in %s", ErrUnknownType, w.exporter))
+		}
+		item[0].repeated, item[0].count = true, v.Len()
+		return item, nil
+	case reflect.Struct:
+		t, err := w.structType(v, bytesLen, id, path)
+		if err != nil {
+			return nil, err
+		}
+		return []layoutAttr{{id: id, kind: layoutStruct, typ: t}}, nil
+	case reflect.String:
+		attr := layoutAttr{id: id, kind: layoutString, size: v.Len()}
+		if bytesLen != 0 {
+			attr.size = bytesLen
+			attr.padded = w.byteOrder == binary.LittleEndian
+			attr.pad = w.padByte
+		}
+		return []layoutAttr{attr}, nil
+	}
+	size, ok := baseTypeSize(v)
+	if !ok {
+		return nil, unsupported()
+	}
+	attrs := []layoutAttr{{id: id, kind: layoutInt, size: size, signed: isInt(v.Kind()), order: w.intOrder()}}
+	if bytesLen == 0 || bytesLen == size {
+		return attrs, nil
+	}
+	if bytesLen < size {
+		return nil, newEncodeError(path, v.Type(), ErrFieldTooLong)
+	}
+	pad := layoutAttr{id: id + "_pad", kind: layoutBytes, size: bytesLen - size}
+	if w.byteOrder == binary.LittleEndian {
+		return append(attrs, pad), nil
+	}
+	return append([]layoutAttr{pad}, attrs...), nil
+}
+
+// structType returns the description of struct v, adding it to the types
+// on first use.
+func (w *layoutWalker) structType(v reflect.Value, bytesLen int, id, path string) (*layoutType, error) {
+	if order := structByteOrder(v); order != nil {
+		defer w.useOrder(order)()
+	}
+	key := layoutKey{t: v.Type(), order: w.byteOrder, ints: w.orderedInts, bytesLen: bytesLen}
+	if t, ok := w.names[key]; ok {
+		return t, nil
+	}
+	base := snakeCase(v.Type().Name(), id+"_t")
+	name := base
+	for n := 2; w.used[name]; n++ {
+		name = base + "_" + strconv.Itoa(n)
+	}
+	w.used[name] = true
+	t := &layoutType{name: name}
+	w.names[key] = t
+	attrs, err := w.structAttrs(v, bytesLen, path)
+	if err != nil {
+		return nil, err
+	}
+	t.attrs = attrs
+	w.types = append(w.types, t)
+	return t, nil
+}
+
+// structValue returns the struct v points to, if any.
+func structValue(v interface{}) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv, rv.Kind() == reflect.Struct
+}
+
+// snakeCase converts a Go identifier to lower snake case, e.g. "InID" to
+// "in_id", returning def for an empty name.
+func snakeCase(name, def string) string {
+	if name == "" {
+		return def
+	}
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) && i > 0 && (unicode.IsLower(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1]) && unicode.IsUpper(r[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// defaultMapPrefix is the width of the entry count of maps without a
+// `prefix` tag.
+const defaultMapPrefix = "u32"
+
+// encodeMap encodes a map as its entry count, sized by the `prefix` tag,
+// followed by the keys and values, sorted by the encoding of the keys so
+// that the output is deterministic. bytesLen applies to keys and values.
+// Entries with a key or value of an unsupported type, logged outside
+// strict mode, are left out and not counted.
+func (enc *Encoder) encodeMap(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	prefix := tags.prefix
+	if prefix == "" {
+		prefix = defaultMapPrefix
+	}
+	tags.prefix = ""
+
+	type entry struct {
+		key, value []byte
+	}
+	entries := make([]entry, 0, v.Len())
+	w, n, trace, layout := enc.w, enc.n, enc.trace, enc.layout
+	enc.trace, enc.layout = nil, nil
+	var err error
+	for iter := v.MapRange(); iter.Next() && err == nil; {
+		var key, value bytes.Buffer
+		skipped := enc.skipped
+		enc.w = &key
+		if err = enc.encode(iter.Key(), bytesLen, tags, path); err != nil {
+			break
+		}
+		enc.w = &value
+		err = enc.encode(iter.Value(), bytesLen, tags, enc.keyPath(path, iter.Key()))
+		if enc.skipped == skipped {
+			entries = append(entries, entry{key.Bytes(), value.Bytes()})
+		}
+	}
+	enc.w, enc.n, enc.trace, enc.layout = w, n, trace, layout
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	p, err := encodePrefix(len(entries), prefix, enc.byteOrder)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if err := enc.write(p); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	for _, e := range entries {
+		if err := enc.write(e.key); err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+		if err := enc.write(e.value); err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+	}
+	return nil
+}
+
+// keyPath appends a map key to the path ending at parent.
+func (enc *Encoder) keyPath(parent int, key reflect.Value) int {
+	enc.pathBuf = append(enc.pathBuf[:parent], '[')
+	enc.pathBuf = append(enc.pathBuf, fmt.Sprint(key.Interface())...)
+	enc.pathBuf = append(enc.pathBuf, ']')
+	return len(enc.pathBuf)
+}
+
+// decodeMap is the inverse of Encoder.encodeMap. Keys and values are read
+// like fields of their types, so strings among them need a `len` tag.
+func (dec *Decoder) decodeMap(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	prefix := tags.prefix
+	if prefix == "" {
+		prefix = defaultMapPrefix
+	}
+	tags.prefix = ""
+	width, err := prefixWidth(prefix)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	p := make([]byte, width)
+	if err := dec.readFull(p); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	count := decodePrefix(p, dec.byteOrder)
+	if err := dec.checkLen(count, reflect.Map); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if count == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	m := reflect.MakeMapWithSize(v.Type(), count)
+	for i := 0; i < count; i++ {
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := dec.decode(key, bytesLen, tags, path); err != nil {
+			return err
+		}
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := dec.decode(value, bytesLen, tags, fmt.Sprintf("%s[%v]", path, key.Interface())); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	v.Set(m)
+	return nil
+}
@@ -0,0 +1,78 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestMap(t *testing.T) {
+	type message struct {
+		Counts map[uint16]uint8  `prefix:"u8"`
+		Names  map[string]string `len:"3"`
+	}
+	in := message{
+		Counts: map[uint16]uint8{0x0201: 1, 0x0102: 2, 0x0300: 3},
+		Names:  map[string]string{"b": "x", "a": "yz"},
+	}
+	b, err := binencoder.Marshal(in, binary.BigEndian, binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{
+		3, 0x01, 0x02, 2, 0x02, 0x01, 1, 0x03, 0x00, 3,
+		0, 0, 0, 2, 0, 0, 'a', 0, 'y', 'z', 0, 0, 'b', 0, 0, 'x',
+	})
+
+	var out message
+	if err := binencoder.Unmarshal(b, &out, binary.BigEndian, binencoder.WithIntByteOrder(true)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	// Encoding is deterministic regardless of map iteration order.
+	for i := 0; i < 10; i++ {
+		again, err := binencoder.Marshal(in, binary.BigEndian, binencoder.WithIntByteOrder(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, b) {
+			t.Fatal("encoding changed between runs")
+		}
+	}
+
+	overflow := message{Counts: make(map[uint16]uint8)}
+	for i := 0; i < 256; i++ {
+		overflow.Counts[uint16(i)] = 0
+	}
+	if _, err := binencoder.Marshal(overflow, binary.BigEndian, binencoder.WithIntByteOrder(true)); !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0xff}), binencoder.WithMaxSliceLen(16)).Decode(&message{}, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestMapUnsupported(t *testing.T) {
+	in := map[uint8]int{1: 1, 2: 2}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf)
+	enc.SetLogger(binencoder.LoggerFunc(func(string, ...interface{}) {}))
+	if err := enc.Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0, 0, 0, 0})
+
+	enc = binencoder.NewEncoder(new(bytes.Buffer), binencoder.WithStrict(true))
+	if err := enc.Encode(in, 0); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Marshaler is implemented by types with a custom wire layout. EncodeBin
+// receives the byte order the Encoder is configured with. The `len` and
+// `prefix` tags apply to the bytes it writes.
+type Marshaler interface {
+	EncodeBin(w io.Writer, order binary.ByteOrder) error
+}
+
+// Unmarshaler is the decoding counterpart of Marshaler. Unless the field has
+// a `len` or `prefix` tag, DecodeBin reads from the stream directly and must
+// consume exactly its own encoding.
+type Unmarshaler interface {
+	DecodeBin(r io.Reader, order binary.ByteOrder) error
+}
+
+// Generated is implemented by the types binencoder-gen writes EncodeBin
+// and DecodeBin methods for. Those methods write numbers little-endian and
+// pad with zero bytes, as an Encoder does by default, and cannot see the
+// options of the Encoder calling them. An Encoder or Decoder whose numbers
+// are not little-endian, see WithIntByteOrder, or whose pad byte is not
+// zero fails on such types with an error matching ErrUnknownType instead.
+type Generated interface {
+	BinencoderGenerated()
+}
+
+var errUnknownLength = errors.New("binencoder: length is unknown, set a prefix or len tag")
+
+var (
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	generatedType       = reflect.TypeOf((*Generated)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+// implements reports whether v or, if v is addressable, its address
+// implements iface. Checking the type first keeps plain values from being
+// boxed into interfaces.
+func implements(v reflect.Value, iface reflect.Type) bool {
+	if v.CanInterface() && v.Type().Implements(iface) {
+		return true
+	}
+	return v.CanAddr() && reflect.PtrTo(v.Type()).Implements(iface)
+}
+
+func binMarshaler(v reflect.Value) (Marshaler, bool) {
+	if !implements(v, marshalerType) {
+		return nil, false
+	}
+	if m, ok := v.Interface().(Marshaler); ok {
+		return m, true
+	}
+	m, ok := v.Addr().Interface().(Marshaler)
+	return m, ok
+}
+
+func binUnmarshaler(v reflect.Value) (Unmarshaler, bool) {
+	if v.Kind() == reflect.Ptr || !v.CanAddr() {
+		return nil, false
+	}
+	u, ok := v.Addr().Interface().(Unmarshaler)
+	return u, ok
+}
+
+// checkGenerated reports values of type t with generated methods that
+// would not lay them out as c does.
+func (c *config) checkGenerated(t reflect.Type) error {
+	if !t.Implements(generatedType) && !reflect.PtrTo(t).Implements(generatedType) {
+		return nil
+	}
+	if c.intOrder() != binary.LittleEndian || c.padByte != 0 {
+		return fmt.Errorf("%w: the generated methods of %s follow neither WithIntByteOrder nor WithPadByte", ErrUnknownType, t)
+	}
+	return nil
+}
+
+func (enc *Encoder) encodeBin(m Marshaler, bytesLen int, tags fieldTags, path int, t reflect.Type) error {
+	if err := enc.checkGenerated(t); err != nil {
+		return enc.fail(path, t, err)
+	}
+	enc.bin.Reset()
+	if err := m.EncodeBin(&enc.bin, enc.byteOrder); err != nil {
+		return enc.fail(path, t, err)
+	}
+	if err := enc.writeBytes(enc.bin.Bytes(), bytesLen, tags.prefix); err != nil {
+		return enc.fail(path, t, err)
+	}
+	return nil
+}
+
+func (dec *Decoder) decodeBin(u Unmarshaler, bytesLen int, tags fieldTags, path string, t reflect.Type) error {
+	if err := dec.checkGenerated(t); err != nil {
+		return newDecodeError(path, t, err)
+	}
+	var r io.Reader = decoderReader{dec}
+	if bytesLen != 0 || tags.prefix != "" {
+		b, err := dec.readBytes(bytesLen, tags.prefix)
+		if err != nil {
+			return newDecodeError(path, t, err)
+		}
+		r = bytes.NewReader(b)
+	}
+	if err := u.DecodeBin(r, dec.byteOrder); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrShortMessage
+		}
+		return newDecodeError(path, t, err)
+	}
+	return nil
+}
+
+// decoderReader reads from the Decoder's stream, counting the bytes read.
+type decoderReader struct {
+	dec *Decoder
+}
+
+func (r decoderReader) Read(p []byte) (int, error) {
+	n, err := r.dec.r.Read(p)
+	r.dec.n += n
+	return n, err
+}
+
+func marshaler(v reflect.Value) (encoding.BinaryMarshaler, bool) {
+	if !implements(v, binaryMarshalerType) {
+		return nil, false
+	}
+	if m, ok := v.Interface().(encoding.BinaryMarshaler); ok {
+		return m, true
+	}
+	m, ok := v.Addr().Interface().(encoding.BinaryMarshaler)
+	return m, ok
+}
+
+func unmarshaler(v reflect.Value) (encoding.BinaryUnmarshaler, bool) {
+	if v.Kind() == reflect.Ptr || !v.CanAddr() {
+		return nil, false
+	}
+	u, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler)
+	return u, ok
+}
+
+func (enc *Encoder) encodeMarshaler(m encoding.BinaryMarshaler, bytesLen int, tags fieldTags, path int, t reflect.Type) error {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return enc.fail(path, t, err)
+	}
+	if err := enc.writeBytes(b, bytesLen, tags.prefix); err != nil {
+		return enc.fail(path, t, err)
+	}
+	return nil
+}
+
+// writeBytes writes b preceded by a length prefix if prefix is set and
+// padded to bytesLen if it is not 0.
+func (enc *Encoder) writeBytes(b []byte, bytesLen int, prefix string) error {
+	if prefix != "" {
+		p, err := encodePrefix(len(b), prefix, enc.byteOrder)
+		if err != nil {
+			return err
+		}
+		if err := enc.write(p); err != nil {
+			return err
+		}
+	}
+	return enc.writePadded(b, bytesLen)
+}
+
+func (dec *Decoder) decodeUnmarshaler(u encoding.BinaryUnmarshaler, bytesLen int, tags fieldTags, path string, t reflect.Type) error {
+	b, err := dec.readBytes(bytesLen, tags.prefix)
+	if err != nil {
+		return newDecodeError(path, t, err)
+	}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return newDecodeError(path, t, err)
+	}
+	return nil
+}
+
+// readBytes is the inverse of writeBytes. Without a prefix the padding is
+// returned along with the data.
+func (dec *Decoder) readBytes(bytesLen int, prefix string) ([]byte, error) {
+	n := bytesLen
+	if prefix != "" {
+		width, err := prefixWidth(prefix)
+		if err != nil {
+			return nil, err
+		}
+		p := make([]byte, width)
+		if err := dec.readFull(p); err != nil {
+			return nil, err
+		}
+		n = decodePrefix(p, dec.byteOrder)
+		if bytesLen != 0 && n > bytesLen {
+			return nil, ErrFieldTooLong
+		}
+		if err := dec.checkLen(n, reflect.Slice); err != nil {
+			return nil, err
+		}
+	} else if bytesLen == 0 {
+		return nil, errUnknownLength
+	}
+	width := n
+	if bytesLen != 0 {
+		width = bytesLen
+	}
+	b := make([]byte, width)
+	if err := dec.readFull(b); err != nil {
+		return nil, err
+	}
+	if dec.byteOrder == binary.LittleEndian {
+		return b[:n], nil
+	}
+	return b[width-n:], nil
+}
+
+func prefixWidth(prefix string) (int, error) {
+	switch prefix {
+	case "u8":
+		return 1, nil
+	case "u16":
+		return 2, nil
+	case "u32":
+		return 4, nil
+	case "u64":
+		return 8, nil
+	default:
+		return 0, fmt.Errorf("binencoder: unknown prefix %q", prefix)
+	}
+}
+
+// encodePrefix encodes a length as an unsigned integer of the width named by
+// prefix, using the same byte layout as integer fields.
+func encodePrefix(n int, prefix string, order binary.ByteOrder) ([]byte, error) {
+	width, err := prefixWidth(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if width < 8 && uint64(n) >= 1<<(8*uint(width)) {
+		return nil, fmt.Errorf("%w: length %d does not fit %s prefix", ErrOverflow, n, prefix)
+	}
+	b := make([]byte, 8)
+	order.PutUint64(b, uint64(n))
+	if order == binary.BigEndian {
+		return b[8-width:], nil
+	}
+	return b[:width], nil
+}
+
+func decodePrefix(b []byte, order binary.ByteOrder) int {
+	wide := make([]byte, 8)
+	if order == binary.BigEndian {
+		copy(wide[8-len(b):], b)
+	} else {
+		copy(wide, b)
+	}
+	return int(order.Uint64(wide))
+}
@@ -0,0 +1,127 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+// point marshals itself as text to show that the reflective layout of the
+// struct is bypassed.
+type point struct {
+	X, Y uint8
+}
+
+func (p point) MarshalBinary() ([]byte, error) {
+	return []byte{'(', '0' + p.X, ',', '0' + p.Y, ')'}, nil
+}
+
+func (p *point) UnmarshalBinary(b []byte) error {
+	b = bytes.TrimRight(b, "\x00")
+	if len(b) != 5 {
+		return errors.New("bad point")
+	}
+	p.X, p.Y = b[1]-'0', b[3]-'0'
+	return nil
+}
+
+type shape struct {
+	Prefixed point   `prefix:"u8"`
+	Fixed    point   `len:"8"`
+	Both     point   `prefix:"u16" len:"6"`
+	Points   []point `prefix:"u8"`
+}
+
+func TestBinaryMarshaler(t *testing.T) {
+	in := shape{
+		Prefixed: point{1, 2},
+		Fixed:    point{3, 4},
+		Both:     point{5, 6},
+		Points:   []point{{7, 8}, {9, 0}},
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, buf.String(), "\x05(1,2)"+"(3,4)\x00\x00\x00"+"\x05\x00(5,6)\x00"+"\x05(7,8)\x05(9,0)")
+
+	out := shape{Points: make([]point, 2)}
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Prefixed != in.Prefixed || out.Fixed != in.Fixed || out.Both != in.Both ||
+		out.Points[0] != in.Points[0] || out.Points[1] != in.Points[1] {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	var bare struct{ P point }
+	err := binencoder.NewDecoder(strings.NewReader("(1,2)"), binencoder.WithByteOrder(binary.LittleEndian)).Decode(&bare, 0)
+	if err == nil {
+		t.Error("expected an error for a marshaler without prefix or len")
+	}
+}
+
+// reading is a sensor value with a custom layout: a one byte channel and a
+// 24-bit value in the configured byte order.
+type reading struct {
+	Channel uint8
+	Value   uint32
+}
+
+func (r reading) EncodeBin(w io.Writer, order binary.ByteOrder) error {
+	b := make([]byte, 4)
+	order.PutUint32(b, r.Value)
+	if order == binary.BigEndian {
+		b = b[1:]
+	} else {
+		b = b[:3]
+	}
+	_, err := w.Write(append([]byte{r.Channel}, b...))
+	return err
+}
+
+func (r *reading) DecodeBin(rd io.Reader, order binary.ByteOrder) error {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(rd, b); err != nil {
+		return err
+	}
+	r.Channel = b[0]
+	if order == binary.BigEndian {
+		r.Value = uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	} else {
+		r.Value = uint32(b[3])<<16 | uint32(b[2])<<8 | uint32(b[1])
+	}
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	type frame struct {
+		A reading
+		B reading `prefix:"u8" len:"6"`
+		C uint8
+	}
+	in := frame{A: reading{1, 0x0a0b0c}, B: reading{2, 0x010203}, C: 9}
+	for order, want := range map[binary.ByteOrder][]byte{
+		binary.LittleEndian: {1, 0x0c, 0x0b, 0x0a, 4, 2, 3, 2, 1, 0, 0, 9},
+		binary.BigEndian:    {1, 0x0a, 0x0b, 0x0c, 4, 0, 0, 2, 1, 2, 3, 9},
+	} {
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(order)).Encode(in, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), want)
+
+		var out frame
+		if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(order)).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("%v: we have:\n%+v\n got:\n%+v\n", order, in, out)
+		}
+	}
+}
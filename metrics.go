@@ -0,0 +1,25 @@
+package binencoder
+
+import (
+	"reflect"
+	"time"
+)
+
+// Metrics receives a measurement for each message handled by an Encoder or
+// a Decoder created with WithMetrics: the type of the message (not of the
+// pointer passed to Decode), the bytes written or read, the time taken and
+// the error, if any. The io.EOF a Decoder returns at the end of its input
+// is not reported. Implementations must be safe for concurrent use if
+// shared.
+type Metrics interface {
+	ObserveEncode(t reflect.Type, n int, d time.Duration, err error)
+	ObserveDecode(t reflect.Type, n int, d time.Duration, err error)
+}
+
+// WithMetrics reports each Encode and Decode call to m. Package
+// binencmetrics has an implementation exposing them to Prometheus.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) {
+		c.metrics = m
+	}
+}
@@ -0,0 +1,148 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MigrateFunc converts the fields of a message from one version to the
+// next. Fields are keyed by Go field name.
+type MigrateFunc func(old map[string]interface{}) map[string]interface{}
+
+// Migrations decodes messages written by older versions of a protocol
+// straight into the current struct type. Each old version registers the
+// struct type it was written with, and migration steps lead from every old
+// version to the current one.
+type Migrations struct {
+	current int
+	layouts map[int]reflect.Type
+	steps   map[int]migrationStep
+}
+
+type migrationStep struct {
+	to int
+	fn MigrateFunc
+}
+
+// NewMigrations returns Migrations decoding into the struct type of
+// version currentVer, with no layouts or steps registered.
+func NewMigrations(currentVer int) *Migrations {
+	return &Migrations{
+		current: currentVer,
+		layouts: map[int]reflect.Type{},
+		steps:   map[int]migrationStep{},
+	}
+}
+
+// Layout registers the struct type messages of version ver were encoded with.
+func (m *Migrations) Layout(ver int, proto interface{}) {
+	t := reflect.TypeOf(proto)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m.layouts[ver] = t
+}
+
+// Migrate registers fn as the step from fromVer to toVer.
+func (m *Migrations) Migrate(fromVer, toVer int, fn MigrateFunc) {
+	m.steps[fromVer] = migrationStep{to: toVer, fn: fn}
+}
+
+// Decode reads a message written with version ver into data, which must
+// point to a struct of the current version. Old messages are decoded with
+// their registered layout, migrated step by step and then copied into data
+// by field name.
+func (m *Migrations) Decode(dec *Decoder, data interface{}, bytesLen int, ver int) error {
+	if ver == m.current {
+		return dec.Decode(data, bytesLen)
+	}
+	old, err := m.decodeOld(dec, bytesLen, ver)
+	if err != nil {
+		return err
+	}
+	fields, err := m.upgrade(old, ver)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binencoder: migration target must be a pointer to a struct, got %T", data)
+	}
+	return mapToStruct(fields, v.Elem())
+}
+
+func (m *Migrations) decodeOld(dec *Decoder, bytesLen int, ver int) (map[string]interface{}, error) {
+	layout, ok := m.layouts[ver]
+	if !ok {
+		return nil, fmt.Errorf("binencoder: no layout registered for version %d", ver)
+	}
+	old := reflect.New(layout)
+	if err := dec.Decode(old.Interface(), bytesLen); err != nil {
+		return nil, err
+	}
+	return structToMap(old.Elem()), nil
+}
+
+func (m *Migrations) upgrade(fields map[string]interface{}, ver int) (map[string]interface{}, error) {
+	for seen := map[int]bool{}; ver != m.current; {
+		step, ok := m.steps[ver]
+		if !ok || seen[ver] {
+			return nil, fmt.Errorf("binencoder: no migration path from version %d to %d", ver, m.current)
+		}
+		seen[ver] = true
+		fields = step.fn(fields)
+		ver = step.to
+	}
+	return fields, nil
+}
+
+func structToMap(v reflect.Value) map[string]interface{} {
+	fields := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); f.PkgPath == "" {
+			fields[f.Name] = v.Field(i).Interface()
+		}
+	}
+	return fields
+}
+
+// mapToStruct sets the fields of v from fields by name. Values must be
+// assignable to the field type or convertible to it as a number to a
+// number or a value to one of the same kind, so that an integer never
+// becomes a string; missing fields are zeroed.
+func mapToStruct(fields map[string]interface{}, v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		val, ok := fields[f.Name]
+		if !ok || val == nil {
+			v.Field(i).Set(reflect.Zero(f.Type))
+			continue
+		}
+		rv := reflect.ValueOf(val)
+		switch {
+		case rv.Type().AssignableTo(f.Type):
+			v.Field(i).Set(rv)
+		case migratable(rv.Type(), f.Type):
+			v.Field(i).Set(rv.Convert(f.Type))
+		default:
+			return fmt.Errorf("binencoder: cannot use %s as %s in field %s", rv.Type(), f.Type, f.Name)
+		}
+	}
+	return nil
+}
+
+// migratable reports whether mapToStruct converts values of type from to
+// type to.
+func migratable(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	return from.Kind() == to.Kind() || isNumber(from.Kind()) && isNumber(to.Kind())
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,76 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type recordV1 struct {
+	ID   uint16
+	Temp int16
+}
+
+type recordV2 struct {
+	ID    uint32
+	TempC int16
+}
+
+type recordV3 struct {
+	ID    uint32
+	TempC int16
+	Unit  string `len:"1"`
+}
+
+func TestMigrations(t *testing.T) {
+	m := binencoder.NewMigrations(3)
+	m.Layout(1, recordV1{})
+	m.Layout(2, recordV2{})
+	m.Migrate(1, 2, func(old map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"ID": old["ID"], "TempC": old["Temp"]}
+	})
+	m.Migrate(2, 3, func(old map[string]interface{}) map[string]interface{} {
+		old["Unit"] = "C"
+		return old
+	})
+
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	enc.Encode(recordV1{ID: 7, Temp: -5}, 0)
+	enc.Encode(recordV2{ID: 8, TempC: 21}, 0)
+	enc.Encode(recordV3{ID: 9, TempC: 30, Unit: "F"}, 0)
+
+	dec := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	want := []recordV3{{7, -5, "C"}, {8, 21, "C"}, {9, 30, "F"}}
+	for i, ver := range []int{1, 2, 3} {
+		var got recordV3
+		if err := m.Decode(dec, &got, 0, ver); err != nil {
+			t.Fatal(err)
+		}
+		if got != want[i] {
+			t.Errorf("version %d: we have:\n%+v\n got:\n%+v\n", ver, want[i], got)
+		}
+	}
+
+	if err := m.Decode(dec, &recordV3{}, 0, 4); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
+
+func TestMigrationsConvert(t *testing.T) {
+	m := binencoder.NewMigrations(3)
+	m.Layout(2, recordV2{})
+	m.Migrate(2, 3, func(old map[string]interface{}) map[string]interface{} {
+		old["Unit"] = 65
+		return old
+	})
+
+	buf := new(bytes.Buffer)
+	binencoder.NewEncoder(buf).Encode(recordV2{ID: 8, TempC: 21}, 0)
+	var got recordV3
+	if err := m.Decode(binencoder.NewDecoder(buf), &got, 0, 2); err == nil {
+		t.Errorf("expected an error converting an int to a string, got %+v", got)
+	}
+}
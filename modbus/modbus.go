@@ -0,0 +1,130 @@
+// Package modbus frames Modbus messages for the binencoder package: RTU
+// frames with their CRC-16 for serial lines and MBAP headers for Modbus
+// TCP. The request and response types are the PDUs of the common
+// functions; their fields are big-endian whatever the byte order of the
+// Encoder, as Modbus requires.
+//
+//	enc := binencoder.NewEncoder(modbus.NewTCPWriter(conn, 1))
+//	err := enc.Encode(modbus.ReadRequest{Function: modbus.ReadHoldingRegisters, Address: 0x10, Quantity: 2}, 0)
+//	...
+//	_, pdu, err := modbus.NewTCPReader(conn).ReadFrame()
+//	var resp modbus.ReadResponse
+//	err = binencoder.Unmarshal(pdu, &resp, binary.BigEndian)
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Function codes.
+const (
+	ReadCoils              = 0x01
+	ReadDiscreteInputs     = 0x02
+	ReadHoldingRegisters   = 0x03
+	ReadInputRegisters     = 0x04
+	WriteSingleCoil        = 0x05
+	WriteSingleRegister    = 0x06
+	WriteMultipleCoils     = 0x0f
+	WriteMultipleRegisters = 0x10
+)
+
+// exceptionFlag is set in the function code of an exception response.
+const exceptionFlag = 0x80
+
+// Bytes is the data of a PDU. With a `prefix:"u8"` tag it is written after
+// its byte count.
+type Bytes []byte
+
+// EncodeBin writes b.
+func (b Bytes) EncodeBin(w io.Writer, order binary.ByteOrder) error {
+	_, err := w.Write(b)
+	return err
+}
+
+// DecodeBin reads b to the end of r.
+func (b *Bytes) DecodeBin(r io.Reader, order binary.ByteOrder) error {
+	data, err := ioutil.ReadAll(r)
+	*b = data
+	return err
+}
+
+// ReadRequest reads Quantity coils, inputs or registers from Address.
+type ReadRequest struct {
+	Function uint8
+	Address  uint16 `endian:"be"`
+	Quantity uint16 `endian:"be"`
+}
+
+// ReadResponse holds the bytes read by a ReadRequest: packed bits for coils
+// and discrete inputs, big-endian registers otherwise.
+type ReadResponse struct {
+	Function uint8
+	Data     Bytes `prefix:"u8"`
+}
+
+// Registers returns the registers in the data of r.
+func (r ReadResponse) Registers() []uint16 {
+	regs := make([]uint16, len(r.Data)/2)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(r.Data[2*i:])
+	}
+	return regs
+}
+
+// WriteSingleRequest writes Value to the coil or register at Address. The
+// response echoes the request.
+type WriteSingleRequest struct {
+	Function uint8
+	Address  uint16 `endian:"be"`
+	Value    uint16 `endian:"be"`
+}
+
+// WriteMultipleRequest writes Quantity coils or registers from Address with
+// the bytes in Data.
+type WriteMultipleRequest struct {
+	Function uint8
+	Address  uint16 `endian:"be"`
+	Quantity uint16 `endian:"be"`
+	Data     Bytes  `prefix:"u8"`
+}
+
+// WriteRegisters returns the request writing values to the holding
+// registers from address.
+func WriteRegisters(address uint16, values []uint16) WriteMultipleRequest {
+	data := make(Bytes, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(data[2*i:], v)
+	}
+	return WriteMultipleRequest{
+		Function: WriteMultipleRegisters,
+		Address:  address,
+		Quantity: uint16(len(values)),
+		Data:     data,
+	}
+}
+
+// WriteMultipleResponse confirms a WriteMultipleRequest.
+type WriteMultipleResponse struct {
+	Function uint8
+	Address  uint16 `endian:"be"`
+	Quantity uint16 `endian:"be"`
+}
+
+// ExceptionResponse is the response to a request that failed: the function
+// code of the request with the high bit set and an exception code.
+type ExceptionResponse struct {
+	Function uint8
+	Code     uint8
+}
+
+func (e ExceptionResponse) Error() string {
+	return fmt.Sprintf("modbus: function %#02x: exception %d", e.Function&^exceptionFlag, e.Code)
+}
+
+// IsException reports whether pdu is an ExceptionResponse.
+func IsException(pdu []byte) bool {
+	return len(pdu) > 0 && pdu[0]&exceptionFlag != 0
+}
@@ -0,0 +1,118 @@
+package modbus_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+	"github.com/milQA/binencoder/modbus"
+)
+
+func TestCRC16(t *testing.T) {
+	if got := modbus.CRC16([]byte("123456789")); got != 0x4b37 {
+		t.Errorf("CRC16 = %#04x, want 0x4b37", got)
+	}
+}
+
+func TestRTU(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(modbus.NewRTUWriter(buf, 1))
+	req := modbus.ReadRequest{Function: modbus.ReadHoldingRegisters, Quantity: 1}
+	if err := enc.Encode(req, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01, 0x84, 0x0a}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got % x, want % x", buf.Bytes(), want)
+	}
+
+	unit, pdu, err := modbus.ParseRTU(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out modbus.ReadRequest
+	if err := binencoder.Unmarshal(pdu, &out, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if unit != 1 || out != req {
+		t.Errorf("got unit %d %+v, want 1 %+v", unit, out, req)
+	}
+
+	want[3] ^= 1
+	if _, _, err := modbus.ParseRTU(want); !errors.Is(err, binencoder.ErrBadChecksum) {
+		t.Errorf("expected ErrBadChecksum, got %v", err)
+	}
+	if _, _, err := modbus.ParseRTU(want[:3]); !errors.Is(err, binencoder.ErrBadFrame) {
+		t.Errorf("expected ErrBadFrame, got %v", err)
+	}
+}
+
+func TestTCP(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := modbus.NewTCPWriter(buf, 0x11)
+	w.SetTransactionID(0x0102)
+	enc := binencoder.NewEncoder(w)
+	if err := enc.Encode(modbus.WriteRegisters(0x0001, []uint16{0x000a, 0x0102}), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(modbus.ExceptionResponse{Function: 0x83, Code: 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x01, 0x02, 0, 0, 0, 11, 0x11,
+		0x10, 0x00, 0x01, 0x00, 0x02, 0x04, 0x00, 0x0a, 0x01, 0x02,
+		0x01, 0x03, 0, 0, 0, 3, 0x11,
+		0x83, 0x02,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("got % x, want % x", buf.Bytes(), want)
+	}
+
+	r := modbus.NewTCPReader(buf)
+	h, pdu, err := r.ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req modbus.WriteMultipleRequest
+	if err := binencoder.Unmarshal(pdu, &req, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if h.TransactionID != 0x0102 || h.UnitID != 0x11 || req.Quantity != 2 {
+		t.Errorf("got %+v %+v", h, req)
+	}
+	resp := modbus.ReadResponse{Data: req.Data}
+	if regs := resp.Registers(); !reflect.DeepEqual(regs, []uint16{0x000a, 0x0102}) {
+		t.Errorf("got registers %v", regs)
+	}
+
+	_, pdu, err = r.ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modbus.IsException(pdu) {
+		t.Fatalf("% x is not an exception", pdu)
+	}
+	var exc modbus.ExceptionResponse
+	if err := binencoder.Unmarshal(pdu, &exc, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if exc.Error() != "modbus: function 0x03: exception 2" {
+		t.Errorf("got %q", exc.Error())
+	}
+	if _, _, err := r.ReadFrame(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	if _, _, err := modbus.NewTCPReader(bytes.NewReader(want[:10])).ReadFrame(); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+	bad := append([]byte(nil), want...)
+	bad[3] = 1
+	if _, _, err := modbus.NewTCPReader(bytes.NewReader(bad)).ReadFrame(); !errors.Is(err, binencoder.ErrBadFrame) {
+		t.Errorf("expected ErrBadFrame, got %v", err)
+	}
+}
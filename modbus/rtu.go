@@ -0,0 +1,76 @@
+package modbus
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/milQA/binencoder"
+)
+
+var _ binencoder.FrameWriter = (*RTUWriter)(nil)
+
+// RTUWriter writes Modbus RTU frames: the unit address, the data and its
+// CRC-16 in little-endian order. Data is buffered until EndFrame. The
+// silent intervals separating frames on the line are left to the serial
+// port.
+type RTUWriter struct {
+	w    io.Writer
+	unit uint8
+	buf  []byte
+}
+
+// NewRTUWriter returns an RTUWriter writing frames for unit to w. An
+// Encoder writing to it emits every value as a frame.
+func NewRTUWriter(w io.Writer, unit uint8) *RTUWriter {
+	return &RTUWriter{w: w, unit: unit, buf: []byte{unit}}
+}
+
+func (rw *RTUWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	return len(p), nil
+}
+
+// EndFrame writes the buffered data as a frame.
+func (rw *RTUWriter) EndFrame() error {
+	crc := CRC16(rw.buf)
+	_, err := rw.w.Write(append(rw.buf, byte(crc), byte(crc>>8)))
+	rw.buf = rw.buf[:1]
+	return err
+}
+
+// AbortFrame discards the buffered data.
+func (rw *RTUWriter) AbortFrame() {
+	rw.buf = rw.buf[:1]
+}
+
+// ParseRTU checks the CRC of an RTU frame and returns its unit address and
+// PDU. It returns an error matching binencoder.ErrBadFrame for a frame too
+// short to hold an address, a function code and a CRC and one matching
+// binencoder.ErrBadChecksum if the CRC does not match.
+func ParseRTU(frame []byte) (uint8, []byte, error) {
+	if len(frame) < 4 {
+		return 0, nil, fmt.Errorf("%w: RTU frame of %d bytes", binencoder.ErrBadFrame, len(frame))
+	}
+	data := frame[:len(frame)-2]
+	want := uint16(frame[len(frame)-2]) | uint16(frame[len(frame)-1])<<8
+	if got := CRC16(data); got != want {
+		return 0, nil, fmt.Errorf("%w: CRC %#04x, computed %#04x", binencoder.ErrBadChecksum, want, got)
+	}
+	return data[0], data[1:], nil
+}
+
+// CRC16 returns the CRC-16/MODBUS of b.
+func CRC16(b []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, c := range b {
+		crc ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
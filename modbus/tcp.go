@@ -0,0 +1,116 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/milQA/binencoder"
+)
+
+var _ binencoder.FrameWriter = (*TCPWriter)(nil)
+
+// mbapLen is the length of an MBAP header.
+const mbapLen = 7
+
+// maxPDULen is the longest PDU of a Modbus message.
+const maxPDULen = 253
+
+// MBAPHeader is the header of a Modbus TCP message. Length counts the unit
+// identifier and the PDU that follow it.
+type MBAPHeader struct {
+	TransactionID uint16 `endian:"be"`
+	ProtocolID    uint16 `endian:"be"`
+	Length        uint16 `endian:"be"`
+	UnitID        uint8
+}
+
+// TCPWriter writes Modbus TCP messages: an MBAP header followed by the
+// data, with a single call to the underlying writer. Data is buffered until
+// EndFrame. Transaction identifiers count up from 0; a server sets the one
+// of its request with SetTransactionID before replying.
+type TCPWriter struct {
+	w    io.Writer
+	unit uint8
+	tid  uint16
+	buf  []byte
+}
+
+// NewTCPWriter returns a TCPWriter writing messages for unit to w. An
+// Encoder writing to it emits every value as a message.
+func NewTCPWriter(w io.Writer, unit uint8) *TCPWriter {
+	return &TCPWriter{w: w, unit: unit, buf: make([]byte, mbapLen)}
+}
+
+// SetTransactionID sets the transaction identifier of the next message.
+func (tw *TCPWriter) SetTransactionID(id uint16) {
+	tw.tid = id
+}
+
+func (tw *TCPWriter) Write(p []byte) (int, error) {
+	tw.buf = append(tw.buf, p...)
+	return len(p), nil
+}
+
+// EndFrame writes the buffered data as a message. It returns an error
+// matching binencoder.ErrMessageTooLarge for data longer than a PDU.
+func (tw *TCPWriter) EndFrame() error {
+	defer tw.AbortFrame()
+	pdu := len(tw.buf) - mbapLen
+	if pdu > maxPDULen {
+		return fmt.Errorf("%w: PDU of %d bytes", binencoder.ErrMessageTooLarge, pdu)
+	}
+	h := MBAPHeader{TransactionID: tw.tid, Length: uint16(pdu + 1), UnitID: tw.unit}
+	if _, err := binencoder.EncodeTo(tw.buf[:mbapLen], h); err != nil {
+		return err
+	}
+	tw.tid++
+	_, err := tw.w.Write(tw.buf)
+	return err
+}
+
+// AbortFrame discards the buffered data.
+func (tw *TCPWriter) AbortFrame() {
+	tw.buf = tw.buf[:mbapLen]
+}
+
+// TCPReader reads Modbus TCP messages.
+type TCPReader struct {
+	dec *binencoder.Decoder
+	r   io.Reader
+	buf []byte
+}
+
+// NewTCPReader returns a TCPReader reading from r.
+func NewTCPReader(r io.Reader) *TCPReader {
+	return &TCPReader{dec: binencoder.NewDecoder(r, binencoder.WithByteOrder(binary.BigEndian)), r: r}
+}
+
+// ReadFrame reads the next message and returns its header and PDU. The PDU
+// is only valid until the next call. It returns io.EOF at the end of the
+// input, an error matching binencoder.ErrShortMessage if the input ends
+// within a message and one matching binencoder.ErrBadFrame for a protocol
+// identifier other than 0 or a length out of range.
+func (tr *TCPReader) ReadFrame() (MBAPHeader, []byte, error) {
+	var h MBAPHeader
+	if err := tr.dec.Decode(&h, 0); err != nil {
+		return h, nil, err
+	}
+	if h.ProtocolID != 0 {
+		return h, nil, fmt.Errorf("%w: MBAP protocol %d", binencoder.ErrBadFrame, h.ProtocolID)
+	}
+	if h.Length < 2 || h.Length > maxPDULen+1 {
+		return h, nil, fmt.Errorf("%w: MBAP length %d", binencoder.ErrBadFrame, h.Length)
+	}
+	if cap(tr.buf) < int(h.Length) {
+		tr.buf = make([]byte, maxPDULen)
+	}
+	tr.buf = tr.buf[:h.Length-1]
+	if _, err := io.ReadFull(tr.r, tr.buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = binencoder.ErrShortMessage
+		}
+		return h, nil, err
+	}
+	return h, tr.buf, nil
+}
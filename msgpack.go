@@ -0,0 +1,153 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// appendMsgpack appends the MessagePack encoding of v to b. Structs are
+// written as maps keyed by field name and maps with their entries sorted by
+// the encoding of the keys.
+func (enc *Encoder) appendMsgpack(b []byte, v reflect.Value, tags fieldTags, path string) ([]byte, error) {
+	w, err := enc.formatValue(v, tags)
+	if err != nil {
+		return nil, newEncodeError(path, v.Type(), err)
+	}
+	v = w
+	if !v.IsValid() {
+		return append(b, 0xc0), nil
+	}
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case isInt(v.Kind()):
+		return appendMsgpackInt(b, v.Int()), nil
+	case isUint(v.Kind()):
+		return appendMsgpackUint(b, v.Uint()), nil
+	case v.Kind() == reflect.Float32:
+		return appendUint32(append(b, 0xca), math.Float32bits(float32(v.Float()))), nil
+	case v.Kind() == reflect.Float64:
+		return appendUint64(append(b, 0xcb), math.Float64bits(v.Float())), nil
+	case v.Kind() == reflect.String:
+		b = appendMsgpackHeader(b, v.Len(), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		return append(b, v.String()...), nil
+	case isBytes(v):
+		b = appendMsgpackHeader(b, v.Len(), 0, 0, 0xc4, 0xc5, 0xc6)
+		return append(b, bytesOf(v)...), nil
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		b = appendMsgpackHeader(b, v.Len(), 0x90, 16, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if b, err = enc.appendMsgpack(b, v.Index(i), tags, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case v.Kind() == reflect.Map:
+		entries, err := formatEntries(v, tags, path, enc.appendMsgpack)
+		if err != nil {
+			return nil, err
+		}
+		b = appendMsgpackHeader(b, len(entries), 0x80, 16, 0, 0xde, 0xdf)
+		for _, e := range entries {
+			b = append(append(b, e.key...), e.value...)
+		}
+		return b, nil
+	case v.Kind() == reflect.Struct:
+		fields, err := enc.formatFields(v, path)
+		if err != nil {
+			return nil, err
+		}
+		fields = presentFields(fields)
+		b = appendMsgpackHeader(b, len(fields), 0x80, 16, 0, 0xde, 0xdf)
+		for _, f := range fields {
+			b = appendMsgpackHeader(b, len(f.plan.name), 0xa0, 32, 0xd9, 0xda, 0xdb)
+			b = append(b, f.plan.name...)
+			if b, err = enc.appendMsgpack(b, f.value, f.plan.tags, f.path); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, newEncodeError(path, v.Type(), fmt.Errorf("%w: %s in MessagePack", ErrUnknownType, v.Kind()))
+}
+
+// appendMsgpackHeader appends the type and length of a string, binary,
+// array or map of n items: a fix type if n is below fixMax, otherwise the
+// first of the 8, 16 and 32-bit forms that exists (is not 0) and fits.
+func appendMsgpackHeader(b []byte, n int, fix byte, fixMax int, t8, t16, t32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fix|byte(n))
+	case t8 != 0 && n <= math.MaxUint8:
+		return append(b, t8, byte(n))
+	case n <= math.MaxUint16:
+		return appendUint16(append(b, t16), uint16(n))
+	}
+	return appendUint32(append(b, t32), uint32(n))
+}
+
+func appendMsgpackUint(b []byte, x uint64) []byte {
+	switch {
+	case x <= 0x7f:
+		return append(b, byte(x))
+	case x <= math.MaxUint8:
+		return append(b, 0xcc, byte(x))
+	case x <= math.MaxUint16:
+		return appendUint16(append(b, 0xcd), uint16(x))
+	case x <= math.MaxUint32:
+		return appendUint32(append(b, 0xce), uint32(x))
+	}
+	return appendUint64(append(b, 0xcf), x)
+}
+
+func appendMsgpackInt(b []byte, x int64) []byte {
+	switch {
+	case x >= 0:
+		return appendMsgpackUint(b, uint64(x))
+	case x >= -32:
+		return append(b, byte(x))
+	case x >= math.MinInt8:
+		return append(b, 0xd0, byte(x))
+	case x >= math.MinInt16:
+		return appendUint16(append(b, 0xd1), uint16(x))
+	case x >= math.MinInt32:
+		return appendUint32(append(b, 0xd2), uint32(x))
+	}
+	return appendUint64(append(b, 0xd3), uint64(x))
+}
+
+// presentFields drops the nil pointer fields the formats omit.
+func presentFields(fields []formatField) []formatField {
+	present := fields[:0]
+	for _, f := range fields {
+		if f.value.Kind() == reflect.Ptr && f.value.IsNil() {
+			continue
+		}
+		present = append(present, f)
+	}
+	return present
+}
+
+func appendUint16(b []byte, x uint16) []byte {
+	var w [2]byte
+	binary.BigEndian.PutUint16(w[:], x)
+	return append(b, w[:]...)
+}
+
+func appendUint32(b []byte, x uint32) []byte {
+	var w [4]byte
+	binary.BigEndian.PutUint32(w[:], x)
+	return append(b, w[:]...)
+}
+
+func appendUint64(b []byte, x uint64) []byte {
+	var w [8]byte
+	binary.BigEndian.PutUint64(w[:], x)
+	return append(b, w[:]...)
+}
@@ -0,0 +1,59 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFormatMsgpack(t *testing.T) {
+	type message struct {
+		ID   uint16
+		Name string
+		Data []byte
+		Neg  int8
+		Big  uint32
+		List []bool
+		Opt  *uint8
+		Skip uint8 `len:"-"`
+		F    float64
+	}
+	in := message{ID: 1, Name: "ab", Data: []byte{1, 2}, Neg: -33, Big: 70000, List: []bool{true, false}, F: 0.5}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatMsgpack)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x87,
+		0xa2, 'I', 'D', 0x01,
+		0xa4, 'N', 'a', 'm', 'e', 0xa2, 'a', 'b',
+		0xa4, 'D', 'a', 't', 'a', 0xc4, 0x02, 0x01, 0x02,
+		0xa3, 'N', 'e', 'g', 0xd0, 0xdf,
+		0xa3, 'B', 'i', 'g', 0xce, 0x00, 0x01, 0x11, 0x70,
+		0xa4, 'L', 'i', 's', 't', 0x92, 0xc3, 0xc2,
+		0xa1, 'F', 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0,
+	})
+
+	buf.Reset()
+	long := make([]uint8, 20)
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatMsgpack)).Encode([]interface{}{long, string(long)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0x92, 0xc4, 20}) {
+		t.Errorf("unexpected encoding % x", buf.Bytes())
+	}
+}
+
+func TestFormatMsgpackMap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	in := map[string]uint16{"b": 300, "a": 1}
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatMsgpack)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x82,
+		0xa1, 'a', 0x01,
+		0xa1, 'b', 0xcd, 0x01, 0x2c,
+	})
+}
@@ -0,0 +1,77 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// WithNarrowInts lets integer fields take a `len` shorter than their type,
+// e.g. a uint32 stored in 2 bytes or an int32 restricted to the range of
+// int16. Values are checked against the width and encoded in its low
+// bytes; those that do not fit fail with an error matching ErrOverflow and
+// the field's path. Signed values are sign-extended when decoded. Without
+// it such fields fail with ErrFieldTooLong whatever their value.
+func WithNarrowInts(enabled bool) Option {
+	return func(c *config) {
+		c.narrowInts = enabled
+	}
+}
+
+// narrows reports whether values of type t are narrowed to bytesLen bytes.
+func (c *config) narrows(t reflect.Type, bytesLen int) bool {
+	if !c.narrowInts || bytesLen == 0 {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Uint16, reflect.Int16, reflect.Uint32, reflect.Int32, reflect.Uint64, reflect.Int64:
+		return bytesLen < int(t.Size())
+	}
+	return false
+}
+
+// narrowInt returns the low n bytes of by, the encoding of integer v, in
+// byte order order, after checking that v fits them.
+func narrowInt(by []byte, v reflect.Value, n int, order binary.ByteOrder) ([]byte, error) {
+	bits := uint(8 * n)
+	switch v.Kind() {
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if x := v.Int(); x < -1<<(bits-1) || x >= 1<<(bits-1) {
+			return nil, fmt.Errorf("%w: %d does not fit %d bytes", ErrOverflow, x, n)
+		}
+	default:
+		if x := v.Uint(); x >= 1<<bits {
+			return nil, fmt.Errorf("%w: %d does not fit %d bytes", ErrOverflow, x, n)
+		}
+	}
+	if order == binary.LittleEndian {
+		return by[:n], nil
+	}
+	return by[len(by)-n:], nil
+}
+
+// widenInt returns b, the low bytes of integer v read in byte order
+// order, extended to size bytes.
+func widenInt(b []byte, v reflect.Value, size int, order binary.ByteOrder) []byte {
+	ext := byte(0)
+	msb := b[len(b)-1]
+	if order != binary.LittleEndian {
+		msb = b[0]
+	}
+	switch v.Kind() {
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if msb&0x80 != 0 {
+			ext = 0xff
+		}
+	}
+	out := make([]byte, size)
+	for i := range out {
+		out[i] = ext
+	}
+	if order == binary.LittleEndian {
+		copy(out, b)
+	} else {
+		copy(out[size-len(b):], b)
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package binencoder
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+)
+
+var (
+	ipType           = reflect.TypeOf(net.IP{})
+	ipNetType        = reflect.TypeOf(net.IPNet{})
+	hardwareAddrType = reflect.TypeOf(net.HardwareAddr{})
+)
+
+// rawBytes is the wire value of types whose representation is a byte
+// sequence written verbatim, without per-element padding.
+type rawBytes []byte
+
+var rawBytesType = reflect.TypeOf(rawBytes{})
+
+// ipWidth returns the number of bytes an address is encoded with: the `ip`
+// tag forces 4 ("v4") or 16 ("v6", "16") bytes, otherwise IPv4 addresses use
+// 4 bytes and everything else 16.
+func ipWidth(ip net.IP, tag string) (int, error) {
+	switch tag {
+	case "":
+		if ip.To4() != nil {
+			return net.IPv4len, nil
+		}
+		return net.IPv6len, nil
+	case "v4":
+		return net.IPv4len, nil
+	case "v6", "16":
+		return net.IPv6len, nil
+	default:
+		return 0, fmt.Errorf("binencoder: unknown ip tag %q", tag)
+	}
+}
+
+func ipBytes(ip net.IP, width int) ([]byte, error) {
+	if ip == nil {
+		return make([]byte, width), nil
+	}
+	if width == net.IPv4len {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4, nil
+		}
+		return nil, fmt.Errorf("%w: %s is not an IPv4 address", ErrOverflow, ip)
+	}
+	if ip16 := ip.To16(); ip16 != nil {
+		return ip16, nil
+	}
+	return nil, fmt.Errorf("binencoder: invalid IP address %v", []byte(ip))
+}
+
+// netToWire encodes net.IP, net.IPNet (address followed by mask of the same
+// width) and net.HardwareAddr.
+func netToWire(v reflect.Value, tag string) (reflect.Value, error) {
+	switch v.Type() {
+	case ipType:
+		ip := v.Interface().(net.IP)
+		width, err := ipWidth(ip, tag)
+		if err != nil {
+			return v, err
+		}
+		b, err := ipBytes(ip, width)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(rawBytes(b)), nil
+	case ipNetType:
+		n := v.Interface().(net.IPNet)
+		width, err := ipWidth(n.IP, tag)
+		if err != nil {
+			return v, err
+		}
+		ip, err := ipBytes(n.IP, width)
+		if err != nil {
+			return v, err
+		}
+		mask := n.Mask
+		switch {
+		case mask == nil:
+			mask = make(net.IPMask, width)
+		case len(mask) == net.IPv4len && width == net.IPv6len:
+			ones, _ := mask.Size()
+			mask = net.CIDRMask(ones+8*(net.IPv6len-net.IPv4len), 8*net.IPv6len)
+		case len(mask) == net.IPv6len && width == net.IPv4len:
+			mask = mask[net.IPv6len-net.IPv4len:]
+		}
+		if len(mask) != width {
+			return v, fmt.Errorf("binencoder: invalid IP mask %v", []byte(n.Mask))
+		}
+		return reflect.ValueOf(rawBytes(append(append([]byte{}, ip...), mask...))), nil
+	default:
+		return reflect.ValueOf(rawBytes(v.Bytes())), nil
+	}
+}
+
+// netWireSize returns the number of bytes to read for a network address
+// field. Untagged addresses use the width of the current value.
+func netWireSize(v reflect.Value, tag string) (int, error) {
+	switch v.Type() {
+	case ipType:
+		return ipWidth(v.Interface().(net.IP), tag)
+	case ipNetType:
+		width, err := ipWidth(v.Interface().(net.IPNet).IP, tag)
+		return 2 * width, err
+	default:
+		if v.Len() == 0 {
+			return 6, nil
+		}
+		return v.Len(), nil
+	}
+}
+
+func netFromWire(v reflect.Value, b []byte) {
+	switch v.Type() {
+	case ipType:
+		v.Set(reflect.ValueOf(net.IP(b)))
+	case ipNetType:
+		half := len(b) / 2
+		v.Set(reflect.ValueOf(net.IPNet{IP: net.IP(b[:half]), Mask: net.IPMask(b[half:])}))
+	default:
+		v.Set(reflect.ValueOf(net.HardwareAddr(b)))
+	}
+}
@@ -0,0 +1,62 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type packetHeader struct {
+	Src    net.IP
+	Dst    net.IP `ip:"v6"`
+	Mapped net.IP `ip:"16"`
+	Net    *net.IPNet
+	MAC    net.HardwareAddr
+}
+
+func TestNetTypes(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("10.1.0.0/16")
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	in := packetHeader{
+		Src:    net.ParseIP("192.168.0.1"),
+		Dst:    net.ParseIP("2001:db8::1"),
+		Mapped: net.ParseIP("10.0.0.1"),
+		Net:    ipNet,
+		MAC:    mac,
+	}
+
+	buf := new(bytes.Buffer)
+	err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		192, 168, 0, 1,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 0, 0, 1,
+		10, 1, 0, 0, 255, 255, 0, 0,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+	})
+
+	out := packetHeader{Src: net.IPv4zero, Net: &net.IPNet{IP: net.IPv4zero}}
+	err = binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Decode(&out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Src.Equal(in.Src) || !out.Dst.Equal(in.Dst) || !out.Mapped.Equal(in.Mapped) ||
+		out.Net.String() != "10.1.0.0/16" || !reflect.DeepEqual(out.MAC, in.MAC) {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	err = binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(struct {
+		IP net.IP `ip:"v4"`
+	}{net.ParseIP("::1")}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !race
+// +build !race
+
+package binencoder_test
+
+const raceEnabled = false
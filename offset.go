@@ -0,0 +1,97 @@
+package binencoder
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// parseOffsetTag parses the value of an `offset` tag, a position from the
+// start of the message in any Go integer syntax, returning -1 for an empty
+// tag.
+func parseOffsetTag(tag string) (int, error) {
+	if tag == "" {
+		return -1, nil
+	}
+	n, err := strconv.ParseInt(tag, 0, 0)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("binencoder: invalid offset tag %q", tag)
+	}
+	return int(n), nil
+}
+
+// seek moves the encoder to offset bytes from the start of the message.
+// Gaps are filled with the pad byte; positions already written to need a
+// writer that implements io.Seeker.
+func (enc *Encoder) seek(offset int) error {
+	if offset >= enc.n {
+		return enc.writePadding(offset - enc.n)
+	}
+	s, ok := enc.w.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("binencoder: offset %#x is behind position %#x and the writer cannot seek", offset, enc.n)
+	}
+	if _, err := s.Seek(int64(offset-enc.n), io.SeekCurrent); err != nil {
+		return err
+	}
+	if enc.n > enc.end {
+		enc.end = enc.n
+	}
+	enc.n = offset
+	return nil
+}
+
+// seekEnd moves the encoder past the last byte of the message after fields
+// were placed behind it.
+func (enc *Encoder) seekEnd() error {
+	if enc.end <= enc.n {
+		return nil
+	}
+	if _, err := enc.w.(io.Seeker).Seek(int64(enc.end-enc.n), io.SeekCurrent); err != nil {
+		return err
+	}
+	enc.n = enc.end
+	return nil
+}
+
+// seek is the inverse of Encoder.seek: gaps are skipped and positions
+// already read from need a reader that implements io.Seeker.
+func (dec *Decoder) seek(offset int) error {
+	if offset >= dec.n {
+		if err := dec.reserve(offset - dec.n); err != nil {
+			return err
+		}
+		skipped, err := io.CopyN(ioutil.Discard, dec.r, int64(offset-dec.n))
+		dec.n += int(skipped)
+		if err == io.EOF {
+			err = ErrShortMessage
+		}
+		return err
+	}
+	s, ok := dec.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("binencoder: offset %#x is behind position %#x and the reader cannot seek", offset, dec.n)
+	}
+	if _, err := s.Seek(int64(offset-dec.n), io.SeekCurrent); err != nil {
+		return err
+	}
+	if dec.n > dec.end {
+		dec.end = dec.n
+	}
+	dec.n = offset
+	return nil
+}
+
+// seekEnd moves the decoder past the last byte of the message after fields
+// were read from behind it.
+func (dec *Decoder) seekEnd() error {
+	if dec.end <= dec.n {
+		return nil
+	}
+	if _, err := dec.r.(io.Seeker).Seek(int64(dec.end-dec.n), io.SeekCurrent); err != nil {
+		return err
+	}
+	dec.n = dec.end
+	return nil
+}
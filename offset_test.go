@@ -0,0 +1,78 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestOffset(t *testing.T) {
+	type image struct {
+		Magic uint16
+		Entry uint8 `offset:"0x6"`
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithPadByte(0xff)).Encode(image{0x0201, 3}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 2, 0xff, 0xff, 0xff, 0xff, 3})
+
+	var out image
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != (image{0x0201, 3}) || buf.Len() != 0 {
+		t.Errorf("got %+v with %d bytes left", out, buf.Len())
+	}
+}
+
+func TestOffsetSeek(t *testing.T) {
+	type header struct {
+		Body   [2]uint8 `offset:"4"`
+		Length uint8    `offset:"0"`
+	}
+	f, err := ioutil.TempFile("", "offset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	enc := binencoder.NewEncoder(f)
+	for i := uint8(1); i <= 2; i++ {
+		n, err := enc.EncodeN(header{Body: [2]uint8{i, i}, Length: 2}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 6 {
+			t.Errorf("wrote %d bytes, want 6", n)
+		}
+	}
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{2, 0, 0, 0, 1, 1, 2, 0, 0, 0, 2, 2})
+
+	r := bytes.NewReader(b)
+	dec := binencoder.NewDecoder(r)
+	for i := uint8(1); i <= 2; i++ {
+		var out header
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != (header{Body: [2]uint8{i, i}, Length: 2}) {
+			t.Errorf("record %d: got %+v", i, out)
+		}
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d bytes left", r.Len())
+	}
+
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(header{}, 0); err == nil {
+		t.Error("expected an error for an offset behind the position of a plain writer")
+	}
+}
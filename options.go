@@ -0,0 +1,212 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// Option configures an Encoder or a Decoder.
+type Option func(*config)
+
+// config holds the settings shared by Encoder and Decoder.
+type config struct {
+	byteOrder     binary.ByteOrder
+	tagName       string
+	strict        bool
+	padByte       byte
+	logger        Logger
+	unsafe        bool
+	maxSize       int
+	tlvTag        int
+	tlvLen        int
+	format        Format
+	trace         io.Writer
+	version       int
+	trailer       *checksum
+	sealKey       []byte
+	rand          io.Reader
+	metrics       Metrics
+	bufSize       int
+	borrow        bool
+	presence      bool
+	narrowInts    bool
+	orderedInts   bool
+	progress      ProgressFunc
+	progressEvery int
+
+	// Limits on lengths read from untrusted input, zero for none.
+	maxSliceLen    int
+	maxStringLen   int
+	maxMessageSize int
+}
+
+// init resets c to the defaults and applies opts.
+func (c *config) init(opts []Option) {
+	*c = config{
+		byteOrder: binary.LittleEndian,
+		tagName:   "bin",
+		logger:    nopLogger{},
+		tlvTag:    1,
+		tlvLen:    1,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithByteOrder sets the byte order. The default is binary.LittleEndian.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(c *config) {
+		c.byteOrder = order
+	}
+}
+
+// WithIntByteOrder writes and reads numbers in the byte order. Without it
+// numbers are little-endian whatever the byte order, which only decides on
+// which side of a field its padding goes, as in earlier versions of the
+// package. Fields with an `endian` option and structs implementing
+// ByteOrderer always have their numbers in their own byte order.
+func WithIntByteOrder(enabled bool) Option {
+	return func(c *config) {
+		c.orderedInts = enabled
+	}
+}
+
+// intOrder returns the byte order numbers are written in.
+func (c *config) intOrder() binary.ByteOrder {
+	if c.orderedInts {
+		return c.byteOrder
+	}
+	return binary.LittleEndian
+}
+
+// useOrder switches to the byte order of a field or struct, numbers
+// included, and returns a function switching back.
+func (c *config) useOrder(order binary.ByteOrder) func() {
+	prevOrder, prevInts := c.byteOrder, c.orderedInts
+	c.byteOrder, c.orderedInts = order, true
+	return func() { c.byteOrder, c.orderedInts = prevOrder, prevInts }
+}
+
+// WithTagName sets the key of the namespace tag, `bin` by default. Its
+// value lists field options named after the individual tags, e.g.
+// `bin:"len=10,endian=be"`; a bare first item is the length. Individual
+// tags such as `len:"10"` are still honored for options it does not set.
+func WithTagName(name string) Option {
+	return func(c *config) {
+		c.tagName = name
+	}
+}
+
+// WithStrict makes values of unsupported types fail with an error matching
+// ErrUnknownType instead of being logged and skipped.
+func WithStrict(strict bool) Option {
+	return func(c *config) {
+		c.strict = strict
+	}
+}
+
+// WithPadByte sets the byte fields are padded with up to their `len`. The
+// default is zero. Decoded strings are trimmed of it.
+func WithPadByte(b byte) Option {
+	return func(c *config) {
+		c.padByte = b
+	}
+}
+
+// WithLogger sets the logger for diagnostics. A nil logger discards them,
+// which is the default.
+func WithLogger(l Logger) Option {
+	return func(c *config) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		c.logger = l
+	}
+}
+
+// WithMaxSize limits the number of bytes a single Encode call may write.
+// A write that would exceed it is not made and encoding fails with an
+// error matching ErrMessageTooLarge. Zero, the default, means no limit.
+// Decoders ignore it; see WithMaxMessageSize.
+func WithMaxSize(n int) Option {
+	return func(c *config) {
+		c.maxSize = n
+	}
+}
+
+// WithMaxSliceLen limits the length of byte slices, io.Reader fields and
+// maps a Decoder allocates from a length read from the input: TLV and KLV
+// item lengths, `sizeof` fields, `prefix` tags and map entry counts. A
+// longer length fails with an error matching ErrLimitExceeded before
+// anything is allocated. Zero, the default, means no limit. Encoders
+// ignore it.
+func WithMaxSliceLen(n int) Option {
+	return func(c *config) {
+		c.maxSliceLen = n
+	}
+}
+
+// WithMaxStringLen is like WithMaxSliceLen for strings.
+func WithMaxStringLen(n int) Option {
+	return func(c *config) {
+		c.maxStringLen = n
+	}
+}
+
+// WithMaxMessageSize limits the number of bytes a single Decode call may
+// read. Reads and lengths read from the input that would exceed it fail with
+// an error matching ErrMessageTooLarge before anything is allocated. Zero,
+// the default, means no limit. Encoders ignore it; see WithMaxSize.
+func WithMaxMessageSize(n int) Option {
+	return func(c *config) {
+		c.maxMessageSize = n
+	}
+}
+
+// WithTLV sets the widths in bytes of the tag and the length of the TLV
+// items written for fields with a `tlv` tag, 1 and 1 by default. Both are
+// unsigned integers in the configured byte order; a length width of 0
+// selects BER lengths.
+func WithTLV(tagWidth, lenWidth int) Option {
+	return func(c *config) {
+		c.tlvTag = tagWidth
+		c.tlvLen = lenWidth
+	}
+}
+
+// WithVersion selects the protocol revision to encode and decode: fields
+// with a `minver` tag greater than n or a `maxver` tag less than n are left
+// out. Without it every field is present.
+func WithVersion(n int) Option {
+	return func(c *config) {
+		c.version = n
+	}
+}
+
+// WithTrace makes an Encoder write a line to w for every field it encodes:
+// the field's offset in the message, its length, its path and its bytes in
+// hex. Fields holding structs are traced through their own fields, TLV and
+// KLV items as a whole. Decoders ignore it.
+func WithTrace(w io.Writer) Option {
+	return func(c *config) {
+		c.trace = w
+	}
+}
+
+// WithTrailerChecksum makes an Encoder append to every message the CRC-32
+// with polynomial poly, e.g. crc32.IEEE, of its bytes, in the byte order
+// of the Encoder, and a Decoder verify and strip it, returning
+// ErrBadChecksum on a mismatch. See WithTrailer for other checksums.
+func WithTrailerChecksum(poly uint32) Option {
+	table := crc32.MakeTable(poly)
+	return func(c *config) {
+		c.trailer = &checksum{
+			name: fmt.Sprintf("crc32/%#08x", poly),
+			new:  func() hash.Hash { return crc32.New(table) },
+		}
+	}
+}
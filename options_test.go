@@ -0,0 +1,244 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestOptionsDefaults(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(uint16(0x0102), 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x02, 0x01})
+}
+
+func TestOptionsPadByte(t *testing.T) {
+	type label struct {
+		Name string `len:"5"`
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		buf := new(bytes.Buffer)
+		opts := []binencoder.Option{binencoder.WithByteOrder(order), binencoder.WithPadByte(' ')}
+		if err := binencoder.NewEncoder(buf, opts...).Encode(label{"ab"}, 0); err != nil {
+			t.Fatal(err)
+		}
+		want := []byte("ab   ")
+		if order == binary.BigEndian {
+			want = []byte("   ab")
+		}
+		equalByte(t, buf.Bytes(), want)
+
+		out := label{Name: "12345"}
+		if err := binencoder.NewDecoder(buf, opts...).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out.Name != "ab" {
+			t.Errorf("%v: got %q", order, out.Name)
+		}
+	}
+}
+
+func TestOptionsTagName(t *testing.T) {
+	type message struct {
+		ID   uint8  `size:"2" len:"4"`
+		Name string `size:"3"`
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithTagName("size"))
+	if err := enc.Encode(message{1, "a"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x01, 0x00, 'a', 0x00, 0x00})
+}
+
+func TestOptionsStrict(t *testing.T) {
+	type message struct {
+		ID    uint8
+		Ratio float32
+	}
+	buf := new(bytes.Buffer)
+	err := binencoder.NewEncoder(buf, binencoder.WithStrict(true)).Encode(message{}, 0)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("encode: got %v", err)
+	}
+
+	dec := binencoder.NewDecoder(bytes.NewReader([]byte{1}), binencoder.WithStrict(true))
+	err = dec.Decode(&message{}, 0)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("decode: got %v", err)
+	}
+}
+
+func TestOptionsMaxSize(t *testing.T) {
+	type message struct {
+		ID      uint16
+		Payload []byte
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithMaxSize(4))
+	if err := enc.Encode(message{1, []byte{2, 3}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	err := enc.Encode(message{1, []byte{2, 3, 4}}, 0)
+	if !errors.Is(err, binencoder.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: encoding Payload ([]uint8): message too large: 5 bytes exceed the limit of 4")
+	equalByte(t, buf.Bytes(), []byte{1, 0})
+}
+
+func TestOptionsVersion(t *testing.T) {
+	type record struct {
+		ID     uint8
+		Flags  uint8  `minver:"2"`
+		Legacy uint16 `maxver:"1"`
+		Extra  uint8  `bin:"minver=3"`
+	}
+	v := record{ID: 1, Flags: 2, Legacy: 3, Extra: 4}
+	for _, c := range []struct {
+		version int
+		want    []byte
+	}{
+		{0, []byte{1, 2, 3, 0, 4}},
+		{1, []byte{1, 3, 0}},
+		{2, []byte{1, 2}},
+		{3, []byte{1, 2, 4}},
+	} {
+		var buf bytes.Buffer
+		if err := binencoder.NewEncoder(&buf, binencoder.WithVersion(c.version)).Encode(v, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), c.want)
+
+		var out record
+		if err := binencoder.NewDecoder(&buf, binencoder.WithVersion(c.version)).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("version %d: %d bytes left", c.version, buf.Len())
+		}
+	}
+
+	type bad struct {
+		A uint8 `minver:"x"`
+	}
+	if err := binencoder.NewEncoder(&bytes.Buffer{}, binencoder.WithVersion(1)).Encode(bad{}, 0); err == nil {
+		t.Error("expected an error for a bad version tag")
+	}
+}
+
+func TestOptionsTrailerChecksum(t *testing.T) {
+	opts := []binencoder.Option{binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true), binencoder.WithTrailerChecksum(crc32.IEEE)}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, opts...).Encode(uint16(0x0102), 0); err != nil {
+		t.Fatal(err)
+	}
+	sum := crc32.ChecksumIEEE([]byte{1, 2})
+	equalByte(t, buf.Bytes(), []byte{1, 2, byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)})
+
+	b := append([]byte(nil), buf.Bytes()...)
+	var out uint16
+	if err := binencoder.NewDecoder(buf, opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != 0x0102 || buf.Len() != 0 {
+		t.Errorf("got %#x with %d bytes left", out, buf.Len())
+	}
+
+	b[1] ^= 0xff
+	err := binencoder.NewDecoder(bytes.NewReader(b), opts...).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrBadChecksum) {
+		t.Errorf("expected ErrBadChecksum, got %v", err)
+	}
+}
+
+// fletcher16 is the Fletcher-16 checksum, a hash.Hash with a 2-byte sum.
+type fletcher16 struct{ a, b uint16 }
+
+func (f *fletcher16) Write(p []byte) (int, error) {
+	for _, c := range p {
+		f.a = (f.a + uint16(c)) % 255
+		f.b = (f.b + f.a) % 255
+	}
+	return len(p), nil
+}
+
+func (f *fletcher16) Sum(b []byte) []byte { return append(b, byte(f.b), byte(f.a)) }
+func (f *fletcher16) Reset()              { *f = fletcher16{} }
+func (f *fletcher16) Size() int           { return 2 }
+func (f *fletcher16) BlockSize() int      { return 1 }
+
+func TestOptionsTrailer(t *testing.T) {
+	binencoder.RegisterChecksum("test-fletcher16", func() hash.Hash { return new(fletcher16) })
+	buf := new(bytes.Buffer)
+	opts := []binencoder.Option{binencoder.WithTrailer("test-fletcher16")}
+	if err := binencoder.NewEncoder(buf, opts...).Encode([]byte("abcde"), 0); err != nil {
+		t.Fatal(err)
+	}
+	// 0xc8f0 is the Fletcher-16 check value of "abcde".
+	equalByte(t, buf.Bytes(), []byte{'a', 'b', 'c', 'd', 'e', 0xc8, 0xf0})
+	out := make([]byte, 5)
+	if err := binencoder.NewDecoder(buf, opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = []binencoder.Option{binencoder.WithTrailer("crc32c")}
+	if err := binencoder.NewEncoder(buf, opts...).Encode(uint8(1), 0); err != nil {
+		t.Fatal(err)
+	}
+	sum := crc32.Checksum([]byte{1}, crc32.MakeTable(crc32.Castagnoli))
+	equalByte(t, buf.Bytes(), []byte{1, byte(sum), byte(sum >> 8), byte(sum >> 16), byte(sum >> 24)})
+
+	err := binencoder.NewEncoder(buf, binencoder.WithTrailer("test-missing")).Encode(uint8(1), 0)
+	if err == nil {
+		t.Error("expected an error for an unknown checksum")
+	}
+}
+
+func TestOptionsNarrowInts(t *testing.T) {
+	type reading struct {
+		ID    uint32    `len:"2"`
+		Delta int32     `len:"2"`
+		Raw   [2]uint64 `len:"3"`
+	}
+	in := reading{ID: 0x1234, Delta: -2, Raw: [2]uint64{1, 0xffffff}}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		buf := new(bytes.Buffer)
+		enc := binencoder.NewEncoder(buf, binencoder.WithByteOrder(order), binencoder.WithNarrowInts(true), binencoder.WithIntByteOrder(true))
+		if err := enc.Encode(in, 0); err != nil {
+			t.Fatal(err)
+		}
+		want := []byte{0x34, 0x12, 0xfe, 0xff, 1, 0, 0, 0xff, 0xff, 0xff}
+		if order == binary.BigEndian {
+			want = []byte{0x12, 0x34, 0xff, 0xfe, 0, 0, 1, 0xff, 0xff, 0xff}
+		}
+		equalByte(t, buf.Bytes(), want)
+
+		var out reading
+		if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(order), binencoder.WithNarrowInts(true), binencoder.WithIntByteOrder(true)).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("got %+v, want %+v", out, in)
+		}
+	}
+
+	for _, v := range []reading{{ID: 0x10000}, {Delta: 0x8000}, {Delta: -0x8001}, {Raw: [2]uint64{0, 1 << 24}}} {
+		err := binencoder.NewEncoder(new(bytes.Buffer), binencoder.WithNarrowInts(true)).Encode(v, 0)
+		if !errors.Is(err, binencoder.ErrOverflow) {
+			t.Errorf("%+v: expected ErrOverflow, got %v", v, err)
+		}
+	}
+	err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(reading{}, 0)
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong without the option, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package pcap writes packets to capture files in the pcap and pcapng
+// formats, which Wireshark and tcpdump open. A Writer is a
+// binencoder.FrameWriter, so an Encoder writing to it records every value
+// as a packet:
+//
+//	w, err := pcap.NewWriter(f, pcap.LinkTypeUser0)
+//	enc := binencoder.NewEncoder(w)
+//	err = enc.Encode(msg, 0)
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+var _ binencoder.FrameWriter = (*Writer)(nil)
+
+// Link types naming the protocol of the packets, from
+// https://www.tcpdump.org/linktypes.html.
+const (
+	LinkTypeEthernet = 1
+	LinkTypeRaw      = 101
+	// LinkTypeUser0 to LinkTypeUser15 are reserved for private use, such
+	// as the messages of an application protocol.
+	LinkTypeUser0  = 147
+	LinkTypeUser15 = 162
+)
+
+// SnapLen is the longest packet a Writer records; longer packets are
+// truncated.
+const SnapLen = 262144
+
+// Block types and magic numbers of the formats.
+const (
+	pcapMagic    = 0xa1b2c3d4
+	ngSection    = 0x0a0d0d0a
+	ngInterface  = 0x00000001
+	ngPacket     = 0x00000006
+	ngByteOrder  = 0x1a2b3c4d
+	ngHeaderLen  = 28
+	ngPacketLen  = 32
+	ngIfaceLen   = 20
+	ngDataAlign  = 4
+	pcapMajor    = 2
+	pcapMinor    = 4
+	ngMajor      = 1
+	ngNoSection  = -1
+	microsPerSec = 1e6
+)
+
+type fileHeader struct {
+	Magic    uint32
+	Major    uint16
+	Minor    uint16
+	Zone     int32
+	SigFigs  uint32
+	SnapLen  uint32
+	LinkType uint32
+}
+
+type recordHeader struct {
+	Sec      uint32
+	Micros   uint32
+	Captured uint32
+	Original uint32
+}
+
+type sectionHeader struct {
+	Type      uint32
+	Len       uint32
+	ByteOrder uint32
+	Major     uint16
+	Minor     uint16
+	Section   int64
+	TrailLen  uint32
+}
+
+type interfaceBlock struct {
+	Type     uint32
+	Len      uint32
+	LinkType uint16
+	Reserved uint16
+	SnapLen  uint32
+	TrailLen uint32
+}
+
+type packetBlock struct {
+	Type      uint32
+	Len       uint32
+	Interface uint32
+	TimeHigh  uint32
+	TimeLow   uint32
+	Captured  uint32
+	Original  uint32
+}
+
+// Writer writes packets to a capture file, each with a single call to the
+// underlying writer. Timestamps have microsecond resolution.
+type Writer struct {
+	// Now returns the timestamp of the packets written through an
+	// Encoder, time.Now if nil.
+	Now func() time.Time
+
+	w   io.Writer
+	ng  bool
+	buf bytes.Buffer
+	out bytes.Buffer
+	enc *binencoder.Encoder
+}
+
+// NewWriter writes the header of a pcap file with packets of linkType to
+// w and returns a Writer adding packets to it.
+func NewWriter(w io.Writer, linkType uint32) (*Writer, error) {
+	pw := newWriter(w, false)
+	return pw, pw.write(fileHeader{
+		Magic:    pcapMagic,
+		Major:    pcapMajor,
+		Minor:    pcapMinor,
+		SnapLen:  SnapLen,
+		LinkType: linkType,
+	})
+}
+
+// NewNGWriter is like NewWriter for the pcapng format: it writes a section
+// header block and an interface description block for linkType, and every
+// packet as an enhanced packet block.
+func NewNGWriter(w io.Writer, linkType uint16) (*Writer, error) {
+	pw := newWriter(w, true)
+	pw.enc.Encode(sectionHeader{
+		Type:      ngSection,
+		Len:       ngHeaderLen,
+		ByteOrder: ngByteOrder,
+		Major:     ngMajor,
+		Section:   ngNoSection,
+		TrailLen:  ngHeaderLen,
+	}, 0)
+	return pw, pw.write(interfaceBlock{
+		Type:     ngInterface,
+		Len:      ngIfaceLen,
+		LinkType: linkType,
+		SnapLen:  SnapLen,
+		TrailLen: ngIfaceLen,
+	})
+}
+
+func newWriter(w io.Writer, ng bool) *Writer {
+	pw := &Writer{w: w, ng: ng}
+	pw.enc = binencoder.NewEncoder(&pw.out, binencoder.WithByteOrder(binary.LittleEndian))
+	return pw
+}
+
+// write encodes v after what is in out and writes out to w.
+func (pw *Writer) write(v interface{}) error {
+	defer pw.out.Reset()
+	if err := pw.enc.Encode(v, 0); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(pw.out.Bytes())
+	return err
+}
+
+// WritePacket writes a packet with the given timestamp and data.
+func (pw *Writer) WritePacket(ts time.Time, data []byte) error {
+	defer pw.out.Reset()
+	captured := data
+	if len(captured) > SnapLen {
+		captured = captured[:SnapLen]
+	}
+	micros := ts.UnixNano() / (1e9 / microsPerSec)
+	var err error
+	if pw.ng {
+		padded := (len(captured) + ngDataAlign - 1) &^ (ngDataAlign - 1)
+		blockLen := uint32(ngPacketLen + padded)
+		err = pw.enc.Encode(packetBlock{
+			Type:     ngPacket,
+			Len:      blockLen,
+			TimeHigh: uint32(micros >> 32),
+			TimeLow:  uint32(micros),
+			Captured: uint32(len(captured)),
+			Original: uint32(len(data)),
+		}, 0)
+		pw.out.Write(captured)
+		pw.out.Write(make([]byte, padded-len(captured)))
+		if err == nil {
+			err = pw.enc.Encode(blockLen, 0)
+		}
+	} else {
+		err = pw.enc.Encode(recordHeader{
+			Sec:      uint32(micros / microsPerSec),
+			Micros:   uint32(micros % microsPerSec),
+			Captured: uint32(len(captured)),
+			Original: uint32(len(data)),
+		}, 0)
+		pw.out.Write(captured)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = pw.w.Write(pw.out.Bytes())
+	return err
+}
+
+func (pw *Writer) Write(p []byte) (int, error) {
+	return pw.buf.Write(p)
+}
+
+// EndFrame writes the data written since the last packet as a packet
+// stamped with Now.
+func (pw *Writer) EndFrame() error {
+	defer pw.buf.Reset()
+	now := time.Now
+	if pw.Now != nil {
+		now = pw.Now
+	}
+	return pw.WritePacket(now(), pw.buf.Bytes())
+}
+
+// AbortFrame discards the data written since the last packet.
+func (pw *Writer) AbortFrame() {
+	pw.buf.Reset()
+}
@@ -0,0 +1,64 @@
+package pcap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+	"github.com/milQA/binencoder/pcap"
+)
+
+var stamp = time.Unix(1600000000, 123456000)
+
+func TestWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := pcap.NewWriter(buf, pcap.LinkTypeUser0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Now = func() time.Time { return stamp }
+	if err := binencoder.NewEncoder(w, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)).Encode(uint16(0x0102), 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xd4, 0xc3, 0xb2, 0xa1, 2, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 0, 147, 0, 0, 0,
+		0x00, 0x10, 0x5e, 0x5f, 0x40, 0xe2, 0x01, 0, 2, 0, 0, 0, 2, 0, 0, 0,
+		1, 2,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got\n% x\nwant\n% x", buf.Bytes(), want)
+	}
+}
+
+func TestNGWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := pcap.NewNGWriter(buf, pcap.LinkTypeUser0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePacket(stamp, []byte{1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if len(b) != 28+20+40 {
+		t.Fatalf("got %d bytes, want %d", len(b), 28+20+40)
+	}
+	// Every block starts and ends with its length.
+	for off := 0; off < len(b); {
+		n := int(binary.LittleEndian.Uint32(b[off+4:]))
+		if end := binary.LittleEndian.Uint32(b[off+n-4:]); int(end) != n {
+			t.Fatalf("block at %d: trailing length %d, want %d", off, end, n)
+		}
+		off += n
+	}
+	packet := b[48:]
+	micros := uint64(binary.LittleEndian.Uint32(packet[12:]))<<32 | uint64(binary.LittleEndian.Uint32(packet[16:]))
+	if micros != uint64(stamp.UnixNano()/1000) {
+		t.Errorf("got timestamp %d", micros)
+	}
+	if !bytes.Equal(packet[28:36], []byte{1, 2, 3, 4, 5, 0, 0, 0}) {
+		t.Errorf("got data % x", packet[28:36])
+	}
+}
@@ -0,0 +1,337 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// inheritLen marks a field without its own length, which takes the length
+// of its parent.
+const inheritLen = -2
+
+// fieldPlan holds the tags of a struct field resolved once per type.
+type fieldPlan struct {
+	index int
+	name  string
+	len   int
+	order binary.ByteOrder
+	ratio float64
+	tags  fieldTags
+	// sizeFrom is the index of the field holding the field's size, or -1.
+	sizeFrom int
+	// sizeTo is the compressed field whose size the field holds, or nil.
+	sizeTo *fieldPlan
+	// lenFrom is the index of the field holding the field's length, named
+	// by its `len` tag, or -1.
+	lenFrom int
+	// compress is the value of the field's `compress` tag.
+	compress string
+	// transform names the registered transform of the field, see
+	// RegisterTransform.
+	transform string
+	// tlv is the tag the field is encoded with as a TLV item, or -1.
+	tlv int64
+	// klv is the universal key the field is encoded with as a KLV item.
+	klv []byte
+	// validate holds the rules of the field's `validate` tag, or nil.
+	validate *validation
+	// enum holds the values the field is restricted to, or nil.
+	enum *enum
+	// minVer and maxVer bound the protocol revisions with the field, 0 if
+	// unbounded.
+	minVer, maxVer int
+	// optional fields may be missing from the end of decoded input.
+	optional bool
+	// afterOptional marks a required field following optional ones, see
+	// config.fieldErr.
+	afterOptional bool
+	// nullable fields, optional ones and pointers, have a bit in the
+	// presence bitmap of their struct, see WithPresence.
+	nullable bool
+	// until ends a slice field without a count, or is nil.
+	until *until
+	// embed is the value of the `embed` tag of struct fields.
+	embed string
+	// offset is the position of the field from the start of the message,
+	// or -1.
+	offset int
+	// flagBits holds the indices of the bool fields packed into the
+	// flagWidth-bit bitmask the field starts, or nil; flagged fields are
+	// packed into the bitmask of an earlier field.
+	flagBits  []int
+	flagWidth int
+	flagged   bool
+	// err reports invalid tags when the field is encoded or decoded.
+	err error
+}
+
+// parseVersionTag parses a `minver` or `maxver` tag, returning 0 for an
+// empty tag.
+func parseVersionTag(tag string) (int, error) {
+	if tag == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(tag)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("binencoder: invalid version tag %q", tag)
+	}
+	return n, nil
+}
+
+// present reports whether field f is part of the revision selected with
+// WithVersion. Fields with invalid tags are, so that their errors surface.
+func (c *config) present(f *fieldPlan) bool {
+	if c.version == 0 || f.err != nil {
+		return true
+	}
+	return (f.minVer == 0 || c.version >= f.minVer) && (f.maxVer == 0 || c.version <= f.maxVer)
+}
+
+// fieldErr returns the error of field f: its invalid tags or, unless
+// presence bitmaps free optional fields from having to come last, a
+// required field following optional ones.
+func (c *config) fieldErr(f *fieldPlan) error {
+	if f.err == nil && f.afterOptional && !c.presence {
+		return fmt.Errorf("binencoder: field %s follows optional fields", f.name)
+	}
+	return f.err
+}
+
+// check checks v, the value of the field, against its `validate` tag and
+// enum.
+func (f *fieldPlan) check(v reflect.Value) error {
+	if f.validate != nil {
+		if err := f.validate.check(v); err != nil {
+			return err
+		}
+	}
+	if f.enum != nil {
+		return f.enum.check(v)
+	}
+	return nil
+}
+
+// fieldLen returns the length the field is encoded with inside a parent
+// of length bytesLen.
+func (f *fieldPlan) fieldLen(bytesLen int) int {
+	if f.len == inheritLen {
+		return bytesLen
+	}
+	return f.len
+}
+
+type planKey struct {
+	t       reflect.Type
+	tagName string
+}
+
+var (
+	plansMu sync.RWMutex
+	plans   = map[planKey][]fieldPlan{}
+)
+
+// structPlan returns the plans of the exported fields of struct type t,
+// building them on first use.
+func (c *config) structPlan(t reflect.Type) []fieldPlan {
+	key := planKey{t: t, tagName: c.tagName}
+	plansMu.RLock()
+	plan, ok := plans[key]
+	plansMu.RUnlock()
+	if ok {
+		return plan
+	}
+
+	plan = make([]fieldPlan, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		f := fieldPlan{
+			index: i,
+			name:  field.Name,
+			len:   decodeTags(c.tag(field, "len"), inheritLen),
+			tags:  c.parseFieldTags(field),
+
+			transform: c.tag(field, "transform"),
+
+			sizeFrom: -1,
+			lenFrom:  -1,
+			tlv:      -1,
+			offset:   -1,
+		}
+		f.err = c.checkNamespace(field)
+		if f.err == nil {
+			f.order, f.err = c.fieldOrder(field)
+		}
+		if f.err == nil {
+			f.tlv, f.err = parseTLVTag(c.tag(field, "tlv"))
+		}
+		if f.err == nil {
+			f.klv, f.err = parseKLVKey(c.tag(field, "klv"))
+		}
+		if f.err == nil {
+			f.validate, f.err = parseValidateTag(c.tag(field, "validate"))
+		}
+		if f.err == nil {
+			f.enum, f.err = fieldEnum(field.Type, c.tag(field, "enum"))
+		}
+		if f.err == nil {
+			f.minVer, f.err = parseVersionTag(c.tag(field, "minver"))
+		}
+		if f.err == nil {
+			f.maxVer, f.err = parseVersionTag(c.tag(field, "maxver"))
+		}
+		if tag := c.tag(field, "optional"); tag != "" && f.err == nil {
+			if f.optional, f.err = strconv.ParseBool(tag); f.err != nil {
+				f.err = fmt.Errorf("binencoder: invalid optional tag %q", tag)
+			}
+		}
+		if f.err == nil {
+			f.compress, f.err = parseCompressTag(c.tag(field, "compress"), field.Type)
+		}
+		if f.err == nil {
+			f.offset, f.err = parseOffsetTag(c.tag(field, "offset"))
+		}
+		if f.err == nil {
+			f.until, f.err = parseUntilTag(c.tag(field, "until"), field.Type)
+		}
+		if f.err == nil {
+			f.embed, f.err = parseEmbedTag(c.tag(field, "embed"), field.Type)
+			if f.embed == "skip" {
+				f.len = -1
+			}
+		}
+		f.nullable = f.optional || field.Type.Kind() == reflect.Ptr
+		if unitTag := c.tag(field, "unit"); unitTag != "" && f.len != -1 && f.err == nil {
+			f.ratio, f.err = parseUnitTag(unitTag)
+		}
+		plan = append(plan, f)
+	}
+	c.orderPlan(t, plan)
+	c.groupFlags(t, plan)
+	for i := 1; i < len(plan); i++ {
+		f, prev := &plan[i], &plan[i-1]
+		if prev.tlv >= 0 && f.tlv < 0 && f.err == nil {
+			f.err = fmt.Errorf("binencoder: field %s follows TLV fields", f.name)
+		}
+		f.afterOptional = prev.optional && !f.optional
+		if prev.until != nil && prev.until.eof && f.err == nil {
+			f.err = fmt.Errorf("binencoder: field %s follows a field read until eof", f.name)
+		}
+	}
+	for i := range plan {
+		field := t.Field(plan[i].index)
+		if target := c.tag(field, "sizeof"); target != "" {
+			linkSize(t, plan, i, target)
+		}
+	}
+	for i := range plan {
+		field := t.Field(plan[i].index)
+		if from := c.tag(field, "len"); isFieldName(from) && plan[i].err == nil {
+			linkLen(t, plan, i, from)
+		}
+	}
+	for i := range plan {
+		if f := &plan[i]; f.compress != "" && f.sizeFrom < 0 && f.err == nil {
+			f.err = fmt.Errorf("binencoder: compressed field %s needs a sizeof field", f.name)
+		}
+	}
+
+	plansMu.Lock()
+	plans[key] = plan
+	plansMu.Unlock()
+	return plan
+}
+
+// orderPlan sorts plan by the `order` tags of the fields of t, if any. Every
+// encoded field must then have a distinct one.
+func (c *config) orderPlan(t reflect.Type, plan []fieldPlan) {
+	keys := make(map[int]int, len(plan))
+	seen := make(map[int]string, len(plan))
+	for i := range plan {
+		f := &plan[i]
+		tag := c.tag(t.Field(f.index), "order")
+		if tag == "" {
+			continue
+		}
+		n, err := strconv.Atoi(tag)
+		switch {
+		case err != nil:
+			f.err = fmt.Errorf("binencoder: invalid order tag %q", tag)
+		case seen[n] != "":
+			f.err = fmt.Errorf("binencoder: field %s has the order of %s", f.name, seen[n])
+		default:
+			seen[n] = f.name
+		}
+		keys[f.index] = n
+	}
+	if len(keys) == 0 {
+		return
+	}
+	for i := range plan {
+		f := &plan[i]
+		if _, ok := keys[f.index]; !ok && f.len != -1 && f.err == nil {
+			f.err = fmt.Errorf("binencoder: field %s has no order tag", f.name)
+		}
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		return keys[plan[i].index] < keys[plan[j].index]
+	})
+}
+
+// isFieldName reports whether a `len` tag names a field rather than
+// giving a length.
+func isFieldName(tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+	_, err := strconv.Atoi(tag)
+	return err != nil
+}
+
+// linkLen makes plan[to] take its length from the field named from, an
+// integer field that must precede it so that decoding reads it first.
+func linkLen(t reflect.Type, plan []fieldPlan, to int, from string) {
+	for i := range plan {
+		if plan[i].name != from {
+			continue
+		}
+		switch {
+		case i > to:
+			plan[to].err = fmt.Errorf("binencoder: len field %s must precede %s", from, plan[to].name)
+		case !isVarintKind(t.Field(plan[i].index).Type.Kind()):
+			plan[to].err = fmt.Errorf("binencoder: len field %s is not an integer", from)
+		default:
+			plan[to].lenFrom = plan[i].index
+		}
+		return
+	}
+	plan[to].err = fmt.Errorf("binencoder: len names unknown field %s", from)
+}
+
+// linkSize makes the field named target take its size from plan[from].
+func linkSize(t reflect.Type, plan []fieldPlan, from int, target string) {
+	for i := range plan {
+		if plan[i].name != target {
+			continue
+		}
+		switch {
+		case t.Field(plan[i].index).Type != readerType && plan[i].compress == "":
+			plan[from].err = fmt.Errorf("binencoder: sizeof field %s is not an io.Reader or compressed", target)
+		case i < from:
+			plan[from].err = fmt.Errorf("binencoder: sizeof field must precede %s", target)
+		default:
+			plan[i].sizeFrom = plan[from].index
+			if plan[i].compress != "" {
+				plan[from].sizeTo = &plan[i]
+			}
+		}
+		return
+	}
+	plan[from].err = fmt.Errorf("binencoder: sizeof names unknown field %s", target)
+}
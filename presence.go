@@ -0,0 +1,86 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithPresence makes every struct with nullable fields, pointers and
+// fields tagged `optional:"true"`, start with a presence bitmap, so that
+// sparse records do not pay for absent fields. The bitmap has a bit per
+// nullable field in field order, least significant bit first, set if the
+// field follows: a non-nil pointer or a non-zero optional value. Absent
+// fields are decoded as nil or zero. Optional fields may then come
+// anywhere in the struct.
+func WithPresence(enabled bool) Option {
+	return func(c *config) {
+		c.presence = enabled
+	}
+}
+
+// presenceLen returns the size of the presence bitmap of plan in bytes, 0
+// if it has none.
+func (c *config) presenceLen(plan []fieldPlan) int {
+	if !c.presence {
+		return 0
+	}
+	n := 0
+	for i := range plan {
+		if f := &plan[i]; f.nullable && c.present(f) {
+			n++
+		}
+	}
+	return (n + 7) / 8
+}
+
+// encodePresence writes the presence bitmap of struct v and returns it, or
+// nil if v has none.
+func (enc *Encoder) encodePresence(v reflect.Value, plan []fieldPlan, path int) ([]byte, error) {
+	size := enc.presenceLen(plan)
+	if size == 0 {
+		return nil, nil
+	}
+	bitmap := make([]byte, size)
+	bit := 0
+	for i := range plan {
+		f := &plan[i]
+		if !f.nullable || !enc.present(f) {
+			continue
+		}
+		if !v.Field(f.index).IsZero() {
+			bitmap[bit/8] |= 1 << uint(bit%8)
+		}
+		bit++
+	}
+	if err := enc.write(bitmap); err != nil {
+		return nil, enc.fail(path, v.Type(), err)
+	}
+	return bitmap, nil
+}
+
+// decodePresence is the inverse of Encoder.encodePresence.
+func (dec *Decoder) decodePresence(v reflect.Value, plan []fieldPlan, path string) ([]byte, error) {
+	size := dec.presenceLen(plan)
+	if size == 0 {
+		return nil, nil
+	}
+	bitmap := make([]byte, size)
+	if err := dec.readFull(bitmap); err != nil {
+		return nil, newDecodeError(path, v.Type(), err)
+	}
+	n := 0
+	for i := range plan {
+		if f := &plan[i]; f.nullable && dec.present(f) {
+			n++
+		}
+	}
+	if n%8 != 0 && bitmap[size-1]>>uint(n%8) != 0 {
+		return nil, newDecodeError(path, v.Type(), fmt.Errorf("%w: presence bitmap has bits beyond the %d nullable fields", ErrInvalidValue, n))
+	}
+	return bitmap, nil
+}
+
+// absent reports whether the bit-th nullable field is missing from bitmap.
+func absent(bitmap []byte, bit int) bool {
+	return bitmap[bit/8]&(1<<uint(bit%8)) == 0
+}
@@ -0,0 +1,60 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestPresence(t *testing.T) {
+	type gps struct {
+		Lat, Lon int32
+	}
+	type telemetry struct {
+		ID       uint16
+		Temp     int16 `optional:"true"`
+		Position *gps
+		Humidity uint8 `optional:"true"`
+		Battery  uint8
+	}
+	opts := []binencoder.Option{binencoder.WithPresence(true)}
+	in := telemetry{ID: 7, Humidity: 40, Battery: 99}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, opts...).Encode(&in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x04, 7, 0, 40, 99})
+
+	out := telemetry{Temp: -1, Position: &gps{1, 2}}
+	if err := binencoder.NewDecoder(bytes.NewReader(buf.Bytes()), opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	in = telemetry{ID: 7, Temp: -2, Position: &gps{10, -10}, Battery: 99}
+	buf.Reset()
+	if err := binencoder.NewEncoder(buf, opts...).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x03, 7, 0, 0xfe, 0xff, 10, 0, 0, 0, 0xf6, 0xff, 0xff, 0xff, 99})
+	out = telemetry{}
+	if err := binencoder.NewDecoder(bytes.NewReader(buf.Bytes()), opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Temp != -2 || out.Position == nil || *out.Position != *in.Position || out.Humidity != 0 || out.Battery != 99 {
+		t.Errorf("got %+v", out)
+	}
+
+	err := binencoder.NewDecoder(bytes.NewReader([]byte{0x08, 7, 0, 99}), opts...).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	// Without presence bitmaps optional fields must come last.
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err == nil {
+		t.Error("expected an error for a field following optional fields")
+	}
+}
@@ -0,0 +1,39 @@
+package binencoder
+
+// ProgressFunc receives the progress of an Encode or Decode call: the
+// bytes of the message written or read so far and the path of the value
+// about to be encoded or decoded, empty for the message itself.
+type ProgressFunc func(n int, path string)
+
+// WithProgress calls fn whenever another every bytes of a message have
+// been written or read, before the next value, so that tools exporting
+// large datasets can show progress and estimate completion. Values written
+// or read in one piece, e.g. arrays of numbers, []byte slices or io.Reader
+// fields, are reported after them. An every of 0 reports before every
+// value. fn runs in the Encode or Decode call and should return quickly.
+func WithProgress(every int, fn ProgressFunc) Option {
+	return func(c *config) {
+		c.progress = fn
+		c.progressEvery = every
+	}
+}
+
+// reportProgress calls the ProgressFunc if enough bytes were written since
+// its last call.
+func (enc *Encoder) reportProgress(path int) {
+	if enc.n-enc.reported < enc.progressEvery {
+		return
+	}
+	enc.reported = enc.n
+	enc.progress(enc.n, string(enc.pathBuf[:path]))
+}
+
+// reportProgress calls the ProgressFunc if enough bytes were read since
+// its last call.
+func (dec *Decoder) reportProgress(path string) {
+	if dec.n-dec.reported < dec.progressEvery {
+		return
+	}
+	dec.reported = dec.n
+	dec.progress(dec.n, path)
+}
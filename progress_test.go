@@ -0,0 +1,43 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestProgress(t *testing.T) {
+	type row struct {
+		ID   uint32
+		Name string `len:"12"`
+	}
+	type export struct {
+		Rows [4]row
+	}
+	type report struct {
+		n    int
+		path string
+	}
+	var got []report
+	progress := binencoder.WithProgress(32, func(n int, path string) {
+		got = append(got, report{n, path})
+	})
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, progress).Encode(export{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []report{{32, "Rows[2]"}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("encoding reported %v, want %v", got, want)
+	}
+
+	got = nil
+	var out export
+	if err := binencoder.NewDecoder(buf, progress).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("decoding reported %v, want %v", got, want)
+	}
+}
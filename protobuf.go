@@ -0,0 +1,212 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Protocol buffers wire types.
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+	pbFixed32 = 5
+)
+
+// pbField is a parsed `pbf` tag: the field number and the integer
+// encoding, "" for varint, "zigzag" or "fixed".
+type pbField struct {
+	num      uint64
+	encoding string
+}
+
+func parsePBTag(tag string) (pbField, error) {
+	num, encoding := tag, ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		num, encoding = tag[:i], tag[i+1:]
+	}
+	n, err := strconv.ParseUint(num, 10, 29)
+	if err != nil || n == 0 {
+		return pbField{}, fmt.Errorf("binencoder: invalid pbf field number %q", num)
+	}
+	switch encoding {
+	case "", "zigzag", "fixed":
+	default:
+		return pbField{}, fmt.Errorf("binencoder: unknown pbf encoding %q", encoding)
+	}
+	return pbField{num: n, encoding: encoding}, nil
+}
+
+// appendProto appends the fields of struct v tagged with `pbf` as a
+// protocol buffers message.
+func (enc *Encoder) appendProto(b []byte, v reflect.Value, path string) ([]byte, error) {
+	w, err := enc.formatValue(v, fieldTags{})
+	if err != nil {
+		return nil, newEncodeError(path, v.Type(), err)
+	}
+	if w.Kind() != reflect.Struct {
+		return nil, newEncodeError(path, v.Type(), fmt.Errorf("%w: protobuf messages must be structs", ErrUnknownType))
+	}
+	fields, err := enc.formatFields(w, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		tag := enc.tag(f.field, "pbf")
+		if tag == "" {
+			continue
+		}
+		pf, err := parsePBTag(tag)
+		if err != nil {
+			return nil, newEncodeError(f.path, f.field.Type, err)
+		}
+		if b, err = enc.appendProtoField(b, f, pf); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendProtoField appends a field, omitting nil pointers and, for fields
+// that are not pointers, zero values. Slices of scalars are packed.
+func (enc *Encoder) appendProtoField(b []byte, f formatField, pf pbField) ([]byte, error) {
+	optional := f.value.Kind() == reflect.Ptr
+	v, err := enc.formatValue(f.value, f.plan.tags)
+	if err != nil {
+		return nil, newEncodeError(f.path, f.field.Type, err)
+	}
+	if !v.IsValid() || !optional && v.IsZero() {
+		return b, nil
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !isBytes(v) {
+		elem := v.Type().Elem()
+		if _, ok := pbScalarType(elem, pf); ok {
+			var packed []byte
+			for i := 0; i < v.Len(); i++ {
+				if packed, err = appendProtoScalar(packed, v.Index(i), pf); err != nil {
+					return nil, newEncodeError(f.path+"["+strconv.Itoa(i)+"]", elem, err)
+				}
+			}
+			b = appendProtoKey(b, pf.num, pbBytes)
+			b = appendUvarint(b, uint64(len(packed)))
+			return append(b, packed...), nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			item := f
+			item.value = v.Index(i)
+			item.path = f.path + "[" + strconv.Itoa(i) + "]"
+			if b, err = enc.appendProtoItem(b, item, pf); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	f.value = v
+	return enc.appendProtoItem(b, f, pf)
+}
+
+// appendProtoItem appends a single value of a field with its key.
+func (enc *Encoder) appendProtoItem(b []byte, f formatField, pf pbField) ([]byte, error) {
+	v, err := enc.formatValue(f.value, f.plan.tags)
+	if err != nil {
+		return nil, newEncodeError(f.path, f.value.Type(), err)
+	}
+	if !v.IsValid() {
+		return b, nil
+	}
+	if wire, ok := pbScalarType(v.Type(), pf); ok {
+		b = appendProtoKey(b, pf.num, wire)
+		b, err = appendProtoScalar(b, v, pf)
+		if err != nil {
+			return nil, newEncodeError(f.path, v.Type(), err)
+		}
+		return b, nil
+	}
+	var content []byte
+	switch {
+	case isBytes(v):
+		content = bytesOf(v)
+	case v.Kind() == reflect.Struct:
+		if content, err = enc.appendProto(nil, v, f.path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, newEncodeError(f.path, v.Type(), fmt.Errorf("%w: %s in protobuf", ErrUnknownType, v.Type()))
+	}
+	b = appendProtoKey(b, pf.num, pbBytes)
+	b = appendUvarint(b, uint64(len(content)))
+	return append(b, content...), nil
+}
+
+// pbScalarType returns the wire type values of type t are written with, if
+// t is a scalar type.
+func pbScalarType(t reflect.Type, pf pbField) (int, bool) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return pbVarint, true
+	case reflect.Float32:
+		return pbFixed32, true
+	case reflect.Float64:
+		return pbFixed64, true
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		if pf.encoding == "fixed" {
+			return pbFixed32, true
+		}
+		return pbVarint, true
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		if pf.encoding == "fixed" {
+			return pbFixed64, true
+		}
+		return pbVarint, true
+	}
+	return 0, false
+}
+
+// appendProtoScalar appends the value of a scalar without its key.
+func appendProtoScalar(b []byte, v reflect.Value, pf pbField) ([]byte, error) {
+	wire, _ := pbScalarType(v.Type(), pf)
+	var x uint64
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			x = 1
+		}
+	case v.Kind() == reflect.Float32:
+		x = uint64(math.Float32bits(float32(v.Float())))
+	case v.Kind() == reflect.Float64:
+		x = math.Float64bits(v.Float())
+	case isInt(v.Kind()):
+		x = uint64(v.Int())
+		if pf.encoding == "zigzag" {
+			x = uint64(v.Int()<<1 ^ v.Int()>>63)
+		}
+	default:
+		if pf.encoding == "zigzag" {
+			return nil, fmt.Errorf("binencoder: zigzag encoding of unsigned %s", v.Type())
+		}
+		x = v.Uint()
+	}
+	var fixed [8]byte
+	switch wire {
+	case pbFixed32:
+		binary.LittleEndian.PutUint32(fixed[:], uint32(x))
+		return append(b, fixed[:4]...), nil
+	case pbFixed64:
+		binary.LittleEndian.PutUint64(fixed[:], x)
+		return append(b, fixed[:]...), nil
+	}
+	return appendUvarint(b, x), nil
+}
+
+func appendProtoKey(b []byte, num uint64, wire int) []byte {
+	return appendUvarint(b, num<<3|uint64(wire))
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var w [binary.MaxVarintLen64]byte
+	return append(b, w[:binary.PutUvarint(w[:], x)]...)
+}
@@ -0,0 +1,62 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestFormatProtobuf(t *testing.T) {
+	type item struct {
+		Name string `pbf:"1"`
+	}
+	type message struct {
+		ID     uint32   `pbf:"1"`
+		Delta  int32    `pbf:"2,zigzag"`
+		Neg    int64    `pbf:"3"`
+		Code   uint32   `pbf:"4,fixed"`
+		Name   string   `pbf:"5"`
+		Values []uint16 `pbf:"6"`
+		Items  []item   `pbf:"7"`
+		Zero   uint8    `pbf:"8"`
+		Opt    *bool    `pbf:"9"`
+		Skip   uint8
+		Ratio  float32 `pbf:"10"`
+	}
+	opt := false
+	in := message{
+		ID:     150,
+		Delta:  -2,
+		Neg:    -1,
+		Code:   1,
+		Name:   "ab",
+		Values: []uint16{1, 300},
+		Items:  []item{{"x"}},
+		Opt:    &opt,
+		Skip:   7,
+		Ratio:  1,
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatProtobuf)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x08, 0x96, 0x01,
+		0x10, 0x03,
+		0x18, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+		0x25, 0x01, 0x00, 0x00, 0x00,
+		0x2a, 0x02, 'a', 'b',
+		0x32, 0x03, 0x01, 0xac, 0x02,
+		0x3a, 0x03, 0x0a, 0x01, 'x',
+		0x48, 0x00,
+		0x55, 0x00, 0x00, 0x80, 0x3f,
+	})
+
+	err := binencoder.NewEncoder(buf, binencoder.WithFormat(binencoder.FormatProtobuf)).Encode(struct {
+		A uint8 `pbf:"0"`
+	}{1}, 0)
+	if err == nil {
+		t.Error("expected an error for field number 0")
+	}
+}
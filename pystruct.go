@@ -0,0 +1,273 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// pyItem is one item of a Python struct format string: a format character
+// and its count.
+type pyItem struct {
+	code  byte
+	count int
+}
+
+// parsePyFormat parses a Python struct format string such as "<HI10s". The
+// byte order character '<' selects little endian, '>' and '!' big endian,
+// and '=', '@' or none the native order; no alignment is applied.
+func parsePyFormat(format string) (binary.ByteOrder, []pyItem, error) {
+	order := nativeOrder
+	if format != "" {
+		switch format[0] {
+		case '<':
+			order, format = binary.LittleEndian, format[1:]
+		case '>', '!':
+			order, format = binary.BigEndian, format[1:]
+		case '=', '@':
+			format = format[1:]
+		}
+	}
+	var items []pyItem
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			continue
+		}
+		count, digits := 0, false
+		for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			count = count*10 + int(format[i]-'0')
+			digits = true
+		}
+		if i == len(format) {
+			return nil, nil, fmt.Errorf("binencoder: format %q ends with a count", format)
+		}
+		if !digits {
+			count = 1
+		}
+		c = format[i]
+		if pySize(c) == 0 {
+			return nil, nil, fmt.Errorf("binencoder: bad format character %q", c)
+		}
+		items = append(items, pyItem{code: c, count: count})
+	}
+	return order, items, nil
+}
+
+// pySize returns the size of a format character, 0 for unknown ones.
+func pySize(c byte) int {
+	switch c {
+	case 'x', 'c', 'b', 'B', '?', 's':
+		return 1
+	case 'h', 'H':
+		return 2
+	case 'i', 'I', 'l', 'L', 'f':
+		return 4
+	case 'q', 'Q', 'd':
+		return 8
+	}
+	return 0
+}
+
+// values returns the number of values an item packs.
+func (it pyItem) values() int {
+	switch it.code {
+	case 'x':
+		return 0
+	case 's':
+		return 1
+	}
+	return it.count
+}
+
+// Pack returns values packed according to a Python struct format string,
+// e.g. "<HI10s". Integers may be of any integer type and must fit their
+// format character; 's' takes a string or []byte padded with zeros or
+// truncated to its count, 'c' a byte, '?' a bool and 'f' and 'd' floats.
+// Pad bytes ('x') are zero.
+func Pack(format string, values ...interface{}) ([]byte, error) {
+	order, items, err := parsePyFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	want := 0
+	for _, it := range items {
+		want += it.values()
+	}
+	if len(values) != want {
+		return nil, fmt.Errorf("binencoder: format %q packs %d values, got %d", format, want, len(values))
+	}
+	var b []byte
+	var w [8]byte
+	for _, it := range items {
+		switch it.code {
+		case 'x':
+			b = append(b, make([]byte, it.count)...)
+			continue
+		case 's':
+			s, ok := pyBytes(values[0])
+			if !ok {
+				return nil, fmt.Errorf("binencoder: 's' needs a string or []byte, got %T", values[0])
+			}
+			field := make([]byte, it.count)
+			copy(field, s)
+			b = append(b, field...)
+			values = values[1:]
+			continue
+		}
+		for n := 0; n < it.count; n++ {
+			x, err := pyBits(it.code, values[0])
+			if err != nil {
+				return nil, err
+			}
+			size := pySize(it.code)
+			switch size {
+			case 1:
+				w[0] = byte(x)
+			case 2:
+				order.PutUint16(w[:], uint16(x))
+			case 4:
+				order.PutUint32(w[:], uint32(x))
+			case 8:
+				order.PutUint64(w[:], x)
+			}
+			b = append(b, w[:size]...)
+			values = values[1:]
+		}
+	}
+	return b, nil
+}
+
+// pyBits returns the bits v is packed with for format character c.
+func pyBits(c byte, v interface{}) (uint64, error) {
+	rv := reflect.ValueOf(v)
+	switch c {
+	case '?':
+		if rv.Kind() != reflect.Bool {
+			return 0, fmt.Errorf("binencoder: '?' needs a bool, got %T", v)
+		}
+		if rv.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case 'f', 'd':
+		if rv.Kind() != reflect.Float32 && rv.Kind() != reflect.Float64 {
+			return 0, fmt.Errorf("binencoder: %q needs a float, got %T", c, v)
+		}
+		if c == 'f' {
+			return uint64(math.Float32bits(float32(rv.Float()))), nil
+		}
+		return math.Float64bits(rv.Float()), nil
+	}
+	var lo, hi int64
+	var uhi uint64
+	switch c {
+	case 'b':
+		lo, hi = math.MinInt8, math.MaxInt8
+	case 'h':
+		lo, hi = math.MinInt16, math.MaxInt16
+	case 'i', 'l':
+		lo, hi = math.MinInt32, math.MaxInt32
+	case 'q':
+		lo, hi = math.MinInt64, math.MaxInt64
+	case 'B', 'c':
+		uhi = math.MaxUint8
+	case 'H':
+		uhi = math.MaxUint16
+	case 'I', 'L':
+		uhi = math.MaxUint32
+	case 'Q':
+		uhi = math.MaxUint64
+	}
+	switch {
+	case isInt(rv.Kind()):
+		x := rv.Int()
+		if uhi != 0 && (x < 0 || uint64(x) > uhi) || uhi == 0 && (x < lo || x > hi) {
+			return 0, fmt.Errorf("%w: %d does not fit %q", ErrOverflow, x, c)
+		}
+		return uint64(x), nil
+	case isUint(rv.Kind()):
+		x := rv.Uint()
+		if uhi != 0 && x > uhi || uhi == 0 && x > uint64(hi) {
+			return 0, fmt.Errorf("%w: %d does not fit %q", ErrOverflow, x, c)
+		}
+		return x, nil
+	}
+	return 0, fmt.Errorf("binencoder: %q needs an integer, got %T", c, v)
+}
+
+func pyBytes(v interface{}) ([]byte, bool) {
+	switch s := v.(type) {
+	case string:
+		return []byte(s), true
+	case []byte:
+		return s, true
+	}
+	return nil, false
+}
+
+// Unpack is the inverse of Pack. The values are typed after their format
+// characters: int8 for 'b', uint8 for 'B' and 'c', int16, uint16, int32
+// for 'i' and 'l', uint32, int64, uint64, bool, float32, float64 and []byte
+// for 's'. data must be exactly as long as the format.
+func Unpack(format string, data []byte) ([]interface{}, error) {
+	order, items, err := parsePyFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	size := 0
+	for _, it := range items {
+		size += pySize(it.code) * it.count
+	}
+	if len(data) < size {
+		return nil, fmt.Errorf("%w: format %q needs %d bytes, got %d", ErrShortMessage, format, size, len(data))
+	}
+	if len(data) > size {
+		return nil, fmt.Errorf("binencoder: format %q needs %d bytes, got %d", format, size, len(data))
+	}
+	var values []interface{}
+	for _, it := range items {
+		switch it.code {
+		case 'x':
+			data = data[it.count:]
+			continue
+		case 's':
+			values = append(values, append([]byte(nil), data[:it.count]...))
+			data = data[it.count:]
+			continue
+		}
+		for n := 0; n < it.count; n++ {
+			values = append(values, pyValue(it.code, data, order))
+			data = data[pySize(it.code):]
+		}
+	}
+	return values, nil
+}
+
+// pyValue decodes the value of format character c at the start of b.
+func pyValue(c byte, b []byte, order binary.ByteOrder) interface{} {
+	switch c {
+	case 'b':
+		return int8(b[0])
+	case 'B', 'c':
+		return b[0]
+	case '?':
+		return b[0] != 0
+	case 'h':
+		return int16(order.Uint16(b))
+	case 'H':
+		return order.Uint16(b)
+	case 'i', 'l':
+		return int32(order.Uint32(b))
+	case 'I', 'L':
+		return order.Uint32(b)
+	case 'q':
+		return int64(order.Uint64(b))
+	case 'Q':
+		return order.Uint64(b)
+	case 'f':
+		return math.Float32frombits(order.Uint32(b))
+	}
+	return math.Float64frombits(order.Uint64(b))
+}
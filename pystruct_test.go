@@ -0,0 +1,45 @@
+package binencoder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestPack(t *testing.T) {
+	b, err := binencoder.Pack("<HI4s?x2b", 0x0102, uint32(0x03040506), "ab", true, -1, int8(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0x02, 0x01, 0x06, 0x05, 0x04, 0x03, 'a', 'b', 0, 0, 1, 0, 0xff, 0x02})
+
+	values, err := binencoder.Unpack("<HI4s?x2b", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{uint16(0x0102), uint32(0x03040506), []byte("ab\x00\x00"), true, int8(-1), int8(2)}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("We have:\n%v\n got:\n%v\n", want, values)
+	}
+
+	b, err = binencoder.Pack("!hd", -2, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0xff, 0xfe, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0})
+
+	if _, err := binencoder.Pack(">B", 256); !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	if _, err := binencoder.Pack(">H", 1, 2); err == nil {
+		t.Error("expected an error for too many values")
+	}
+	if _, err := binencoder.Pack(">z", 1); err == nil {
+		t.Error("expected an error for a bad format character")
+	}
+	if _, err := binencoder.Unpack(">I", []byte{1, 2}); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package binencoder_test
+
+// raceEnabled reports whether the tests run with the race detector, which
+// makes allocation counts unreliable.
+const raceEnabled = true
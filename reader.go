@@ -0,0 +1,70 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// encodeReader copies exactly n bytes from the io.Reader in v.
+func (enc *Encoder) encodeReader(v reflect.Value, n int, path int) error {
+	if n == 0 {
+		return enc.fail(path, v.Type(), errUnknownLength)
+	}
+	var r io.Reader = bytes.NewReader(nil)
+	if !v.IsNil() {
+		r = v.Interface().(io.Reader)
+	}
+	copied, err := io.CopyN(encoderWriter{enc}, r, int64(n))
+	if err == io.EOF {
+		err = fmt.Errorf("%w: reader has %d of %d bytes", ErrShortMessage, copied, n)
+	}
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeReader reads n bytes and sets v to a reader over them.
+func (dec *Decoder) decodeReader(v reflect.Value, n int, path string) error {
+	if n == 0 {
+		return newDecodeError(path, v.Type(), errUnknownLength)
+	}
+	if err := dec.checkLen(n, reflect.Slice); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	b := make([]byte, n)
+	if err := dec.readFull(b); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	v.Set(reflect.ValueOf(bytes.NewReader(b)))
+	return nil
+}
+
+// encoderWriter writes to the Encoder's stream, counting the bytes written.
+type encoderWriter struct {
+	enc *Encoder
+}
+
+func (w encoderWriter) Write(p []byte) (int, error) {
+	n := w.enc.n
+	err := w.enc.write(p)
+	return w.enc.n - n, err
+}
+
+// sizeValue returns the value of a `sizeof` field.
+func sizeValue(v reflect.Value) (int, error) {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint()), nil
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("binencoder: negative size %d", v.Int())
+		}
+		return int(v.Int()), nil
+	}
+	return 0, fmt.Errorf("%w: %s cannot hold a size", ErrUnknownType, v.Type())
+}
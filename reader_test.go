@@ -0,0 +1,72 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type upload struct {
+	Kind    uint8
+	Size    uint16 `sizeof:"Payload"`
+	Payload io.Reader
+	Trailer io.Reader `len:"2"`
+}
+
+func TestReaderFields(t *testing.T) {
+	in := upload{
+		Kind:    1,
+		Size:    5,
+		Payload: strings.NewReader("hello, world"),
+		Trailer: strings.NewReader("ok"),
+	}
+	buf := new(bytes.Buffer)
+	n, err := binencoder.NewEncoder(buf).EncodeN(in, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{1, 5, 0, 'h', 'e', 'l', 'l', 'o', 'o', 'k'}
+	equalByte(t, buf.Bytes(), want)
+	if n != len(want) {
+		t.Errorf("EncodeN reported %d bytes", n)
+	}
+
+	var out upload
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := ioutil.ReadAll(out.Payload)
+	trailer, _ := ioutil.ReadAll(out.Trailer)
+	if out.Size != 5 || string(payload) != "hello" || string(trailer) != "ok" {
+		t.Errorf("got %+v, %q, %q", out, payload, trailer)
+	}
+}
+
+func TestReaderFieldErrors(t *testing.T) {
+	in := upload{Size: 10, Payload: strings.NewReader("short"), Trailer: strings.NewReader("ok")}
+	err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(in, 0)
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("short reader: got %v", err)
+	}
+
+	var after struct {
+		Payload io.Reader `len:"1"`
+		Size    uint8     `sizeof:"Payload"`
+	}
+	after.Payload = strings.NewReader("x")
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(after, 0); err == nil || !strings.Contains(err.Error(), "precede") {
+		t.Error("expected an error for a sizeof field after its target")
+	}
+	var notReader struct {
+		Size uint8 `sizeof:"Name"`
+		Name string
+	}
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(notReader, 0); err == nil {
+		t.Error("expected an error for a sizeof field naming a string")
+	}
+}
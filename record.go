@@ -0,0 +1,250 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"reflect"
+)
+
+// RecordHeader precedes each record of a record file. CRC is the IEEE
+// CRC-32 of the record's Length bytes.
+type RecordHeader struct {
+	Length uint32
+	Type   uint32
+	CRC    uint32
+}
+
+// recordTrailer ends a record file with the position of its index, the
+// offsets of the records as uint64s.
+type recordTrailer struct {
+	IndexOffset uint64
+	Count       uint32
+	Magic       [4]byte
+}
+
+const (
+	recordHeaderSize  = 12
+	recordTrailerSize = 16
+)
+
+var (
+	recordMagic      = [4]byte{'B', 'R', 'E', 'C'}
+	recordIndexMagic = [4]byte{'B', 'I', 'D', 'X'}
+)
+
+// RecordWriter writes a record file, a simple durable log: the magic
+// "BREC", then each record as a RecordHeader followed by the encoded
+// message, and on Close an index of the record offsets for random access
+// by record number, ended by its position, the number of records and the
+// magic "BIDX".
+type RecordWriter struct {
+	w       io.Writer
+	hdr     *Encoder
+	enc     *Encoder
+	body    bytes.Buffer
+	offsets []uint64
+	pos     uint64
+}
+
+// NewRecordWriter returns a RecordWriter writing to w. Headers and records
+// are encoded with opts.
+func NewRecordWriter(w io.Writer, opts ...Option) *RecordWriter {
+	return &RecordWriter{w: w, hdr: NewEncoder(w, opts...), enc: NewEncoder(nil, opts...)}
+}
+
+// Append encodes v as the next record with the given type ID and returns
+// its number, counted from 0.
+func (rw *RecordWriter) Append(typeID uint32, v interface{}) (int, error) {
+	if err := rw.start(); err != nil {
+		return 0, err
+	}
+	n := len(rw.offsets)
+	rw.body.Reset()
+	rw.enc.Reset(&rw.body)
+	if err := rw.enc.Encode(v, 0); err != nil {
+		return n, fmt.Errorf("binencoder: record %d: %w", n, err)
+	}
+	if rw.body.Len() > math.MaxUint32 {
+		return n, fmt.Errorf("%w: record %d of %d bytes", ErrOverflow, n, rw.body.Len())
+	}
+	h := RecordHeader{Length: uint32(rw.body.Len()), Type: typeID, CRC: crc32.ChecksumIEEE(rw.body.Bytes())}
+	if err := rw.hdr.Encode(h, 0); err != nil {
+		return n, err
+	}
+	if _, err := rw.w.Write(rw.body.Bytes()); err != nil {
+		return n, err
+	}
+	rw.offsets = append(rw.offsets, rw.pos)
+	rw.pos += recordHeaderSize + uint64(h.Length)
+	return n, nil
+}
+
+// Close writes the index. It does not close the underlying writer.
+func (rw *RecordWriter) Close() error {
+	if err := rw.start(); err != nil {
+		return err
+	}
+	if len(rw.offsets) > math.MaxUint32 {
+		return fmt.Errorf("%w: %d records", ErrOverflow, len(rw.offsets))
+	}
+	if err := rw.hdr.Encode(rw.offsets, 0); err != nil {
+		return err
+	}
+	return rw.hdr.Encode(recordTrailer{IndexOffset: rw.pos, Count: uint32(len(rw.offsets)), Magic: recordIndexMagic}, 0)
+}
+
+// start writes the file magic before the first record.
+func (rw *RecordWriter) start() error {
+	if rw.pos != 0 {
+		return nil
+	}
+	if _, err := rw.w.Write(recordMagic[:]); err != nil {
+		return err
+	}
+	rw.pos = uint64(len(recordMagic))
+	return nil
+}
+
+// RecordReader reads the records of a record file written by a
+// RecordWriter.
+type RecordReader struct {
+	r       io.ReaderAt
+	opts    []Option
+	offsets []uint64
+	// end is the position after the last record.
+	end int64
+	// maxLen is the limit of WithMaxMessageSize on record lengths.
+	maxLen int
+}
+
+// NewRecordReader reads the index of the record file of the given size in
+// r, decoding with opts. A file without its index, e.g. one whose writer
+// crashed before Close, is scanned instead, up to the last complete record
+// with a valid CRC. A file not starting with the magic "BREC" gives an
+// error matching ErrBadMagic.
+func NewRecordReader(r io.ReaderAt, size int64, opts ...Option) (*RecordReader, error) {
+	var magic [4]byte
+	if n, err := r.ReadAt(magic[:], 0); n < len(magic) {
+		return nil, sectionErr(err)
+	}
+	if magic != recordMagic {
+		return nil, fmt.Errorf("%w: record file starts with % x", ErrBadMagic, magic)
+	}
+	rr := &RecordReader{r: r, opts: opts}
+	var c config
+	c.init(opts)
+	rr.maxLen = c.maxMessageSize
+	if ok, err := rr.readIndex(size); ok || err != nil {
+		return rr, err
+	}
+	rr.scan(size)
+	return rr, nil
+}
+
+// readIndex reads the index at the end of a file, reporting false if the
+// file has none.
+func (rr *RecordReader) readIndex(size int64) (bool, error) {
+	start := size - recordTrailerSize
+	if start < int64(len(recordMagic)) {
+		return false, nil
+	}
+	var t recordTrailer
+	if err := NewDecoder(io.NewSectionReader(rr.r, start, recordTrailerSize), rr.opts...).Decode(&t, 0); err != nil {
+		return false, sectionErr(err)
+	}
+	if t.Magic != recordIndexMagic || t.IndexOffset+8*uint64(t.Count) != uint64(start) {
+		return false, nil
+	}
+	dec := NewDecoder(io.NewSectionReader(rr.r, int64(t.IndexOffset), 8*int64(t.Count)), rr.opts...)
+	if err := dec.checkLen(int(t.Count), reflect.Slice); err != nil {
+		return true, newDecodeError("", reflect.TypeOf(rr.offsets), err)
+	}
+	rr.offsets = make([]uint64, t.Count)
+	if err := dec.Decode(&rr.offsets, 0); err != nil {
+		return true, sectionErr(err)
+	}
+	rr.end = int64(t.IndexOffset)
+	return true, nil
+}
+
+// scan finds the complete records of a file without an index.
+func (rr *RecordReader) scan(size int64) {
+	rr.end = size
+	off := int64(len(recordMagic))
+	for off+recordHeaderSize <= size {
+		h, _, err := rr.read(off)
+		if err != nil {
+			break
+		}
+		rr.offsets = append(rr.offsets, uint64(off))
+		off += recordHeaderSize + int64(h.Length)
+	}
+	rr.end = off
+}
+
+// Len returns the number of records.
+func (rr *RecordReader) Len() int {
+	return len(rr.offsets)
+}
+
+// Header returns the header of record i.
+func (rr *RecordReader) Header(i int) (RecordHeader, error) {
+	if i < 0 || i >= len(rr.offsets) {
+		return RecordHeader{}, fmt.Errorf("binencoder: no record %d", i)
+	}
+	return rr.header(int64(rr.offsets[i]))
+}
+
+// Decode decodes record i into v, which must take all of it, and returns
+// the record's type ID. A record whose bytes do not match its CRC gives
+// an error matching ErrBadChecksum.
+func (rr *RecordReader) Decode(i int, v interface{}) (uint32, error) {
+	if i < 0 || i >= len(rr.offsets) {
+		return 0, fmt.Errorf("binencoder: no record %d", i)
+	}
+	h, b, err := rr.read(int64(rr.offsets[i]))
+	if err != nil {
+		return h.Type, fmt.Errorf("binencoder: record %d: %w", i, err)
+	}
+	r := bytes.NewReader(b)
+	if err := NewDecoder(r, rr.opts...).Decode(v, 0); err != nil {
+		return h.Type, fmt.Errorf("binencoder: record %d: %w", i, sectionErr(err))
+	}
+	if r.Len() != 0 {
+		return h.Type, fmt.Errorf("binencoder: record %d: %d bytes left after decoding %T", i, r.Len(), v)
+	}
+	return h.Type, nil
+}
+
+// header decodes the header of the record at off.
+func (rr *RecordReader) header(off int64) (RecordHeader, error) {
+	var h RecordHeader
+	err := NewDecoder(io.NewSectionReader(rr.r, off, recordHeaderSize), rr.opts...).Decode(&h, 0)
+	return h, sectionErr(err)
+}
+
+// read returns the header and the bytes of the record at off after
+// checking its CRC.
+func (rr *RecordReader) read(off int64) (RecordHeader, []byte, error) {
+	h, err := rr.header(off)
+	if err != nil {
+		return h, nil, err
+	}
+	if off+recordHeaderSize+int64(h.Length) > rr.end {
+		return h, nil, ErrShortMessage
+	}
+	if rr.maxLen > 0 && int64(h.Length) > int64(rr.maxLen) {
+		return h, nil, fmt.Errorf("%w: record of %d bytes exceeds the limit of %d", ErrMessageTooLarge, h.Length, rr.maxLen)
+	}
+	b := make([]byte, h.Length)
+	if n, err := rr.r.ReadAt(b, off+recordHeaderSize); n < len(b) {
+		return h, nil, sectionErr(err)
+	}
+	if sum := crc32.ChecksumIEEE(b); sum != h.CRC {
+		return h, nil, fmt.Errorf("%w: CRC %#08x, want %#08x", ErrBadChecksum, sum, h.CRC)
+	}
+	return h, b, nil
+}
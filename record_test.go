@@ -0,0 +1,82 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestRecordFile(t *testing.T) {
+	type event struct {
+		Seq  uint32
+		Code uint16
+	}
+	buf := new(bytes.Buffer)
+	rw := binencoder.NewRecordWriter(buf)
+	for i := uint32(0); i < 3; i++ {
+		n, err := rw.Append(7+i, event{Seq: i, Code: uint16(100 * i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int(i) {
+			t.Errorf("Append returned %d, want %d", n, i)
+		}
+	}
+	records := len(buf.Bytes())
+	if err := rw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	equalByte(t, file[:4], []byte("BREC"))
+	equalByte(t, file[4:20], []byte{6, 0, 0, 0, 7, 0, 0, 0, 0xa3, 0xa1, 0xc2, 0xb1, 0, 0, 0, 0})
+	equalByte(t, file[len(file)-4:], []byte("BIDX"))
+
+	rr, err := binencoder.NewRecordReader(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.Len() != 3 {
+		t.Fatalf("Len is %d, want 3", rr.Len())
+	}
+	var e event
+	typeID, err := rr.Decode(2, &e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typeID != 9 || e != (event{2, 200}) {
+		t.Errorf("record 2 is type %d, %+v", typeID, e)
+	}
+	if h, err := rr.Header(1); err != nil || h.Type != 8 || h.Length != 6 {
+		t.Errorf("header %+v, %v", h, err)
+	}
+	if _, err := rr.Decode(3, &e); err == nil {
+		t.Error("expected an error for a missing record")
+	}
+
+	// Without the index, complete records are found by scanning.
+	torn := append([]byte(nil), file[:records-2]...)
+	rr, err = binencoder.NewRecordReader(bytes.NewReader(torn), int64(len(torn)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.Len() != 2 {
+		t.Errorf("scanned %d records, want 2", rr.Len())
+	}
+
+	corrupt := append([]byte(nil), file...)
+	corrupt[16]++
+	rr, err = binencoder.NewRecordReader(bytes.NewReader(corrupt), int64(len(corrupt)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.Decode(0, &e); !errors.Is(err, binencoder.ErrBadChecksum) {
+		t.Errorf("expected ErrBadChecksum, got %v", err)
+	}
+
+	_, err = binencoder.NewRecordReader(bytes.NewReader([]byte("RIFF....")), 8)
+	if !errors.Is(err, binencoder.ErrBadMagic) {
+		t.Errorf("expected ErrBadMagic, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package riff writes RIFF files, such as WAV and AVI, with the binencoder
+// package. Chunk sizes are not known up front: a Writer leaves them zero
+// and, like an Encoder placing fields with `offset` tags, seeks back to
+// fill them in once a chunk is complete.
+//
+//	w, err := riff.NewWriter(f, "WAVE")
+//	err = w.Chunk("fmt ", format)
+//	err = w.Begin("data")
+//	_, err = w.Write(samples)
+//	err = w.Close()
+package riff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/milQA/binencoder"
+)
+
+// headerLen is the length of a chunk header: its ID and its size.
+const headerLen = 8
+
+type chunkHeader struct {
+	ID   [4]byte
+	Size uint32
+}
+
+// Writer writes a RIFF file. Values and data go into the innermost open
+// chunk.
+type Writer struct {
+	ws  io.WriteSeeker
+	enc *binencoder.Encoder
+	// hdr encodes the chunk headers, always little-endian.
+	hdr *binencoder.Encoder
+	// open holds the offsets of the open chunks, outermost first.
+	open []int64
+}
+
+// NewWriter starts a RIFF file of the given form type, e.g. "WAVE", on ws.
+// The values encoded by the Writer use opts; without WithByteOrder they
+// are little-endian, as RIFF requires.
+func NewWriter(ws io.WriteSeeker, formType string, opts ...binencoder.Option) (*Writer, error) {
+	w := &Writer{
+		ws:  ws,
+		enc: binencoder.NewEncoder(ws, opts...),
+		hdr: binencoder.NewEncoder(ws, binencoder.WithByteOrder(binary.LittleEndian)),
+	}
+	return w, w.beginList("RIFF", formType)
+}
+
+// fourCC returns the chunk ID or type s, which must have 4 bytes.
+func fourCC(s string) ([4]byte, error) {
+	var id [4]byte
+	if len(s) != len(id) {
+		return id, fmt.Errorf("%w: RIFF ID %q is not 4 bytes", binencoder.ErrInvalidValue, s)
+	}
+	copy(id[:], s)
+	return id, nil
+}
+
+// Begin opens a chunk with the given ID inside the innermost open chunk.
+func (w *Writer) Begin(id string) error {
+	cc, err := fourCC(id)
+	if err != nil {
+		return err
+	}
+	pos, err := w.ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if err := w.hdr.Encode(chunkHeader{ID: cc}, 0); err != nil {
+		return err
+	}
+	w.open = append(w.open, pos)
+	return nil
+}
+
+// BeginList opens a LIST chunk of the given list type, e.g. "INFO".
+func (w *Writer) BeginList(listType string) error {
+	return w.beginList("LIST", listType)
+}
+
+func (w *Writer) beginList(id, listType string) error {
+	cc, err := fourCC(listType)
+	if err != nil {
+		return err
+	}
+	if err := w.Begin(id); err != nil {
+		return err
+	}
+	_, err = w.ws.Write(cc[:])
+	return err
+}
+
+// Encode encodes v into the innermost open chunk.
+func (w *Writer) Encode(v interface{}) error {
+	return w.enc.Encode(v, 0)
+}
+
+// Write writes p into the innermost open chunk.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.ws.Write(p)
+}
+
+// Chunk writes a chunk with the given ID holding the encoding of v.
+func (w *Writer) Chunk(id string, v interface{}) error {
+	if err := w.Begin(id); err != nil {
+		return err
+	}
+	if err := w.Encode(v); err != nil {
+		return err
+	}
+	return w.End()
+}
+
+// End closes the innermost open chunk: it fills in the chunk's size and
+// pads it to an even length.
+func (w *Writer) End() error {
+	if len(w.open) == 0 {
+		return fmt.Errorf("riff: no open chunk")
+	}
+	start := w.open[len(w.open)-1]
+	w.open = w.open[:len(w.open)-1]
+	end, err := w.ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	size := end - start - headerLen
+	if size > 1<<32-1 {
+		return fmt.Errorf("%w: RIFF chunk of %d bytes", binencoder.ErrOverflow, size)
+	}
+	if _, err := w.ws.Seek(start+headerLen/2, io.SeekStart); err != nil {
+		return err
+	}
+	if err := w.hdr.Encode(uint32(size), 0); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+	if size%2 != 0 {
+		_, err = w.ws.Write([]byte{0})
+	}
+	return err
+}
+
+// Close closes the open chunks, the RIFF chunk last. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	for len(w.open) > 0 {
+		if err := w.End(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package riff_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/milQA/binencoder"
+	"github.com/milQA/binencoder/riff"
+)
+
+type waveFormat struct {
+	Format        uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+func TestWriter(t *testing.T) {
+	f, err := ioutil.TempFile("", "riff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	w, err := riff.NewWriter(f, "WAVE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Chunk("fmt ", waveFormat{1, 1, 8000, 8000, 1, 8}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.BeginList("INFO"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Chunk("INAM", [3]byte{'h', 'i', 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.End(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Begin("data"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte{0x80, 0x81, 0x82}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("RIFF\x40\x00\x00\x00WAVE")
+	want = append(want, "fmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1f\x00\x00\x40\x1f\x00\x00\x01\x00\x08\x00"...)
+	want = append(want, "LIST\x10\x00\x00\x00INFOINAM\x03\x00\x00\x00hi\x00\x00"...)
+	want = append(want, "data\x03\x00\x00\x00\x80\x81\x82\x00"...)
+	if !bytes.Equal(b, want) {
+		t.Errorf("got\n% x\nwant\n% x", b, want)
+	}
+
+	if err := w.Begin("toolong"); !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if err := w.End(); err == nil {
+		t.Error("expected an error without an open chunk")
+	}
+}
@@ -0,0 +1,250 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// RoundTrip encodes v with opts, decodes the encoding into a new value of
+// the same type with the same slice and string lengths, and returns an
+// error naming the first field that does not come back equal, or nil.
+// Encoding and decoding errors are returned as they are.
+func RoundTrip(v interface{}, opts ...Option) error {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, opts...)
+	if err := enc.Encode(v, 0); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil
+	}
+	out := reflect.New(rv.Type())
+	shape(out.Elem(), rv)
+	r := bytes.NewReader(buf.Bytes())
+	if err := NewDecoder(r, opts...).Decode(out.Interface(), 0); err != nil {
+		return err
+	}
+	if path, ok := firstDiff(rv, out.Elem(), ""); !ok {
+		a, b := valueAt(rv, path), valueAt(out.Elem(), path)
+		return fmt.Errorf("binencoder: %s does not round-trip: encoded %#v, decoded %#v", describeField(path, a.Type()), a, b)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("binencoder: %d bytes left after decoding %T", r.Len(), v)
+	}
+	return nil
+}
+
+// Fuzz fills a new value of the type of sample with data and checks that it
+// round-trips with opts like RoundTrip. Strings are kept within their `len`
+// tags and never end in padding, so that only lossy layouts are reported.
+// It is meant to be called from fuzz targets:
+//
+//	f.Fuzz(func(t *testing.T, data []byte) {
+//		if err := binencoder.Fuzz(Message{}, data); err != nil {
+//			t.Fatal(err)
+//		}
+//	})
+func Fuzz(sample interface{}, data []byte, opts ...Option) error {
+	t := reflect.TypeOf(sample)
+	if t == nil {
+		return newEncodeError("", nil, ErrUnknownType)
+	}
+	var c config
+	c.init(opts)
+	v := reflect.New(t).Elem()
+	fz := fuzzer{config: c, data: data}
+	fz.fill(v, 0)
+	return RoundTrip(v.Interface(), opts...)
+}
+
+// fuzzer fills values from fuzzing data, reading zeros once it runs out.
+type fuzzer struct {
+	config
+	data []byte
+}
+
+func (fz *fuzzer) byte() byte {
+	if len(fz.data) == 0 {
+		return 0
+	}
+	b := fz.data[0]
+	fz.data = fz.data[1:]
+	return b
+}
+
+func (fz *fuzzer) uint(size int) uint64 {
+	var x uint64
+	for i := 0; i < size; i++ {
+		x = x<<8 | uint64(fz.byte())
+	}
+	return x
+}
+
+// fill sets v from the data. bytesLen is the length from the enclosing
+// `len` tag, if any.
+func (fz *fuzzer) fill(v reflect.Value, bytesLen int) {
+	if bytesLen == -1 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(fz.byte()&1 != 0)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(fz.uint(int(v.Type().Size()))))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(fz.uint(int(v.Type().Size())))
+	case reflect.Float32:
+		if f := math.Float32frombits(uint32(fz.uint(4))); f == f {
+			v.SetFloat(float64(f))
+		}
+	case reflect.Float64:
+		if f := math.Float64frombits(fz.uint(8)); f == f {
+			v.SetFloat(f)
+		}
+	case reflect.Complex64:
+		re := math.Float32frombits(uint32(fz.uint(4)))
+		im := math.Float32frombits(uint32(fz.uint(4)))
+		if re == re && im == im {
+			v.SetComplex(complex(float64(re), float64(im)))
+		}
+	case reflect.Complex128:
+		re, im := math.Float64frombits(fz.uint(8)), math.Float64frombits(fz.uint(8))
+		if re == re && im == im {
+			v.SetComplex(complex(re, im))
+		}
+	case reflect.String:
+		// Letters are never mistaken for padding, which decoding trims.
+		n := int(fz.byte() % 8)
+		if bytesLen > 0 {
+			n = int(fz.byte()) % (bytesLen + 1)
+		}
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = 'a' + fz.byte()%26
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		n := int(fz.byte() % 4)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fz.fill(v.Index(i), bytesLen)
+		}
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fz.fill(v.Elem(), bytesLen)
+	case reflect.Struct:
+		for _, f := range fz.structPlan(v.Type()) {
+			if f.sizeFrom < 0 && fz.present(&f) {
+				fz.fill(v.Field(f.index), f.fieldLen(bytesLen))
+			}
+			if f.until != nil {
+				v.Field(f.index).Set(reflect.Zero(v.Field(f.index).Type()))
+			}
+			if f.lenFrom >= 0 {
+				// Fields taking their length from another field are left
+				// out, as the length filled in need not fit them.
+				v.Field(f.lenFrom).Set(reflect.Zero(v.Field(f.lenFrom).Type()))
+				v.Field(f.index).Set(reflect.Zero(v.Field(f.index).Type()))
+			}
+		}
+	}
+}
+
+// shape sets v, a zero value, to the slice and string lengths of like, which
+// decoding takes as the lengths to read.
+func shape(v, like reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(make([]byte, like.Len())))
+	case reflect.Slice:
+		if like.IsNil() {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), like.Len(), like.Len()))
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			shape(v.Index(i), like.Index(i))
+		}
+	case reflect.Ptr:
+		if !like.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+			shape(v.Elem(), like.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				shape(v.Field(i), like.Field(i))
+			}
+		}
+	}
+}
+
+// firstDiff compares the exported fields and elements of a and b and
+// returns the path of the first difference, reporting false.
+func firstDiff(a, b reflect.Value, path string) (string, bool) {
+	if eq := a.MethodByName("Equal"); eq.IsValid() && eq.Type().NumIn() == 1 && eq.Type().In(0) == a.Type() && eq.Type().NumOut() == 1 && eq.Type().Out(0).Kind() == reflect.Bool {
+		return path, eq.Call([]reflect.Value{b})[0].Bool()
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if p, ok := firstDiff(a.Field(i), b.Field(i), joinPath(path, a.Type().Field(i).Name)); !ok {
+				return p, false
+			}
+		}
+		return path, true
+	case reflect.Array, reflect.Slice:
+		if a.Len() != b.Len() {
+			return path, false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if p, ok := firstDiff(a.Index(i), b.Index(i), path+"["+strconv.Itoa(i)+"]"); !ok {
+				return p, false
+			}
+		}
+		return path, true
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return path, a.IsNil() == b.IsNil()
+		}
+		return firstDiff(a.Elem(), b.Elem(), path)
+	}
+	return path, reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// valueAt returns the value at path, as returned by firstDiff, below v.
+func valueAt(v reflect.Value, path string) reflect.Value {
+	for path != "" {
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+		case path[0] == '[':
+			end := 1
+			for path[end] != ']' {
+				end++
+			}
+			i, _ := strconv.Atoi(path[1:end])
+			v, path = v.Index(i), path[end+1:]
+		default:
+			end := 0
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			v, path = v.FieldByName(path[:end]), path[end:]
+		}
+	}
+	return v
+}
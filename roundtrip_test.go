@@ -0,0 +1,58 @@
+package binencoder_test
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+type fuzzMessage struct {
+	Version uint8
+	Flags   uint16 `endian:"be"`
+	Name    string `len:"8"`
+	Label   string
+	Points  []struct{ X, Y int16 }
+	Next    *fuzzMessage `len:"-"`
+	Wide    int32        `len:"8"`
+	Raw     [3]byte
+	At      time.Time
+}
+
+func TestFuzz(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		data := make([]byte, r.Intn(64))
+		r.Read(data)
+		if err := binencoder.Fuzz(fuzzMessage{}, data); err != nil {
+			t.Fatalf("% x: %v", data, err)
+		}
+	}
+}
+
+func TestRoundTripLossy(t *testing.T) {
+	type lossy struct {
+		A uint8
+		B []struct {
+			Delay time.Duration `durfmt:"ms"`
+		}
+	}
+	v := lossy{B: make([]struct {
+		Delay time.Duration `durfmt:"ms"`
+	}, 2)}
+	v.B[1].Delay = 1400 * time.Microsecond
+	err := binencoder.RoundTrip(v)
+	if err == nil || !strings.Contains(err.Error(), "B[1].Delay (time.Duration) does not round-trip: encoded 1400000, decoded 1000000") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	type bad struct {
+		A uint8 `endian:"middle"`
+	}
+	if err := binencoder.Fuzz(bad{}, nil); !errors.As(err, new(*binencoder.EncodeError)) {
+		t.Errorf("expected an EncodeError, got %v", err)
+	}
+}
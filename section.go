@@ -0,0 +1,135 @@
+package binencoder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// SectionEntry describes a section in the directory of a document written
+// by a SectionWriter. Offset counts from the start of the document.
+type SectionEntry struct {
+	ID     uint32
+	Offset uint32
+	Size   uint32
+}
+
+// sectionEntrySize is the encoded size of a SectionEntry.
+const sectionEntrySize = 12
+
+// SectionWriter writes a document made of sections: a directory, the
+// number of sections as a uint32 followed by a SectionEntry for each, and
+// then the sections in the order they were added.
+type SectionWriter struct {
+	w       io.Writer
+	opts    []Option
+	enc     *Encoder
+	body    bytes.Buffer
+	entries []SectionEntry
+}
+
+// NewSectionWriter returns a SectionWriter writing to w. The directory and
+// the sections are encoded with opts.
+func NewSectionWriter(w io.Writer, opts ...Option) *SectionWriter {
+	return &SectionWriter{w: w, opts: opts, enc: NewEncoder(nil, opts...)}
+}
+
+// Encode encodes v as a section with the given id. Sections are held in
+// memory until Close, which knows their offsets.
+func (sw *SectionWriter) Encode(id uint32, v interface{}) error {
+	start := sw.body.Len()
+	sw.enc.Reset(&sw.body)
+	if err := sw.enc.Encode(v, 0); err != nil {
+		sw.body.Truncate(start)
+		return fmt.Errorf("binencoder: section %d: %w", id, err)
+	}
+	sw.entries = append(sw.entries, SectionEntry{ID: id, Offset: uint32(start), Size: uint32(sw.body.Len() - start)})
+	return nil
+}
+
+// Close writes the directory and the sections. It does not close the
+// underlying writer.
+func (sw *SectionWriter) Close() error {
+	dirSize := 4 + sectionEntrySize*len(sw.entries)
+	if uint64(dirSize)+uint64(sw.body.Len()) > math.MaxUint32 {
+		return fmt.Errorf("%w: document of %d bytes does not fit uint32 offsets", ErrOverflow, dirSize+sw.body.Len())
+	}
+	entries := make([]SectionEntry, len(sw.entries))
+	for i, e := range sw.entries {
+		e.Offset += uint32(dirSize)
+		entries[i] = e
+	}
+	enc := NewEncoder(sw.w, sw.opts...)
+	if err := enc.Encode(uint32(len(entries)), 0); err != nil {
+		return err
+	}
+	if err := enc.Encode(entries, 0); err != nil {
+		return err
+	}
+	_, err := sw.w.Write(sw.body.Bytes())
+	return err
+}
+
+// SectionReader reads the sections of a document written by a
+// SectionWriter.
+type SectionReader struct {
+	r       io.ReaderAt
+	opts    []Option
+	entries []SectionEntry
+}
+
+// NewSectionReader reads the directory of the document in r, decoding
+// with opts.
+func NewSectionReader(r io.ReaderAt, opts ...Option) (*SectionReader, error) {
+	dec := NewDecoder(io.NewSectionReader(r, 0, math.MaxInt64), opts...)
+	var count uint32
+	if err := dec.Decode(&count, 0); err != nil {
+		return nil, sectionErr(err)
+	}
+	if err := dec.checkLen(int(count), reflect.Slice); err != nil {
+		return nil, newDecodeError("", reflect.TypeOf([]SectionEntry(nil)), err)
+	}
+	sr := &SectionReader{r: r, opts: opts}
+	for i := uint32(0); i < count; i++ {
+		var e SectionEntry
+		if err := dec.Decode(&e, 0); err != nil {
+			return nil, fmt.Errorf("binencoder: section entry %d: %w", i, sectionErr(err))
+		}
+		sr.entries = append(sr.entries, e)
+	}
+	return sr, nil
+}
+
+// Sections returns the directory of the document.
+func (sr *SectionReader) Sections() []SectionEntry {
+	return sr.entries
+}
+
+// Decode decodes the first section with the given id into v, which must
+// take all of it.
+func (sr *SectionReader) Decode(id uint32, v interface{}) error {
+	for _, e := range sr.entries {
+		if e.ID != id {
+			continue
+		}
+		section := io.NewSectionReader(sr.r, int64(e.Offset), int64(e.Size))
+		if err := NewDecoder(section, sr.opts...).Decode(v, 0); err != nil {
+			return fmt.Errorf("binencoder: section %d: %w", id, sectionErr(err))
+		}
+		if n, _ := section.Seek(0, io.SeekCurrent); n != int64(e.Size) {
+			return fmt.Errorf("binencoder: section %d: %d bytes left after decoding %T", id, int64(e.Size)-n, v)
+		}
+		return nil
+	}
+	return fmt.Errorf("binencoder: no section %d", id)
+}
+
+// sectionErr reports the end of a document or section as ErrShortMessage.
+func sectionErr(err error) error {
+	if err == io.EOF {
+		return ErrShortMessage
+	}
+	return err
+}
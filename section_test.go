@@ -0,0 +1,63 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestSections(t *testing.T) {
+	type meta struct {
+		Name string `len:"4"`
+	}
+	var buf bytes.Buffer
+	sw := binencoder.NewSectionWriter(&buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err := sw.Encode(7, meta{"log"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Encode(9, []uint16{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0, 0, 0, 2,
+		0, 0, 0, 7, 0, 0, 0, 28, 0, 0, 0, 4,
+		0, 0, 0, 9, 0, 0, 0, 32, 0, 0, 0, 4,
+		0, 'l', 'o', 'g',
+		0, 1, 0, 2,
+	})
+
+	sr, err := binencoder.NewSectionReader(bytes.NewReader(buf.Bytes()), binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []binencoder.SectionEntry{{ID: 7, Offset: 28, Size: 4}, {ID: 9, Offset: 32, Size: 4}}
+	if !reflect.DeepEqual(sr.Sections(), want) {
+		t.Errorf("got %+v, want %+v", sr.Sections(), want)
+	}
+	values := make([]uint16, 2)
+	if err := sr.Decode(9, &values); err != nil {
+		t.Fatal(err)
+	}
+	var m meta
+	if err := sr.Decode(7, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "log" || !reflect.DeepEqual(values, []uint16{1, 2}) {
+		t.Errorf("got %q and %v", m.Name, values)
+	}
+	if err := sr.Decode(8, &m); err == nil {
+		t.Error("expected an error for a missing section")
+	}
+
+	_, err = binencoder.NewSectionReader(bytes.NewReader(buf.Bytes()[:20]), binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage for a cut directory, got %v", err)
+	}
+}
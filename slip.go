@@ -0,0 +1,116 @@
+package binencoder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// SLIP special bytes (RFC 1055).
+const (
+	slipEnd    = 0xc0
+	slipEsc    = 0xdb
+	slipEscEnd = 0xdc
+	slipEscEsc = 0xdd
+)
+
+// SLIPWriter writes frames in SLIP (RFC 1055) framing: END and ESC bytes
+// in the data are escaped and every frame is enclosed in END bytes, the
+// leading one flushing any line noise at the receiver. Data is buffered
+// until EndFrame.
+type SLIPWriter struct {
+	w   io.Writer
+	buf []byte
+	out []byte
+}
+
+// NewSLIPWriter returns a SLIPWriter writing to w. An Encoder writing to it
+// emits every value as a frame.
+func NewSLIPWriter(w io.Writer) *SLIPWriter {
+	return &SLIPWriter{w: w}
+}
+
+func (sw *SLIPWriter) Write(p []byte) (int, error) {
+	sw.buf = append(sw.buf, p...)
+	return len(p), nil
+}
+
+// EndFrame writes the buffered data as a frame.
+func (sw *SLIPWriter) EndFrame() error {
+	out := append(sw.out[:0], slipEnd)
+	for _, c := range sw.buf {
+		switch c {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, c)
+		}
+	}
+	sw.out = append(out, slipEnd)
+	sw.buf = sw.buf[:0]
+	_, err := sw.w.Write(sw.out)
+	return err
+}
+
+// AbortFrame discards the buffered data.
+func (sw *SLIPWriter) AbortFrame() {
+	sw.buf = sw.buf[:0]
+}
+
+// SLIPReader reads SLIP frames. Its Read method serves the decoded frames
+// as one stream for a Decoder.
+type SLIPReader struct {
+	r *bufio.Reader
+	frameReader
+}
+
+// NewSLIPReader returns a SLIPReader reading from r.
+func NewSLIPReader(r io.Reader) *SLIPReader {
+	sr := &SLIPReader{r: bufio.NewReader(r)}
+	sr.next = sr.ReadFrame
+	return sr
+}
+
+// ReadFrame reads and unescapes the next non-empty frame. It returns io.EOF
+// at the end of the input, an error matching ErrShortMessage if the input
+// ends within a frame and one matching ErrBadFrame for an invalid escape.
+func (sr *SLIPReader) ReadFrame() ([]byte, error) {
+	for {
+		b, err := sr.r.ReadBytes(slipEnd)
+		if err == io.EOF && len(b) != 0 {
+			return nil, ErrShortMessage
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 1 {
+			return slipDecode(b[:len(b)-1])
+		}
+	}
+}
+
+// slipDecode unescapes b in place.
+func slipDecode(b []byte) ([]byte, error) {
+	out := b[:0]
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == slipEsc {
+			i++
+			if i == len(b) {
+				return nil, fmt.Errorf("%w: SLIP escape at the end of the frame", ErrBadFrame)
+			}
+			switch b[i] {
+			case slipEscEnd:
+				c = slipEnd
+			case slipEscEsc:
+				c = slipEsc
+			default:
+				return nil, fmt.Errorf("%w: invalid SLIP escape %#x", ErrBadFrame, b[i])
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
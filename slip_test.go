@@ -0,0 +1,51 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestSLIP(t *testing.T) {
+	type message struct {
+		ID    uint16
+		Flags uint8
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(binencoder.NewSLIPWriter(buf))
+	for _, m := range []message{{0xdbc0, 1}, {2, 0xc0}} {
+		if err := enc.Encode(m, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0xc0, 0xdb, 0xdc, 0xdb, 0xdd, 1, 0xc0,
+		0xc0, 2, 0, 0xdb, 0xdc, 0xc0,
+	})
+
+	dec := binencoder.NewDecoder(binencoder.NewSLIPReader(buf))
+	for _, want := range []message{{0xdbc0, 1}, {2, 0xc0}} {
+		var out message
+		if err := dec.Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != want {
+			t.Errorf("We have:\n%+v\n got:\n%+v\n", want, out)
+		}
+	}
+	if err := dec.Decode(&message{}, 0); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	_, err := binencoder.NewSLIPReader(bytes.NewReader([]byte{0xc0, 1, 0xdb, 2, 0xc0})).ReadFrame()
+	if !errors.Is(err, binencoder.ErrBadFrame) {
+		t.Errorf("expected ErrBadFrame, got %v", err)
+	}
+	_, err = binencoder.NewSLIPReader(bytes.NewReader([]byte{0xc0, 1, 2})).ReadFrame()
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+}
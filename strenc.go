@@ -0,0 +1,242 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// strenc is a parsed `strenc` tag: the character encoding of a string's
+// wire value, optionally starting with a byte order mark and ending with a
+// NUL character, e.g. `strenc:"utf16le bom nul"`.
+type strenc struct {
+	// order is the byte order of UTF-16 and cp the EBCDIC code page; one
+	// of them is set unless jutf8 is.
+	order binary.ByteOrder
+	cp    *ebcdic
+	bom   bool
+	nul   bool
+	// jutf8 selects the length-prefixed modified UTF-8 of Java.
+	jutf8 bool
+}
+
+func parseStrenc(tag string) (strenc, error) {
+	var se strenc
+	words := strings.Fields(tag)
+	if len(words) == 0 {
+		return se, fmt.Errorf("binencoder: invalid strenc tag %q", tag)
+	}
+	switch words[0] {
+	case "utf16le":
+		se.order = binary.LittleEndian
+	case "utf16be":
+		se.order = binary.BigEndian
+	case "ebcdic", "cp037":
+		se.cp = cp037
+	case "cp500":
+		se.cp = cp500
+	case "jutf8":
+		se.jutf8 = true
+	default:
+		return se, fmt.Errorf("binencoder: unknown string encoding %q", words[0])
+	}
+	for _, w := range words[1:] {
+		switch {
+		case w == "bom" && se.cp == nil && !se.jutf8:
+			se.bom = true
+		case w == "nul" && !se.jutf8:
+			se.nul = true
+		default:
+			return se, fmt.Errorf("binencoder: invalid strenc tag %q", tag)
+		}
+	}
+	return se, nil
+}
+
+// unit is the size of a character unit in bytes.
+func (se strenc) unit() int {
+	if se.cp != nil {
+		return 1
+	}
+	return 2
+}
+
+// appendRune appends the encoding of r to b, reporting false if the
+// encoding cannot represent it.
+func (se strenc) appendRune(b []byte, r rune) ([]byte, bool) {
+	if se.cp != nil {
+		if r > 0xff {
+			return b, false
+		}
+		return append(b, se.cp.fromLatin1[r]), true
+	}
+	units := []uint16{uint16(r)}
+	if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+		units = []uint16{uint16(r1), uint16(r2)}
+	}
+	for _, u := range units {
+		b = append(b, 0, 0)
+		se.order.PutUint16(b[len(b)-2:], u)
+	}
+	return b, true
+}
+
+// encode returns the wire value of s, with the BOM and NUL if set. Runes
+// the encoding cannot represent are replaced by '?' and reported with an
+// error.
+func (se strenc) encode(s string) ([]byte, error) {
+	var err error
+	b := make([]byte, 0, se.unit()*(len(s)+2))
+	if se.bom {
+		b, _ = se.appendRune(b, 0xfeff)
+	}
+	for _, r := range s {
+		var ok bool
+		if b, ok = se.appendRune(b, r); !ok {
+			b, _ = se.appendRune(b, '?')
+			if err == nil {
+				err = fmt.Errorf("%w: %q cannot be encoded in the string encoding", ErrInvalidValue, r)
+			}
+		}
+	}
+	if se.nul {
+		b, _ = se.appendRune(b, 0)
+	}
+	return b, err
+}
+
+// decode is the inverse of encode. A BOM, if set, selects the byte order;
+// the string ends at a NUL, if set, and trailing pad characters are
+// trimmed.
+func (se strenc) decode(b []byte, pad rune) string {
+	var runes []rune
+	if se.cp != nil {
+		runes = make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(se.cp.toLatin1[c])
+		}
+	} else {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			units = append(units, se.order.Uint16(b[i:]))
+		}
+		if se.bom && len(units) > 0 {
+			switch units[0] {
+			case 0xfeff:
+				units = units[1:]
+			case 0xfffe:
+				units = units[1:]
+				for i, u := range units {
+					units[i] = u>>8 | u<<8
+				}
+			}
+		}
+		runes = utf16.Decode(units)
+	}
+	if se.nul {
+		for i, r := range runes {
+			if r == 0 {
+				runes = runes[:i]
+				break
+			}
+		}
+	}
+	for len(runes) > 0 && runes[len(runes)-1] == pad {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// encodeStrenc encodes a string with a `strenc` tag. With a length the
+// value is padded at the end with the pad byte as a character.
+func (enc *Encoder) encodeStrenc(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	se, err := parseStrenc(tags.strenc)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if se.jutf8 {
+		return enc.encodeJavaUTF(v, path)
+	}
+	s := v.String()
+	b, err := se.encode(s)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	if bytesLen != 0 && len(b) > bytesLen && tags.overflow != "" {
+		left, err := truncateLeft(tags.overflow)
+		if err != nil {
+			return enc.fail(path, v.Type(), err)
+		}
+		// Runes are dropped one at a time, as their encoded sizes vary.
+		runes := []rune(s)
+		for len(b) > bytesLen && len(runes) > 0 {
+			if left {
+				runes = runes[1:]
+			} else {
+				runes = runes[:len(runes)-1]
+			}
+			b, _ = se.encode(string(runes))
+		}
+	}
+	if bytesLen != 0 {
+		if len(b) > bytesLen {
+			return enc.fail(path, v.Type(), ErrFieldTooLong)
+		}
+		pad, _ := se.appendRune(nil, rune(enc.padByte))
+		for len(b)+len(pad) <= bytesLen {
+			b = append(b, pad...)
+		}
+		for len(b) < bytesLen {
+			b = append(b, enc.padByte)
+		}
+	}
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeStrenc is the inverse of Encoder.encodeStrenc. Without a length a
+// NUL-terminated string is read up to its NUL and other strings take the
+// length of the value v holds.
+func (dec *Decoder) decodeStrenc(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	se, err := parseStrenc(tags.strenc)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	if se.jutf8 {
+		return dec.decodeJavaUTF(v, path)
+	}
+	var b []byte
+	switch {
+	case bytesLen == 0 && se.nul:
+		unit := make([]byte, se.unit())
+		for {
+			if max := dec.maxStringLen; max > 0 && len(b) >= max {
+				return newDecodeError(path, v.Type(), fmt.Errorf("%w: NUL-terminated string exceeds the limit of %d", ErrLimitExceeded, max))
+			}
+			if err := dec.readFull(unit); err != nil {
+				return newDecodeError(path, v.Type(), err)
+			}
+			b = append(b, unit...)
+			if unit[0] == 0 && unit[len(unit)-1] == 0 {
+				break
+			}
+		}
+	default:
+		n := bytesLen
+		if n == 0 {
+			current, _ := se.encode(v.String())
+			n = len(current)
+		}
+		b = make([]byte, n)
+		if err := dec.readFull(b); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+	}
+	v.SetString(se.decode(b, rune(dec.padByte)))
+	return nil
+}
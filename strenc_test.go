@@ -0,0 +1,109 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestStrencUTF16(t *testing.T) {
+	type share struct {
+		Name  string `strenc:"utf16le" len:"8"`
+		Path  string `strenc:"utf16be bom nul"`
+		Emoji string `strenc:"utf16le"`
+	}
+	in := share{Name: "ab", Path: "C:", Emoji: "😀"}
+	b, err := binencoder.Marshal(in, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{
+		'a', 0, 'b', 0, 0, 0, 0, 0,
+		0xfe, 0xff, 0, 'C', 0, ':', 0, 0,
+		0x3d, 0xd8, 0x00, 0xde,
+	})
+
+	out := share{Emoji: "--"}
+	if err := binencoder.Unmarshal(b, &out, binary.LittleEndian); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+q, want %+q", out, in)
+	}
+
+	// A little-endian BOM overrides the tag.
+	var path struct {
+		Path string `strenc:"utf16be bom nul"`
+	}
+	r := bytes.NewReader([]byte{0xff, 0xfe, 'D', 0, 0, 0})
+	if err := binencoder.NewDecoder(r).Decode(&path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if path.Path != "D" {
+		t.Errorf("got %q", path.Path)
+	}
+
+	_, err = binencoder.Marshal(struct {
+		Name string `strenc:"utf32"`
+	}{}, binary.LittleEndian)
+	if err == nil {
+		t.Error("expected an error for an unknown string encoding")
+	}
+}
+
+func TestStrencEBCDIC(t *testing.T) {
+	type record struct {
+		Name string `strenc:"ebcdic" len:"6"`
+		Note string `strenc:"cp500 nul"`
+	}
+	in := record{Name: "AB1", Note: "[x]"}
+	var buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf, binencoder.WithPadByte(' ')).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0xc1, 0xc2, 0xf1, 0x40, 0x40, 0x40, 0x4a, 0xa7, 0x5a, 0x00})
+
+	var out record
+	if err := binencoder.NewDecoder(&buf, binencoder.WithPadByte(' ')).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+q, want %+q", out, in)
+	}
+
+	_, err := binencoder.Marshal(record{Name: "€"}, binary.LittleEndian)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for an unmappable character, got %v", err)
+	}
+}
+
+func TestStrencJavaUTF8(t *testing.T) {
+	type record struct {
+		Name string `strenc:"jutf8"`
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(record{"a\x00é😀"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0, 11, 'a', 0xc0, 0x80, 0xc3, 0xa9, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80})
+
+	var out record
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "a\x00é😀" {
+		t.Errorf("got %q", out.Name)
+	}
+	if err := binencoder.NewDecoder(bytes.NewReader([]byte{0, 1, 0x80})).Decode(&out, 0); !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	err := binencoder.NewEncoder(buf).Encode(struct {
+		Name string `strenc:"jutf8 nul"`
+	}{}, 0)
+	if err == nil {
+		t.Error("expected an error for jutf8 with nul")
+	}
+}
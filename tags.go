@@ -0,0 +1,120 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tag returns the field option key. The namespace tag is looked up first,
+// e.g. `bin:"len=10,endian=be"`, where a bare first item is the length;
+// options it does not set fall back to the tag of the same name, e.g.
+// `len:"10"`. Values in the namespace tag cannot hold commas.
+func (c *config) tag(field reflect.StructField, key string) string {
+	if ns, ok := field.Tag.Lookup(c.tagName); ok {
+		for _, opt := range namespaceOptions(ns) {
+			if opt.name == key {
+				return opt.val
+			}
+		}
+	}
+	return field.Tag.Get(key)
+}
+
+type namespaceOption struct {
+	name, val string
+	// item is the text of the option in the tag.
+	item string
+}
+
+// namespaceOptions splits the value of a namespace tag into its options.
+// Bare items after the first have an empty name.
+func namespaceOptions(ns string) []namespaceOption {
+	var opts []namespaceOption
+	for first := true; ns != ""; first = false {
+		item := ns
+		if i := strings.IndexByte(ns, ','); i >= 0 {
+			item, ns = ns[:i], ns[i+1:]
+		} else {
+			ns = ""
+		}
+		item = strings.TrimSpace(item)
+		opt := namespaceOption{val: item, item: item}
+		if j := strings.IndexByte(item, '='); j >= 0 {
+			opt.name, opt.val = item[:j], item[j+1:]
+		} else if first {
+			opt.name = "len"
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+// checkNamespace rejects the items of the namespace tag of field that set
+// no option: misspelled keys, or the rest of a value cut at a comma.
+func (c *config) checkNamespace(field reflect.StructField) error {
+	ns, ok := field.Tag.Lookup(c.tagName)
+	if !ok {
+		return nil
+	}
+	for _, opt := range namespaceOptions(ns) {
+		if !isOption(opt.name) {
+			return fmt.Errorf("binencoder: unknown option %q in %s tag", opt.item, c.tagName)
+		}
+	}
+	return nil
+}
+
+// isOption reports whether name is the key of a field option.
+func isOption(name string) bool {
+	switch name {
+	case "der", "pbf":
+		return true
+	}
+	for _, key := range fieldOptions {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldOptions lists the options a field can set.
+var fieldOptions = []string{"len", "endian", "unit", "compact", "timefmt", "durfmt", "ip", "uuid", "prefix", "sizeof", "tlv", "klv", "validate", "enum", "minver", "maxver", "optional", "typeid", "embed", "order", "offset", "compress", "encoding", "strenc", "overflow", "fixed", "scale", "bigint", "flags", "dns", "until", "as", "transform", "raw"}
+
+func (c *config) parseFieldTags(field reflect.StructField) fieldTags {
+	return fieldTags{
+		compact:  c.tag(field, "compact"),
+		timefmt:  c.tag(field, "timefmt"),
+		durfmt:   c.tag(field, "durfmt"),
+		ip:       c.tag(field, "ip"),
+		uuid:     c.tag(field, "uuid"),
+		prefix:   c.tag(field, "prefix"),
+		typeid:   c.tag(field, "typeid"),
+		encoding: c.tag(field, "encoding"),
+		strenc:   c.tag(field, "strenc"),
+		overflow: c.tag(field, "overflow"),
+		fixed:    c.tag(field, "fixed"),
+		scale:    c.tag(field, "scale"),
+		bigint:   c.tag(field, "bigint"),
+		dns:      c.tag(field, "dns"),
+		as:       c.tag(field, "as"),
+		raw:      c.tag(field, "raw"),
+	}
+}
+
+// fieldOrder returns the byte order set by the field's endian option, or
+// nil if it has none.
+func (c *config) fieldOrder(field reflect.StructField) (binary.ByteOrder, error) {
+	switch endian := c.tag(field, "endian"); endian {
+	case "":
+		return nil, nil
+	case "le", "little":
+		return binary.LittleEndian, nil
+	case "be", "big":
+		return binary.BigEndian, nil
+	default:
+		return nil, fmt.Errorf("binencoder: unknown endian %q", endian)
+	}
+}
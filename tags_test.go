@@ -0,0 +1,270 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type namespaced struct {
+	ID     uint16
+	Length uint32 `bin:"endian=be"`
+	Name   string `bin:"len=4" len:"8"`
+	Code   string `bin:"3"`
+	Legacy string `len:"2"`
+	Skip   string `bin:"-"`
+	Wrap   struct {
+		A uint16
+	} `bin:"endian=be"`
+}
+
+func TestNamespaceTag(t *testing.T) {
+	in := namespaced{ID: 0x0102, Length: 0x03040506, Name: "ab", Code: "x", Legacy: "y", Skip: "z"}
+	in.Wrap.A = 0x0708
+	want := []byte{
+		0x02, 0x01,
+		0x03, 0x04, 0x05, 0x06,
+		'a', 'b', 0, 0,
+		'x', 0, 0,
+		'y', 0,
+		0x07, 0x08,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), want)
+
+	var out namespaced
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	in.Skip = ""
+	if out != in {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+}
+
+func TestNamespaceTagName(t *testing.T) {
+	type message struct {
+		A uint16 `wire:"endian=be" bin:"endian=le"`
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithTagName("wire")).Encode(message{0x0102}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x01, 0x02})
+
+	// Tags are resolved once per type and tag name.
+	buf.Reset()
+	if err := binencoder.NewEncoder(buf).Encode(message{0x0102}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0x02, 0x01})
+}
+
+func TestBigEndian(t *testing.T) {
+	type message struct {
+		A int16
+		B uint64
+		C string    `len:"3"`
+		D [2]uint16 `len:"3"`
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true), binencoder.WithPadByte(0xee))
+	if err := enc.Encode(message{A: -2, B: 1, C: "a", D: [2]uint16{0x0102, 0x0304}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0xff, 0xfe,
+		0, 0, 0, 0, 0, 0, 0, 1,
+		0xee, 0xee, 'a',
+		0xee, 0x01, 0x02, 0xee, 0x03, 0x04,
+	})
+}
+
+func TestUnknownEndian(t *testing.T) {
+	var v struct {
+		A uint16 `bin:"endian=middle"`
+	}
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(v, 0); err == nil {
+		t.Error("expected an error for an unknown endian")
+	}
+}
+
+func TestOrderTag(t *testing.T) {
+	type message struct {
+		Body    uint16 `order:"3"`
+		Skipped uint8  `len:"-"`
+		Type    uint8  `order:"1"`
+		Length  uint8  `bin:"order=2"`
+	}
+	in := message{Body: 0x0102, Type: 3, Length: 2}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{3, 2, 0x02, 0x01})
+
+	var out message
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	type missing struct {
+		A uint8 `order:"2"`
+		B uint8
+	}
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(missing{}, 0); err == nil {
+		t.Error("expected an error for a field without an order tag")
+	}
+	type duplicate struct {
+		A uint8 `order:"1"`
+		B uint8 `order:"1"`
+	}
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(duplicate{}, 0); err == nil {
+		t.Error("expected an error for a duplicate order")
+	}
+}
+
+func TestLenField(t *testing.T) {
+	type header struct {
+		NameLen uint8
+		Name    string `len:"NameLen"`
+		Alias   string `bin:"len=NameLen"`
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(header{NameLen: 4, Name: "ab", Alias: "abc"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{4, 'a', 'b', 0, 0, 'a', 'b', 'c', 0})
+
+	var out header
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != (header{NameLen: 4, Name: "ab", Alias: "abc"}) {
+		t.Errorf("got %+v", out)
+	}
+
+	buf.Reset()
+	if err := binencoder.NewEncoder(buf).Encode(header{Name: "ignored"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{0})
+	out = header{Name: "stale"}
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil || out.Name != "" {
+		t.Errorf("got %+v, %v", out, err)
+	}
+
+	for _, v := range []interface{}{
+		struct {
+			Name string `len:"Missing"`
+		}{},
+		struct {
+			Name    string `len:"NameLen"`
+			NameLen uint8
+		}{},
+		struct {
+			NameLen string
+			Name    string `len:"NameLen"`
+		}{},
+	} {
+		if err := binencoder.NewEncoder(buf).Encode(v, 0); err == nil {
+			t.Errorf("%T: expected an error", v)
+		}
+	}
+}
+
+func TestLenFieldLimits(t *testing.T) {
+	type name struct {
+		N    uint32
+		Name string `len:"N"`
+	}
+	hostile := []byte{0xff, 0xff, 0xff, 0x7f, 'a'}
+	var out name
+	err := binencoder.NewDecoder(bytes.NewReader(hostile), binencoder.WithMaxStringLen(16)).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err = binencoder.NewDecoder(bytes.NewReader(hostile), binencoder.WithMaxMessageSize(64)).Decode(&out, 0)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, binencoder.ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes for a length over the limit", n)
+	}
+}
+
+func TestIntByteOrder(t *testing.T) {
+	type message struct {
+		A uint16
+		B uint32 `len:"6"`
+		C uint16 `bin:"endian=be"`
+	}
+	in := message{A: 0x0102, B: 0x03040506, C: 0x0708}
+	for _, tc := range []struct {
+		opts []binencoder.Option
+		want []byte
+	}{
+		{
+			[]binencoder.Option{binencoder.WithByteOrder(binary.BigEndian)},
+			[]byte{0x02, 0x01, 0, 0, 0x06, 0x05, 0x04, 0x03, 0x07, 0x08},
+		},
+		{
+			[]binencoder.Option{binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true)},
+			[]byte{0x01, 0x02, 0, 0, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		},
+	} {
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf, tc.opts...).Encode(in, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), tc.want)
+
+		var out message
+		if err := binencoder.NewDecoder(buf, tc.opts...).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("got %+v", out)
+		}
+	}
+}
+
+func TestUnknownNamespaceOption(t *testing.T) {
+	for _, v := range []interface{}{
+		struct {
+			A uint16 `bin:"endain=be"`
+		}{},
+		struct {
+			A uint16 `bin:"validate=min=1,max=5"`
+		}{},
+		struct {
+			A uint16 `bin:"2,be"`
+		}{},
+	} {
+		if err := binencoder.CheckType(reflect.TypeOf(v)); err == nil {
+			t.Errorf("%T: expected an error", v)
+		}
+	}
+	var v struct {
+		A uint16 `bin:"2,endian=be"`
+	}
+	if err := binencoder.CheckType(reflect.TypeOf(v)); err != nil {
+		t.Error(err)
+	}
+}
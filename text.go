@@ -0,0 +1,132 @@
+package binencoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+)
+
+// NewHexWriter returns a writer that hex-encodes the bytes written to it. If
+// lineLen is positive, a newline is inserted after every lineLen characters
+// of output. Close terminates the last line; it does not close w.
+func NewHexWriter(w io.Writer, lineLen int) io.WriteCloser {
+	lw := &lineWriter{w: w, lineLen: lineLen}
+	return &textWriter{enc: nopCloser{hex.NewEncoder(lw)}, lw: lw}
+}
+
+// NewBase64Writer returns a writer that encodes the bytes written to it with
+// enc (base64.StdEncoding if nil), splitting the output into lines of lineLen
+// characters if lineLen is positive. Close flushes any partial block and
+// terminates the last line; it does not close w.
+func NewBase64Writer(w io.Writer, enc *base64.Encoding, lineLen int) io.WriteCloser {
+	if enc == nil {
+		enc = base64.StdEncoding
+	}
+	lw := &lineWriter{w: w, lineLen: lineLen}
+	return &textWriter{enc: base64.NewEncoder(enc, lw), lw: lw}
+}
+
+// NewHexReader returns a reader that decodes hex text from r, ignoring
+// whitespace and line breaks.
+func NewHexReader(r io.Reader) io.Reader {
+	return hex.NewDecoder(&spaceSkipper{r: r})
+}
+
+// NewBase64Reader returns a reader that decodes base64 text from r with enc
+// (base64.StdEncoding if nil), ignoring whitespace and line breaks.
+func NewBase64Reader(r io.Reader, enc *base64.Encoding) io.Reader {
+	if enc == nil {
+		enc = base64.StdEncoding
+	}
+	return base64.NewDecoder(enc, &spaceSkipper{r: r})
+}
+
+type textWriter struct {
+	enc io.WriteCloser
+	lw  *lineWriter
+}
+
+func (t *textWriter) Write(p []byte) (int, error) {
+	return t.enc.Write(p)
+}
+
+func (t *textWriter) Close() error {
+	if err := t.enc.Close(); err != nil {
+		return err
+	}
+	return t.lw.endLine()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// lineWriter inserts a newline after every lineLen bytes.
+type lineWriter struct {
+	w       io.Writer
+	lineLen int
+	col     int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	if l.lineLen <= 0 {
+		return l.w.Write(p)
+	}
+	written := 0
+	for len(p) > 0 {
+		if l.col == l.lineLen {
+			if _, err := l.w.Write([]byte{'\n'}); err != nil {
+				return written, err
+			}
+			l.col = 0
+		}
+		n := l.lineLen - l.col
+		if n > len(p) {
+			n = len(p)
+		}
+		m, err := l.w.Write(p[:n])
+		written += m
+		l.col += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (l *lineWriter) endLine() error {
+	if l.lineLen <= 0 || l.col == 0 {
+		return nil
+	}
+	l.col = 0
+	_, err := l.w.Write([]byte{'\n'})
+	return err
+}
+
+// spaceSkipper drops ASCII whitespace from the underlying reader.
+type spaceSkipper struct {
+	r io.Reader
+}
+
+func (s *spaceSkipper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		j := 0
+		for _, c := range p[:n] {
+			switch c {
+			case ' ', '\t', '\r', '\n':
+			default:
+				p[j] = c
+				j++
+			}
+		}
+		if j > 0 || err != nil {
+			return j, err
+		}
+	}
+}
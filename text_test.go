@@ -0,0 +1,52 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type textMessage struct {
+	ID   uint32
+	Name string `len:"8"`
+}
+
+func TestTextTransports(t *testing.T) {
+	in := textMessage{ID: 0x01020304, Name: "binenc"}
+
+	hexBuf := new(bytes.Buffer)
+	hw := binencoder.NewHexWriter(hexBuf, 8)
+	if err := binencoder.NewEncoder(hw, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := hw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, hexBuf.String(), "04030201\n62696e65\n6e630000\n")
+
+	b64Buf := new(bytes.Buffer)
+	bw := binencoder.NewBase64Writer(b64Buf, base64.StdEncoding, 10)
+	if err := binencoder.NewEncoder(bw, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, b64Buf.String(), "BAMCAWJpbm\nVuYwAA\n")
+
+	var fromHex, fromB64 textMessage
+	err := binencoder.NewDecoder(binencoder.NewHexReader(hexBuf), binencoder.WithByteOrder(binary.LittleEndian)).Decode(&fromHex, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = binencoder.NewDecoder(binencoder.NewBase64Reader(b64Buf, nil), binencoder.WithByteOrder(binary.LittleEndian)).Decode(&fromB64, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromHex != in || fromB64 != in {
+		t.Errorf("We have:\n%+v\n got:\n%+v and %+v\n", in, fromHex, fromB64)
+	}
+}
@@ -0,0 +1,146 @@
+package binencoder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 and 1970-01-01.
+const ntpEpochOffset = 2208988800
+
+// zeroTimeWire is the signed integer the zero Time is encoded as, so that
+// 0 stays the Unix epoch. The unsigned formats have no spare value and
+// encode the zero Time as 0.
+const zeroTimeWire = math.MinInt64
+
+// minUnixNano and maxUnixNano bound the times whose nanoseconds since the
+// epoch fit an int64 other than zeroTimeWire, 1677 to 2262.
+var (
+	minUnixNano = time.Unix(0, zeroTimeWire+1)
+	maxUnixNano = time.Unix(0, math.MaxInt64)
+)
+
+// timeToWire converts t to the integer representation selected by a
+// `timefmt` tag. Times out of the range of the format give ErrOverflow.
+func timeToWire(t time.Time, format string) (reflect.Value, error) {
+	zero := t.IsZero()
+	switch format {
+	case "", "unixnano":
+		if zero {
+			return reflect.ValueOf(int64(zeroTimeWire)), nil
+		}
+		if t.Before(minUnixNano) || t.After(maxUnixNano) {
+			return reflect.Value{}, ErrOverflow
+		}
+		return reflect.ValueOf(t.UnixNano()), nil
+	case "unixmilli":
+		if zero {
+			return reflect.ValueOf(int64(zeroTimeWire)), nil
+		}
+		return reflect.ValueOf(t.UnixMilli()), nil
+	case "unix64":
+		if zero {
+			return reflect.ValueOf(int64(zeroTimeWire)), nil
+		}
+		return reflect.ValueOf(t.Unix()), nil
+	case "unix32":
+		if zero {
+			return reflect.ValueOf(uint32(0)), nil
+		}
+		if t.Unix() < 0 || t.Unix() > 1<<32-1 {
+			return reflect.Value{}, ErrOverflow
+		}
+		return reflect.ValueOf(uint32(t.Unix())), nil
+	case "ntp":
+		if zero {
+			return reflect.ValueOf(uint64(0)), nil
+		}
+		sec := t.Unix() + ntpEpochOffset
+		if sec < 0 || sec > 1<<32-1 {
+			return reflect.Value{}, ErrOverflow
+		}
+		frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+		return reflect.ValueOf(uint64(sec)<<32 | frac), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("binencoder: unknown timefmt %q", format)
+	}
+}
+
+// timeWireType returns the integer type a `timefmt` format is encoded as.
+func timeWireType(format string) (reflect.Type, error) {
+	switch format {
+	case "", "unixnano", "unixmilli", "unix64":
+		return reflect.TypeOf(int64(0)), nil
+	case "unix32":
+		return reflect.TypeOf(uint32(0)), nil
+	case "ntp":
+		return reflect.TypeOf(uint64(0)), nil
+	default:
+		return nil, fmt.Errorf("binencoder: unknown timefmt %q", format)
+	}
+}
+
+// wireToTime is the inverse of timeToWire. Times are returned in UTC.
+func wireToTime(v reflect.Value, format string) time.Time {
+	var t time.Time
+	switch format {
+	case "", "unixnano":
+		if v.Int() != zeroTimeWire {
+			t = time.Unix(0, v.Int())
+		}
+	case "unixmilli":
+		if v.Int() != zeroTimeWire {
+			t = time.UnixMilli(v.Int())
+		}
+	case "unix64":
+		if v.Int() != zeroTimeWire {
+			t = time.Unix(v.Int(), 0)
+		}
+	case "unix32":
+		if v.Uint() != 0 {
+			t = time.Unix(int64(v.Uint()), 0)
+		}
+	case "ntp":
+		if v.Uint() != 0 {
+			sec := int64(v.Uint()>>32) - ntpEpochOffset
+			nsec := (v.Uint()&(1<<32-1)*uint64(time.Second) + 1<<31) >> 32
+			t = time.Unix(sec, int64(nsec))
+		}
+	}
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var durationUnits = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// durationToWire converts d to an int64 count of the unit selected by a
+// `durfmt` tag, rounding half away from zero.
+func durationToWire(d time.Duration, format string) (reflect.Value, error) {
+	unit, ok := durationUnits[format]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("binencoder: unknown durfmt %q", format)
+	}
+	return reflect.ValueOf(int64(d.Round(unit) / unit)), nil
+}
+
+func wireToDuration(v reflect.Value, format string) (time.Duration, error) {
+	unit, ok := durationUnits[format]
+	if !ok {
+		return 0, fmt.Errorf("binencoder: unknown durfmt %q", format)
+	}
+	return time.Duration(v.Int()) * unit, nil
+}
@@ -0,0 +1,76 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestTimeTag(t *testing.T) {
+	ts := time.Unix(1600000000, 500000000).UTC()
+
+	buf := new(bytes.Buffer)
+	encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	err := encoder.Encode(struct {
+		Default time.Time
+		Unix32  time.Time `timefmt:"unix32"`
+		Unix64  time.Time `timefmt:"unix64"`
+		Milli   time.Time `timefmt:"unixmilli"`
+		NTP     time.Time `timefmt:"ntp"`
+		Zero    time.Time `timefmt:"unix32"`
+	}{ts, ts, ts, ts, ts, time.Time{}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		0x00, 0x65, 0x6d, 0xf6, 0x85, 0x57, 0x34, 0x16,
+		0x00, 0x10, 0x5e, 0x5f,
+		0x00, 0x10, 0x5e, 0x5f, 0, 0, 0, 0,
+		0xf4, 0x81, 0x6e, 0x87, 0x74, 0x01, 0, 0,
+		0x00, 0x00, 0x00, 0x80, 0x80, 0x8e, 0x08, 0xe3,
+		0, 0, 0, 0,
+	})
+
+	err = encoder.Encode(struct {
+		T time.Time `timefmt:"unix32"`
+	}{time.Unix(-1, 0)}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	type times struct {
+		Nano  time.Time
+		Milli time.Time `timefmt:"unixmilli"`
+		Sec   time.Time `timefmt:"unix64"`
+	}
+	epoch := time.Unix(0, 0).UTC()
+	for _, in := range []times{{epoch, epoch, epoch}, {}} {
+		b, err := binencoder.Marshal(in, binary.LittleEndian)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out times
+		if err := binencoder.Unmarshal(b, &out, binary.LittleEndian); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("we have:\n%+v\n got:\n%+v\n", in, out)
+		}
+	}
+
+	for _, ts := range []time.Time{
+		time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		_, err := binencoder.Marshal(struct{ T time.Time }{ts}, binary.LittleEndian)
+		if !errors.Is(err, binencoder.ErrOverflow) {
+			t.Errorf("%v: expected ErrOverflow, got %v", ts, err)
+		}
+	}
+}
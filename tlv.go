@@ -0,0 +1,283 @@
+package binencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseTLVTag parses the value of a `tlv` tag, e.g. "0x21", returning -1
+// for an empty tag.
+func parseTLVTag(tag string) (int64, error) {
+	if tag == "" {
+		return -1, nil
+	}
+	n, err := strconv.ParseUint(tag, 0, 63)
+	if err != nil {
+		return -1, fmt.Errorf("binencoder: invalid tlv tag %q", tag)
+	}
+	return int64(n), nil
+}
+
+// checkTLV validates the widths set by WithTLV.
+func (c *config) checkTLV() error {
+	switch c.tlvTag {
+	case 1, 2, 4, 8:
+	default:
+		return fmt.Errorf("binencoder: invalid TLV tag width %d", c.tlvTag)
+	}
+	switch c.tlvLen {
+	case 0, 1, 2, 4, 8:
+	default:
+		return fmt.Errorf("binencoder: invalid TLV length width %d", c.tlvLen)
+	}
+	return nil
+}
+
+// appendUint appends x as an unsigned integer of width bytes, laid out like
+// a length prefix.
+func (c *config) appendUint(b []byte, x uint64, width int, what string) ([]byte, error) {
+	if width < 8 && x >= 1<<(8*uint(width)) {
+		return nil, fmt.Errorf("%w: TLV %s %d does not fit %d bytes", ErrOverflow, what, x, width)
+	}
+	var wide [8]byte
+	c.byteOrder.PutUint64(wide[:], x)
+	if c.byteOrder == binary.BigEndian {
+		return append(b, wide[8-width:]...), nil
+	}
+	return append(b, wide[:width]...), nil
+}
+
+// appendLength appends an item length of width bytes, or in BER form if
+// width is 0.
+func (c *config) appendLength(b []byte, n int, width int) ([]byte, error) {
+	if width != 0 {
+		return c.appendUint(b, uint64(n), width, "length")
+	}
+	if n < 0x80 {
+		return append(b, byte(n)), nil
+	}
+	var wide [8]byte
+	binary.BigEndian.PutUint64(wide[:], uint64(n))
+	i := 0
+	for wide[i] == 0 {
+		i++
+	}
+	b = append(b, 0x80|byte(8-i))
+	return append(b, wide[i:]...), nil
+}
+
+// readLength is the inverse of appendLength.
+func (dec *Decoder) readLength(width int) (int, error) {
+	if width != 0 {
+		b := make([]byte, width)
+		if err := dec.readFull(b); err != nil {
+			return 0, err
+		}
+		return decodePrefix(b, dec.byteOrder), nil
+	}
+	b := make([]byte, 1, 9)
+	if err := dec.readFull(b); err != nil {
+		return 0, err
+	}
+	if b[0] < 0x80 {
+		return int(b[0]), nil
+	}
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 8 {
+		return 0, fmt.Errorf("binencoder: invalid BER length byte %#x", b[0])
+	}
+	b = b[:n]
+	if err := dec.readFull(b); err != nil {
+		return 0, err
+	}
+	return decodePrefix(b, binary.BigEndian), nil
+}
+
+// encodeTLV encodes a field with a `tlv` tag as a tag, length and value
+// item. Nil pointers are omitted.
+func (enc *Encoder) encodeTLV(field reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return nil
+	}
+	if err := enc.checkTLV(); err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	value, err := enc.encodeBuffered(field, f, bytesLen, path)
+	if err != nil {
+		return err
+	}
+	key, err := enc.appendUint(enc.scratch[:0], uint64(f.tlv), enc.tlvTag, "tag")
+	if err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	return enc.writeItem(key, value, enc.tlvLen, path, field.Type())
+}
+
+// encodeKLV encodes a field with a `klv` tag as its universal key, a BER
+// length and the value.
+func (enc *Encoder) encodeKLV(field reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	value, err := enc.encodeBuffered(field, f, bytesLen, path)
+	if err != nil {
+		return err
+	}
+	return enc.writeItem(append(enc.scratch[:0], f.klv...), value, 0, path, field.Type())
+}
+
+// encodeBuffered encodes the value of a field into a buffer of its own, so
+// that its length can be written before it.
+func (enc *Encoder) encodeBuffered(field reflect.Value, f *fieldPlan, bytesLen int, path int) ([]byte, error) {
+	var value bytes.Buffer
+	w, n, trace, layout := enc.w, enc.n, enc.trace, enc.layout
+	enc.w, enc.trace, enc.layout = &value, nil, nil
+	err := enc.encodeFieldValue(field, f, bytesLen, path)
+	enc.w, enc.n, enc.trace, enc.layout = w, n, trace, layout
+	return value.Bytes(), err
+}
+
+// writeItem writes key, the length of value in width bytes (BER if 0) and
+// value. key must be held in enc.scratch.
+func (enc *Encoder) writeItem(key, value []byte, width int, path int, t reflect.Type) error {
+	header, err := enc.appendLength(key, len(value), width)
+	if err != nil {
+		return enc.fail(path, t, err)
+	}
+	enc.scratch = header
+	if err := enc.write(header); err != nil {
+		return enc.fail(path, t, err)
+	}
+	if err := enc.write(value); err != nil {
+		return enc.fail(path, t, err)
+	}
+	return nil
+}
+
+// decodeTLV reads TLV items until the end of the input and decodes them
+// into the fields of plan, the trailing fields of struct v, by tag. Items
+// with unknown tags are skipped. Strings and byte slices without a length
+// take the length of their item.
+func (dec *Decoder) decodeTLV(v reflect.Value, plan []fieldPlan, bytesLen int, path string) error {
+	if err := dec.checkTLV(); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	key := make([]byte, dec.tlvTag)
+	for {
+		if err := dec.reserve(len(key)); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		n, err := io.ReadFull(dec.r, key)
+		dec.n += n
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return newDecodeError(path, v.Type(), ErrShortMessage)
+		}
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		tag := int64(decodePrefix(key, dec.byteOrder))
+		size, err := dec.readLength(dec.tlvLen)
+		if err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		f := findTLV(plan, tag)
+		if err := dec.reserve(size); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		if f == nil {
+			dec.logger.Printf("[decodeTLV] skipping unknown tag %#x of %d bytes", tag, size)
+			skipped, err := io.CopyN(ioutil.Discard, dec.r, int64(size))
+			dec.n += int(skipped)
+			if err != nil {
+				return newDecodeError(path, v.Type(), ErrShortMessage)
+			}
+			continue
+		}
+		if err := dec.decodeItem(v.Field(f.index), f, size, bytesLen, joinPath(path, f.name)); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeKLV is the inverse of Encoder.encodeKLV. A different key fails with
+// an error matching ErrBadMagic.
+func (dec *Decoder) decodeKLV(field reflect.Value, f *fieldPlan, bytesLen int, path string) error {
+	key := make([]byte, len(f.klv))
+	if err := dec.readFull(key); err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	if !bytes.Equal(key, f.klv) {
+		return newDecodeError(path, field.Type(), fmt.Errorf("%w: KLV key % x", ErrBadMagic, key))
+	}
+	size, err := dec.readLength(0)
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	return dec.decodeItem(field, f, size, bytesLen, path)
+}
+
+// decodeItem decodes a field from the size bytes of its TLV or KLV item,
+// which must all be consumed.
+func (dec *Decoder) decodeItem(field reflect.Value, f *fieldPlan, size, bytesLen int, path string) error {
+	if err := dec.checkLen(size, field.Kind()); err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	var value bytes.Buffer
+	copied, err := io.CopyN(&value, dec.r, int64(size))
+	dec.n += int(copied)
+	if err == io.EOF {
+		err = ErrShortMessage
+	}
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	if f.fieldLen(bytesLen) == 0 {
+		switch {
+		case field.Kind() == reflect.String:
+			field.SetString(string(make([]byte, size)))
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+			field.Set(reflect.MakeSlice(field.Type(), size, size))
+		}
+	}
+	r := bytes.NewReader(value.Bytes())
+	sub := *dec
+	sub.r = r
+	err = sub.decodeFieldValue(field, f, bytesLen, path)
+	dec.steps = sub.steps
+	if err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return newDecodeError(path, field.Type(), fmt.Errorf("binencoder: %d bytes left in item", r.Len()))
+	}
+	return dec.validate(field, f, path)
+}
+
+func findTLV(plan []fieldPlan, tag int64) *fieldPlan {
+	for i := range plan {
+		if plan[i].tlv == tag {
+			return &plan[i]
+		}
+	}
+	return nil
+}
+
+// parseKLVKey parses the value of a `klv` tag, a 16-byte universal key in
+// hex with optional dots, e.g. "06.0E.2B.34.02.0B.01.01.0E.01.03.01.01.00.00.00".
+func parseKLVKey(tag string) ([]byte, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(strings.Replace(tag, ".", "", -1))
+	if err != nil || len(key) != 16 {
+		return nil, fmt.Errorf("binencoder: invalid klv key %q", tag)
+	}
+	return key, nil
+}
@@ -0,0 +1,107 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type tlvRecord struct {
+	Type   uint8
+	Amount *uint32 `tlv:"0x9f02"`
+	Name   string  `tlv:"0x5f20"`
+	Data   []byte  `tlv:"0x70"`
+	Code   *uint16 `tlv:"0x9f1a"`
+}
+
+func TestTLV(t *testing.T) {
+	amount := uint32(1000)
+	in := tlvRecord{Type: 1, Amount: &amount, Name: "IVAN", Data: []byte{1, 2, 3}}
+	buf := new(bytes.Buffer)
+	opts := []binencoder.Option{binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true), binencoder.WithTLV(2, 1)}
+	if err := binencoder.NewEncoder(buf, opts...).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		1,
+		0x9f, 0x02, 4, 0, 0, 0x03, 0xe8,
+		0x5f, 0x20, 4, 'I', 'V', 'A', 'N',
+		0x00, 0x70, 3, 1, 2, 3,
+	})
+
+	// An unknown item in the middle is skipped.
+	data := append(buf.Bytes()[:15:15], 0xdf, 0x01, 2, 9, 9)
+	data = append(data, buf.Bytes()[15:]...)
+	var out tlvRecord
+	if err := binencoder.NewDecoder(bytes.NewReader(data), opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	_, err := binencoder.Marshal(struct {
+		A uint8 `tlv:"1"`
+		B uint8
+	}{}, binary.BigEndian, binencoder.WithIntByteOrder(true))
+	if err == nil {
+		t.Error("expected an error for a field after TLV fields")
+	}
+
+	err = binencoder.NewEncoder(new(bytes.Buffer)).Encode(struct {
+		A []byte `tlv:"1"`
+	}{make([]byte, 256)}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{1, 4, 0})).Decode(&struct {
+		A uint32 `tlv:"1"`
+	}{}, 0)
+	if !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage, got %v", err)
+	}
+}
+
+func TestKLV(t *testing.T) {
+	type localSet struct {
+		Timestamp uint64 `tlv:"2"`
+		Mission   string `tlv:"3"`
+		Notes     []byte `tlv:"4"`
+	}
+	type packet struct {
+		UAS localSet `klv:"06.0E.2B.34.02.0B.01.01.0E.01.03.01.01.00.00.00"`
+	}
+	in := packet{localSet{Timestamp: 0x0102030405060708, Mission: "M1", Notes: bytes.Repeat([]byte{'n'}, 200)}}
+	opts := []binencoder.Option{binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true), binencoder.WithTLV(1, 0)}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, opts...).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	key := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x0b, 0x01, 0x01, 0x0e, 0x01, 0x03, 0x01, 0x01, 0x00, 0x00, 0x00}
+	want := append(key, 0x81, 217)
+	want = append(want, 2, 8, 1, 2, 3, 4, 5, 6, 7, 8)
+	want = append(want, 3, 2, 'M', '1')
+	want = append(want, 4, 0x81, 200)
+	want = append(want, in.UAS.Notes...)
+	equalByte(t, buf.Bytes(), want)
+
+	var out packet
+	if err := binencoder.NewDecoder(bytes.NewReader(buf.Bytes()), opts...).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	bad := append([]byte(nil), buf.Bytes()...)
+	bad[15] = 1
+	err := binencoder.NewDecoder(bytes.NewReader(bad), opts...).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrBadMagic) {
+		t.Errorf("expected ErrBadMagic, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// traceField writes the trace line of the field at path, which was encoded
+// from offset start on from the value v.
+func (enc *Encoder) traceField(start int, path int, v reflect.Value, f *fieldPlan) {
+	enc.traced++
+	fmt.Fprintf(enc.trace, "%6d %4d %s:", start, enc.n-start, enc.pathBuf[:path])
+	if enc.n > start {
+		fmt.Fprintf(enc.trace, " % x", enc.traceBuf[start:enc.n])
+	}
+	if f.enum != nil {
+		fmt.Fprintf(enc.trace, " (%s)", f.enum.name(v))
+	}
+	fmt.Fprintln(enc.trace)
+}
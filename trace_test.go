@@ -0,0 +1,52 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestWithTrace(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type message struct {
+		Version uint8
+		Flags   uint16 `endian:"be"`
+		Points  []point
+		Name    string `len:"4"`
+		Skipped uint8  `len:"-"`
+		Extra   []byte `tlv:"0x21"`
+	}
+	var trace, out bytes.Buffer
+	enc := binencoder.NewEncoder(&out, binencoder.WithTrace(&trace))
+	err := enc.Encode(message{
+		Version: 1,
+		Flags:   2,
+		Points:  []point{{1, -1}, {2, 3}},
+		Name:    "ab",
+		Extra:   []byte{9},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, trace.String(), `     0    1 Version: 01
+     1    2 Flags: 00 02
+     3    2 Points[0].X: 01 00
+     5    2 Points[0].Y: ff ff
+     7    2 Points[1].X: 02 00
+     9    2 Points[1].Y: 03 00
+    11    4 Name: 61 62 00 00
+    15    0 Skipped:
+    15    3 Extra: 21 01 09
+`)
+
+	// The trace starts over with every message.
+	trace.Reset()
+	type single struct{ A uint32 }
+	if err := enc.Encode(single{5}, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, trace.String(), "     0    4 A: 05 00 00 00\n")
+}
@@ -0,0 +1,100 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Plan describes the layout of the frames in a stream.
+type Plan struct {
+	// Layout is a struct value each frame is decoded into or encoded
+	// from. Slices and strings without a length keep its lengths.
+	Layout   interface{}
+	BytesLen int
+	Options  []Option
+
+	// Version is the protocol version of the layout. Migrations, if set on
+	// the target plan, upgrade frames from the source plan's version to
+	// the current version of the migration set.
+	Version    int
+	Migrations *Migrations
+}
+
+// Transcode reads frames laid out by from until r is exhausted and writes
+// them to w laid out by to. Fields are matched by name, after migrations if
+// the versions differ; fields missing from the source are zeroed. It
+// returns the number of frames written.
+func Transcode(r io.Reader, w io.Writer, from, to Plan) (int, error) {
+	src, err := planValue(from)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := planValue(to)
+	if err != nil {
+		return 0, err
+	}
+	dec := NewDecoder(r, from.Options...)
+	enc := NewEncoder(w, to.Options...)
+	for n := 0; ; n++ {
+		src.Elem().Set(reflect.ValueOf(from.Layout))
+		if err := dec.Decode(src.Interface(), from.BytesLen); err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, fmt.Errorf("binencoder: frame %d: %w", n, err)
+		}
+		fields := structToMap(src.Elem())
+		if to.Migrations != nil && from.Version != to.Migrations.current {
+			if fields, err = to.Migrations.upgrade(fields, from.Version); err != nil {
+				return n, err
+			}
+		}
+		dst.Elem().Set(reflect.ValueOf(to.Layout))
+		if err := mapToStruct(fields, dst.Elem()); err != nil {
+			return n, fmt.Errorf("binencoder: frame %d: %w", n, err)
+		}
+		if err := enc.Encode(dst.Elem().Interface(), to.BytesLen); err != nil {
+			return n, fmt.Errorf("binencoder: frame %d: %w", n, err)
+		}
+	}
+}
+
+// planValue returns a pointer to a new value of the plan's layout type.
+func planValue(p Plan) (reflect.Value, error) {
+	t := reflect.TypeOf(p.Layout)
+	if t == nil || t.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("binencoder: plan layout must be a struct, got %T", p.Layout)
+	}
+	return reflect.New(t), nil
+}
+
+// TranscodeByteOrder reads messages laid out like schema in byte order
+// from from src until it is exhausted and writes them to dst in byte order to,
+// e.g. to move archived captures between platforms. Numbers are read and
+// written in these byte orders as with WithIntByteOrder; opts are applied
+// to both sides after them. Slices and strings without a length keep the
+// lengths they have in schema. It is Transcode for a layout that only
+// changes its byte order, and returns the number of messages written.
+func TranscodeByteOrder(dst io.Writer, src io.Reader, schema interface{}, from, to binary.ByteOrder, opts ...Option) (int, error) {
+	t := reflect.TypeOf(schema)
+	if t == nil {
+		return 0, fmt.Errorf("binencoder: transcoding needs a schema value")
+	}
+	v := reflect.New(t)
+	dec := NewDecoder(src, append([]Option{WithByteOrder(from), WithIntByteOrder(true)}, opts...)...)
+	enc := NewEncoder(dst, append([]Option{WithByteOrder(to), WithIntByteOrder(true)}, opts...)...)
+	for n := 0; ; n++ {
+		v.Elem().Set(reflect.ValueOf(schema))
+		if err := dec.Decode(v.Interface(), 0); err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, fmt.Errorf("binencoder: frame %d: %w", n, err)
+		}
+		if err := enc.Encode(v.Interface(), 0); err != nil {
+			return n, fmt.Errorf("binencoder: frame %d: %w", n, err)
+		}
+	}
+}
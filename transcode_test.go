@@ -0,0 +1,89 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestTranscode(t *testing.T) {
+	in := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(in, binencoder.WithByteOrder(binary.BigEndian))
+	enc.Encode(recordV1{ID: 7, Temp: -5}, 0)
+	enc.Encode(recordV1{ID: 8, Temp: 21}, 0)
+
+	m := binencoder.NewMigrations(3)
+	m.Migrate(1, 2, func(old map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"ID": old["ID"], "TempC": old["Temp"]}
+	})
+	m.Migrate(2, 3, func(old map[string]interface{}) map[string]interface{} {
+		old["Unit"] = "C"
+		return old
+	})
+
+	out := new(bytes.Buffer)
+	n, err := binencoder.Transcode(in, out,
+		binencoder.Plan{Layout: recordV1{}, Version: 1, Options: []binencoder.Option{binencoder.WithByteOrder(binary.BigEndian)}},
+		binencoder.Plan{Layout: recordV3{}, Version: 3, Migrations: m},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("transcoded %d frames", n)
+	}
+
+	dec := binencoder.NewDecoder(out)
+	for _, want := range []recordV3{{7, -5, "C"}, {8, 21, "C"}} {
+		var got recordV3
+		if err := dec.Decode(&got, 0); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("we have:\n%+v\n got:\n%+v\n", want, got)
+		}
+	}
+}
+
+func TestTranscodeByName(t *testing.T) {
+	in := bytes.NewReader([]byte{1, 0, 2, 0, 3, 0})
+	out := new(bytes.Buffer)
+	_, err := binencoder.Transcode(in, out,
+		binencoder.Plan{Layout: recordV1{}},
+		binencoder.Plan{Layout: recordV2{}},
+	)
+	if err == nil {
+		t.Error("expected an error for a truncated frame")
+	}
+	equalByte(t, out.Bytes(), []byte{1, 0, 0, 0, 0, 0})
+}
+
+func TestTranscodeByteOrder(t *testing.T) {
+	type capture struct {
+		ID      uint16
+		Samples [2]int32
+		Tag     string
+	}
+	in := new(bytes.Buffer)
+	enc := binencoder.NewEncoder(in, binencoder.WithByteOrder(binary.BigEndian), binencoder.WithIntByteOrder(true))
+	enc.Encode(capture{ID: 1, Samples: [2]int32{-1, 2}, Tag: "ab"}, 0)
+	enc.Encode(capture{ID: 2, Samples: [2]int32{3, 4}, Tag: "cd"}, 0)
+
+	out := new(bytes.Buffer)
+	n, err := binencoder.TranscodeByteOrder(out, in, capture{Tag: "xx"}, binary.BigEndian, binary.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("transcoded %d frames", n)
+	}
+	equalByte(t, out.Bytes()[:12], []byte{1, 0, 0xff, 0xff, 0xff, 0xff, 2, 0, 0, 0, 'a', 'b'})
+	equalByte(t, out.Bytes()[12:], []byte{2, 0, 3, 0, 0, 0, 4, 0, 0, 0, 'c', 'd'})
+
+	_, err = binencoder.TranscodeByteOrder(out, bytes.NewReader([]byte{1}), uint16(0), binary.BigEndian, binary.LittleEndian)
+	if err == nil {
+		t.Error("expected an error for a truncated frame")
+	}
+}
@@ -0,0 +1,115 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// TransformFunc maps the value of a field to the value written in its
+// place, e.g. a masked card number, or back. It must return a value of the
+// field's type.
+type TransformFunc func(v interface{}) (interface{}, error)
+
+type transform struct {
+	enc TransformFunc
+	dec TransformFunc
+}
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]transform{
+		"mask": {enc: maskString},
+	}
+)
+
+// RegisterTransform makes fields tagged `transform:"name"` encoded as the
+// value enc returns, so that sensitive data is redacted or hashed while
+// encoding instead of in a separate pass. dec, if not nil, reverses enc
+// after decoding; without it decoded fields hold the transformed value.
+// The field's `validate` and `enum` tags apply to the value before enc and
+// after dec.
+//
+// The built-in "mask" transform replaces all but the last four characters
+// of strings with '*'.
+func RegisterTransform(name string, enc, dec TransformFunc) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = transform{enc: enc, dec: dec}
+}
+
+func lookupTransform(name string) (transform, error) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	t, ok := transforms[name]
+	if !ok {
+		return t, fmt.Errorf("binencoder: unknown transform %q", name)
+	}
+	return t, nil
+}
+
+// maskString masks all but the last four characters of a string.
+func maskString(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: mask transform of %T", ErrUnknownType, v)
+	}
+	keep := 4
+	n := utf8.RuneCountInString(s)
+	if n <= keep {
+		return s, nil
+	}
+	i := 0
+	for j := 0; j < n-keep; j++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return strings.Repeat("*", n-keep) + s[i:], nil
+}
+
+// applyTransform returns the result of fn for v, converted to v's type.
+func applyTransform(fn TransformFunc, name string, v reflect.Value) (reflect.Value, error) {
+	out, err := fn(v.Interface())
+	if err != nil {
+		return v, err
+	}
+	rv := reflect.ValueOf(out)
+	if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+		return v, fmt.Errorf("binencoder: transform %q returned %T for %s", name, out, v.Type())
+	}
+	res := reflect.New(v.Type()).Elem()
+	res.Set(rv)
+	return res, nil
+}
+
+// transformField returns the value field f is encoded as.
+func (enc *Encoder) transformField(field reflect.Value, f *fieldPlan, path int) (reflect.Value, error) {
+	t, err := lookupTransform(f.transform)
+	if err == nil {
+		field, err = applyTransform(t.enc, f.transform, field)
+	}
+	if err != nil {
+		return field, enc.fail(path, field.Type(), err)
+	}
+	return field, nil
+}
+
+// untransformField reverses the transform of field f after decoding, if
+// it has a reverse.
+func (dec *Decoder) untransformField(field reflect.Value, f *fieldPlan, path string) error {
+	t, err := lookupTransform(f.transform)
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	if t.dec == nil {
+		return nil
+	}
+	v, err := applyTransform(t.dec, f.transform, field)
+	if err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	field.Set(v)
+	return nil
+}
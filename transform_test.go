@@ -0,0 +1,59 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestTransform(t *testing.T) {
+	const key = 0x5a5a5a5a
+	scramble := func(v interface{}) (interface{}, error) {
+		return v.(uint32) ^ key, nil
+	}
+	binencoder.RegisterTransform("test-scramble", scramble, scramble)
+	binencoder.RegisterTransform("test-upper", func(v interface{}) (interface{}, error) {
+		return strings.ToUpper(v.(string)), nil
+	}, nil)
+
+	type payment struct {
+		PAN      string `len:"16" transform:"mask"`
+		Customer uint32 `transform:"test-scramble"`
+		Note     string `len:"4" transform:"test-upper"`
+	}
+	in := payment{PAN: "4111111111111234", Customer: 1, Note: "ok"}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(&in, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte("************1234"), 0x5b, 0x5a, 0x5a, 0x5a)
+	want = append(want, "OK\x00\x00"...)
+	equalByte(t, buf.Bytes(), want)
+	if in.PAN != "4111111111111234" {
+		t.Errorf("encoding changed the value to %q", in.PAN)
+	}
+
+	var out payment
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.PAN != "************1234" || out.Customer != 1 || out.Note != "OK" {
+		t.Errorf("got %+v", out)
+	}
+
+	type unknown struct {
+		ID uint32 `transform:"test-missing"`
+	}
+	if err := binencoder.NewEncoder(buf).Encode(unknown{}, 0); err == nil {
+		t.Error("expected an error for an unknown transform")
+	}
+	type wrongType struct {
+		ID uint32 `transform:"mask"`
+	}
+	if err := binencoder.NewEncoder(buf).Encode(wrongType{}, 0); !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
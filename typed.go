@@ -0,0 +1,56 @@
+//go:build go1.18
+// +build go1.18
+
+package binencoder
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+)
+
+// TypedEncoder encodes values of type T. It builds the plans of the
+// structs reachable from T when it is created and encodes each value from
+// a copy it holds, so that Encode does not box v into an interface and
+// does not allocate for values that Encoder encodes without allocating.
+// Like Encoder, it must not be used by several goroutines at once.
+type TypedEncoder[T any] struct {
+	enc *Encoder
+	v   T
+	// val is the reflect.Value of v, made once.
+	val reflect.Value
+}
+
+// NewTypedEncoder returns a TypedEncoder writing values of type T to w in
+// the given byte order. The options are those of NewEncoder.
+func NewTypedEncoder[T any](w io.Writer, order binary.ByteOrder, opts ...Option) *TypedEncoder[T] {
+	// The full slice expression makes append copy opts rather than write
+	// into the caller's array.
+	te := &TypedEncoder[T]{enc: NewEncoder(w, append(opts[:len(opts):len(opts)], WithByteOrder(order))...)}
+	te.val = reflect.ValueOf(&te.v).Elem()
+	// Checking the type builds its plans; the problems it finds are
+	// reported by Encode as for any Encoder.
+	_ = te.enc.checkType(te.val.Type(), fieldTags{}, "", map[reflect.Type]bool{}, map[reflect.Type]bool{})
+	return te
+}
+
+// Encode writes v as one message.
+func (te *TypedEncoder[T]) Encode(v T) error {
+	_, err := te.EncodeN(v)
+	return err
+}
+
+// EncodeN is like Encode and also returns the number of bytes written,
+// including those written before an error.
+func (te *TypedEncoder[T]) EncodeN(v T) (int, error) {
+	te.v = v
+	n, err := te.enc.encodeValue(te.val, 0)
+	var zero T
+	te.v = zero
+	return n, err
+}
+
+// Encoder returns the Encoder te writes with, e.g. to Flush or Reset it.
+func (te *TypedEncoder[T]) Encoder() *Encoder {
+	return te.enc
+}
@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestTypedEncoder(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type reading struct {
+		ID     uint32
+		Name   string `len:"4"`
+		Points [2]point
+	}
+	in := reading{ID: 7, Name: "abc", Points: [2]point{{1, -1}, {2, -2}}}
+	want := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(want, binencoder.WithByteOrder(binary.BigEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	enc := binencoder.NewTypedEncoder[reading](buf, binary.BigEndian)
+	n, err := enc.EncodeN(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != want.Len() {
+		t.Errorf("EncodeN returned %d, want %d", n, want.Len())
+	}
+	equalByte(t, buf.Bytes(), want.Bytes())
+
+	enc.Encoder().Reset(ioutil.Discard)
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := enc.Encode(in); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Encode allocates %.0f times per call", allocs)
+	}
+
+	bad := binencoder.NewTypedEncoder[struct {
+		Name string `len:"2"`
+	}](ioutil.Discard, binary.LittleEndian)
+	err = bad.Encode(struct {
+		Name string `len:"2"`
+	}{"abc"})
+	if !errors.Is(err, binencoder.ErrFieldTooLong) {
+		t.Errorf("expected ErrFieldTooLong, got %v", err)
+	}
+
+	// The byte order must not be appended into the caller's options.
+	opts := make([]binencoder.Option, 1, 2)
+	opts[0] = binencoder.WithPadByte(' ')
+	spare := opts[:2]
+	binencoder.NewTypedEncoder[uint16](ioutil.Discard, binary.BigEndian, opts...)
+	if spare[1] != nil {
+		t.Error("NewTypedEncoder wrote into the options array")
+	}
+}
@@ -0,0 +1,76 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	typeIDsMu sync.RWMutex
+	typesByID = map[uint64]reflect.Type{}
+	idsByType = map[reflect.Type]uint64{}
+)
+
+// RegisterTypeID registers the concrete type of sample under id for
+// interface fields with a `typeid:"u8|u16|u32|u64"` tag. Such fields are
+// encoded as the ID of the type of their value, an unsigned integer of the
+// tag's width, followed by the value; decoding constructs a value of the
+// registered type.
+func RegisterTypeID(id uint64, sample interface{}) {
+	t := reflect.TypeOf(sample)
+	typeIDsMu.Lock()
+	defer typeIDsMu.Unlock()
+	typesByID[id] = t
+	idsByType[t] = id
+}
+
+// encodeTypeID encodes the interface value v preceded by its type ID.
+func (enc *Encoder) encodeTypeID(v reflect.Value, bytesLen int, tags fieldTags, path int) error {
+	if v.IsNil() {
+		return enc.fail(path, v.Type(), fmt.Errorf("%w: nil interface", ErrInvalidValue))
+	}
+	elem := v.Elem()
+	typeIDsMu.RLock()
+	id, ok := idsByType[elem.Type()]
+	typeIDsMu.RUnlock()
+	if !ok {
+		return enc.fail(path, elem.Type(), fmt.Errorf("%w: no type ID registered", ErrUnknownType))
+	}
+	p, err := encodePrefix(int(id), tags.typeid, enc.byteOrder)
+	if err != nil {
+		return enc.fail(path, elem.Type(), err)
+	}
+	if err := enc.write(p); err != nil {
+		return enc.fail(path, elem.Type(), err)
+	}
+	return enc.encode(elem, bytesLen, tags, path)
+}
+
+// decodeTypeID is the inverse of Encoder.encodeTypeID.
+func (dec *Decoder) decodeTypeID(v reflect.Value, bytesLen int, tags fieldTags, path string) error {
+	width, err := prefixWidth(tags.typeid)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	p := make([]byte, width)
+	if err := dec.readFull(p); err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	id := uint64(decodePrefix(p, dec.byteOrder))
+	typeIDsMu.RLock()
+	t, ok := typesByID[id]
+	typeIDsMu.RUnlock()
+	if !ok {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: unknown type ID %d", ErrUnknownType, id))
+	}
+	if !t.AssignableTo(v.Type()) {
+		return newDecodeError(path, v.Type(), fmt.Errorf("%w: type ID %d is %s", ErrUnknownType, id, t))
+	}
+	elem := reflect.New(t).Elem()
+	if err := dec.decode(elem, bytesLen, tags, path); err != nil {
+		return err
+	}
+	v.Set(elem)
+	return nil
+}
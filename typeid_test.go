@@ -0,0 +1,60 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type typeIDRecord interface{}
+
+type typeIDLogin struct {
+	User string `len:"4"`
+}
+
+type typeIDLogout struct {
+	Code uint8
+}
+
+func init() {
+	binencoder.RegisterTypeID(1, typeIDLogin{})
+	binencoder.RegisterTypeID(2, &typeIDLogout{})
+}
+
+func TestTypeID(t *testing.T) {
+	type file struct {
+		Records []typeIDRecord `typeid:"u16"`
+	}
+	in := file{Records: []typeIDRecord{typeIDLogin{"ann"}, &typeIDLogout{7}}}
+	b, err := binencoder.Marshal(in, binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, b, []byte{0, 1, 0, 'a', 'n', 'n', 0, 2, 7})
+
+	out := file{Records: make([]typeIDRecord, 2)}
+	if err := binencoder.Unmarshal(b, &out, binary.BigEndian); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %#v, want %#v", out, in)
+	}
+
+	_, err = binencoder.Marshal(file{Records: []typeIDRecord{uint8(1)}}, binary.BigEndian)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType for an unregistered type, got %v", err)
+	}
+	_, err = binencoder.Marshal(file{Records: []typeIDRecord{nil}}, binary.BigEndian)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for a nil record, got %v", err)
+	}
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0, 9})).Decode(&file{Records: make([]typeIDRecord, 1)}, 0)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType for an unknown ID, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding Records[0] (binencoder_test.typeIDRecord): unsupported type: unknown type ID 2304")
+}
@@ -0,0 +1,194 @@
+package binencoder
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type unit struct {
+	dimension string
+	factor    float64
+}
+
+var (
+	unitsMu sync.RWMutex
+	units   = map[string]unit{
+		"ns":  {"time", 1e-9},
+		"us":  {"time", 1e-6},
+		"ms":  {"time", 1e-3},
+		"s":   {"time", 1},
+		"min": {"time", 60},
+		"h":   {"time", 3600},
+
+		"nm": {"length", 1e-9},
+		"um": {"length", 1e-6},
+		"mm": {"length", 1e-3},
+		"cm": {"length", 1e-2},
+		"dm": {"length", 1e-1},
+		"m":  {"length", 1},
+		"km": {"length", 1e3},
+
+		"mg": {"mass", 1e-6},
+		"g":  {"mass", 1e-3},
+		"kg": {"mass", 1},
+		"t":  {"mass", 1e3},
+
+		"Hz":  {"frequency", 1},
+		"kHz": {"frequency", 1e3},
+		"MHz": {"frequency", 1e6},
+		"GHz": {"frequency", 1e9},
+
+		"uV": {"voltage", 1e-6},
+		"mV": {"voltage", 1e-3},
+		"V":  {"voltage", 1},
+		"kV": {"voltage", 1e3},
+
+		"uA": {"current", 1e-6},
+		"mA": {"current", 1e-3},
+		"A":  {"current", 1},
+
+		"mW": {"power", 1e-3},
+		"W":  {"power", 1},
+		"kW": {"power", 1e3},
+		"MW": {"power", 1e6},
+	}
+)
+
+// RegisterUnit adds a named unit usable in `unit` tags. Units of the same
+// dimension convert into each other by the ratio of their factors.
+func RegisterUnit(name, dimension string, factor float64) {
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	units[name] = unit{dimension: dimension, factor: factor}
+}
+
+// parseUnitTag parses a `unit:"from->to"` tag and returns the factor that
+// converts a Go value into a wire value.
+func parseUnitTag(tag string) (float64, error) {
+	parts := strings.Split(tag, "->")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("binencoder: bad unit tag %q", tag)
+	}
+	unitsMu.RLock()
+	from, okFrom := units[strings.TrimSpace(parts[0])]
+	to, okTo := units[strings.TrimSpace(parts[1])]
+	unitsMu.RUnlock()
+	if !okFrom || !okTo {
+		return 0, fmt.Errorf("binencoder: unknown unit in tag %q", tag)
+	}
+	if from.dimension != to.dimension {
+		return 0, fmt.Errorf("binencoder: incompatible units in tag %q", tag)
+	}
+	return from.factor / to.factor, nil
+}
+
+// convertUnit returns a copy of v, a number or an array/slice of numbers,
+// multiplied by ratio. Integers are rounded half away from zero; results
+// that do not fit their type fail with ErrOverflow.
+func convertUnit(v reflect.Value, ratio float64) (reflect.Value, error) {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			el, err := convertUnit(v.Index(i), ratio)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(el)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			el, err := convertUnit(v.Index(i), ratio)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(el)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, ok := scaleInt(v.Int(), ratio)
+		if !ok || out.OverflowInt(x) {
+			return v, fmt.Errorf("%w: %d scaled by %g", ErrOverflow, v.Int(), ratio)
+		}
+		out.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, ok := scaleUint(v.Uint(), ratio)
+		if !ok || out.OverflowUint(x) {
+			return v, fmt.Errorf("%w: %d scaled by %g", ErrOverflow, v.Uint(), ratio)
+		}
+		out.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		out.SetFloat(v.Float() * ratio)
+	default:
+		return v, fmt.Errorf("binencoder: unit conversion of %s", v.Type())
+	}
+	return out, nil
+}
+
+// scaleInt multiplies or divides by an integral ratio exactly and falls back
+// to floating point arithmetic otherwise. It reports false if the result
+// does not fit an int64.
+func scaleInt(x int64, ratio float64) (int64, bool) {
+	if mul := math.Round(ratio); ratio >= 1 && math.Abs(ratio-mul) < 1e-9*mul {
+		if x == 0 {
+			return 0, true
+		}
+		if mul >= 1<<63 {
+			return 0, false
+		}
+		m := int64(mul)
+		if x > math.MaxInt64/m || x < math.MinInt64/m {
+			return 0, false
+		}
+		return x * m, true
+	}
+	if div := math.Round(1 / ratio); ratio < 1 && math.Abs(1/ratio-div) < 1e-9*div {
+		d := int64(div)
+		q, r := x/d, x%d
+		if 2*r >= d {
+			q++
+		} else if 2*r <= -d {
+			q--
+		}
+		return q, true
+	}
+	f := math.Round(float64(x) * ratio)
+	if f >= 1<<63 || f < -(1<<63) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// scaleUint is scaleInt for unsigned numbers.
+func scaleUint(x uint64, ratio float64) (uint64, bool) {
+	if mul := math.Round(ratio); ratio >= 1 && math.Abs(ratio-mul) < 1e-9*mul {
+		if x == 0 {
+			return 0, true
+		}
+		if mul >= 1<<64 {
+			return 0, false
+		}
+		hi, lo := bits.Mul64(x, uint64(mul))
+		return lo, hi == 0
+	}
+	if div := math.Round(1 / ratio); ratio < 1 && math.Abs(1/ratio-div) < 1e-9*div {
+		d := uint64(div)
+		q, r := x/d, x%d
+		if r >= d-r {
+			q++
+		}
+		return q, true
+	}
+	f := math.Round(float64(x) * ratio)
+	if f >= 1<<64 {
+		return 0, false
+	}
+	return uint64(f), true
+}
@@ -0,0 +1,76 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestUnitTag(t *testing.T) {
+	binencoder.RegisterUnit("dm3", "volume", 1e-3)
+	binencoder.RegisterUnit("m3", "volume", 1)
+
+	buf := new(bytes.Buffer)
+	encoder := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian))
+	err := encoder.Encode(struct {
+		Timeout  uint32    `unit:"ms->s"`
+		Length   int16     `unit:"m->cm"`
+		Samples  [2]uint16 `unit:"kHz->Hz"`
+		Negative int32     `unit:"ms->s"`
+		Volume   uint16    `unit:"m3->dm3"`
+	}{
+		Timeout:  2500,
+		Length:   -3,
+		Samples:  [2]uint16{1, 2},
+		Negative: -1500,
+		Volume:   2,
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{
+		3, 0, 0, 0,
+		0xd4, 0xfe,
+		0xe8, 0x03, 0xd0, 0x07,
+		0xfe, 0xff, 0xff, 0xff,
+		0xd0, 0x07,
+	})
+
+	err = encoder.Encode(struct {
+		Bad uint32 `unit:"ms->m"`
+	}{}, 0)
+	if err == nil {
+		t.Error("expected an error for incompatible units")
+	}
+}
+
+func TestUnitOverflow(t *testing.T) {
+	for _, in := range []interface{}{
+		struct {
+			Length int16 `unit:"m->mm"`
+		}{Length: 40},
+		struct {
+			Uptime int64 `unit:"h->ns"`
+		}{Uptime: 3000000},
+		struct {
+			Uptime int64 `unit:"h->ns"`
+		}{Uptime: -3000000},
+		struct {
+			Uptime uint64 `unit:"h->ns"`
+		}{Uptime: 6000000},
+	} {
+		err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(in, 0)
+		if !errors.Is(err, binencoder.ErrOverflow) {
+			t.Errorf("%+v: expected ErrOverflow, got %v", in, err)
+		}
+	}
+	in := struct {
+		Uptime int64 `unit:"h->ns"`
+	}{Uptime: 2000000}
+	if err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(in, 0); err != nil {
+		t.Errorf("%+v: %v", in, err)
+	}
+}
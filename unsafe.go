@@ -0,0 +1,100 @@
+package binencoder
+
+import (
+	"encoding/binary"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// nativeOrder is the byte order of the machine.
+var nativeOrder binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// WithUnsafe enables copying addressable structs with a fixed layout
+// straight between memory and the stream when the byte order is the
+// machine's. Such structs hold only integers, arrays and structs of them,
+// have no padding between fields, no unexported fields and no tags.
+// Anything else takes the regular path.
+func WithUnsafe(enabled bool) Option {
+	return func(c *config) {
+		c.unsafe = enabled
+	}
+}
+
+var (
+	layoutsMu sync.RWMutex
+	layouts   = map[planKey]bool{}
+)
+
+// memoryBytes returns the memory of struct v if it can be copied as is.
+func (c *config) memoryBytes(v reflect.Value, bytesLen int) ([]byte, bool) {
+	if !c.unsafe || bytesLen != 0 || !v.CanAddr() || c.intOrder() != nativeOrder || !c.isFixedLayout(v.Type()) {
+		return nil, false
+	}
+	size := int(v.Type().Size())
+	return (*[1 << 30]byte)(unsafe.Pointer(v.UnsafeAddr()))[:size:size], true
+}
+
+func (c *config) isFixedLayout(t reflect.Type) bool {
+	key := planKey{t: t, tagName: c.tagName}
+	layoutsMu.RLock()
+	fixed, ok := layouts[key]
+	layoutsMu.RUnlock()
+	if ok {
+		return fixed
+	}
+	fixed = c.fixedLayout(t)
+	layoutsMu.Lock()
+	layouts[key] = fixed
+	layoutsMu.Unlock()
+	return fixed
+}
+
+func (c *config) fixedLayout(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Int16,
+		reflect.Uint32, reflect.Int32, reflect.Uint64, reflect.Int64:
+		return plainElem(t)
+	case reflect.Array:
+		return !customEncoding(t) && c.fixedLayout(t.Elem())
+	case reflect.Struct:
+		if customEncoding(t) {
+			return false
+		}
+		var offset uintptr
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Offset != offset || f.Tag.Get(c.tagName) != "" || !c.fixedLayout(f.Type) {
+				return false
+			}
+			for _, key := range fieldOptions {
+				if c.tag(f, key) != "" {
+					return false
+				}
+			}
+			if e, _ := fieldEnum(f.Type, ""); e != nil {
+				return false
+			}
+			offset += f.Type.Size()
+		}
+		return offset == t.Size()
+	}
+	return false
+}
+
+// customEncoding reports whether values of type t are encoded by a codec or
+// their own methods, in a byte order or blocks of their own or with hooks.
+func customEncoding(t reflect.Type) bool {
+	if _, ok := lookupCodec(t); ok {
+		return true
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(marshalerType) || pt.Implements(binaryMarshalerType) || pt.Implements(byteOrdererType) ||
+		pt.Implements(blockSizerType) || pt.Implements(beforeEncoderType) || pt.Implements(afterDecoderType)
+}
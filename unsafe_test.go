@@ -0,0 +1,67 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type fixedHeader struct {
+	Magic   uint32
+	Version uint16
+	Flags   int16
+	Length  uint64
+	Sensors [4]int32
+	Inner   struct {
+		A, B uint32
+	}
+}
+
+type paddedHeader struct {
+	Kind   uint8
+	Length uint32
+}
+
+func TestUnsafe(t *testing.T) {
+	in := fixedHeader{Magic: 0xcafebabe, Version: 2, Flags: -1, Length: 1 << 40, Sensors: [4]int32{1, -2, 3, -4}}
+	in.Inner.A, in.Inner.B = 5, 6
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		want, err := binencoder.Marshal(in, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := []binencoder.Option{binencoder.WithByteOrder(order), binencoder.WithUnsafe(true)}
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf, opts...).Encode(&in, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), want)
+
+		var out fixedHeader
+		if err := binencoder.NewDecoder(buf, opts...).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("%v: we have:\n%+v\n got:\n%+v\n", order, in, out)
+		}
+	}
+}
+
+func TestUnsafeFallback(t *testing.T) {
+	in := paddedHeader{Kind: 1, Length: 2}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithUnsafe(true)).Encode(&in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 2, 0, 0, 0})
+
+	var out paddedHeader
+	if err := binencoder.NewDecoder(buf, binencoder.WithUnsafe(true)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+}
@@ -0,0 +1,107 @@
+package binencoder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// until is a parsed `until` tag: a slice field without a count, which
+// ends with the input or with an element equal to sentinel.
+type until struct {
+	eof      bool
+	sentinel uint64
+}
+
+// parseUntilTag parses the value of an `until` tag on a field of type t,
+// "eof" or an integer sentinel such as "0x00", returning nil for an empty
+// tag.
+func parseUntilTag(tag string, t reflect.Type) (*until, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%w: until tag on %s", ErrUnknownType, t)
+	}
+	if tag == "eof" {
+		return &until{eof: true}, nil
+	}
+	s, err := strconv.ParseUint(tag, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("binencoder: invalid until tag %q", tag)
+	}
+	elem := reflect.Zero(t.Elem())
+	switch {
+	case !isVarintKind(elem.Kind()):
+		return nil, fmt.Errorf("%w: until sentinel on elements of type %s", ErrUnknownType, t.Elem())
+	case elem.Kind() >= reflect.Uint8 && elem.OverflowUint(s),
+		elem.Kind() < reflect.Uint8 && (s > 1<<63-1 || elem.OverflowInt(int64(s))):
+		return nil, fmt.Errorf("binencoder: until sentinel %s overflows %s", tag, t.Elem())
+	}
+	return &until{sentinel: s}, nil
+}
+
+// isSentinel reports whether the integer v is the sentinel of u.
+func (u *until) isSentinel(v reflect.Value) bool {
+	if v.Kind() >= reflect.Uint8 {
+		return v.Uint() == u.sentinel
+	}
+	return v.Int() >= 0 && uint64(v.Int()) == u.sentinel
+}
+
+// encodeUntil encodes the slice field with an `until` tag, followed by its
+// sentinel, if any, which the elements must not contain.
+func (enc *Encoder) encodeUntil(field reflect.Value, f *fieldPlan, bytesLen int, path int) error {
+	if f.until.eof {
+		return enc.encodeFieldValue(field, f, bytesLen, path)
+	}
+	if err := f.check(field); err != nil {
+		return enc.fail(path, field.Type(), err)
+	}
+	for i := 0; i < field.Len(); i++ {
+		if f.until.isSentinel(field.Index(i)) {
+			return enc.fail(enc.indexPath(path, i), field.Type().Elem(), fmt.Errorf("%w: element equals the until sentinel %#x", ErrInvalidValue, f.until.sentinel))
+		}
+	}
+	sentinel := reflect.New(field.Type().Elem()).Elem()
+	if sentinel.Kind() >= reflect.Uint8 {
+		sentinel.SetUint(f.until.sentinel)
+	} else {
+		sentinel.SetInt(int64(f.until.sentinel))
+	}
+	terminated := reflect.Append(reflect.AppendSlice(reflect.MakeSlice(field.Type(), 0, field.Len()+1), field), sentinel)
+	// The field was checked without its sentinel.
+	unchecked := *f
+	unchecked.validate, unchecked.enum = nil, nil
+	return enc.encodeFieldValue(terminated, &unchecked, bytesLen, path)
+}
+
+// decodeUntil is the inverse of Encoder.encodeUntil. Elements are read
+// until the input ends between two of them or the sentinel is read.
+func (dec *Decoder) decodeUntil(field reflect.Value, f *fieldPlan, bytesLen int, path string) error {
+	if f.order != nil {
+		defer dec.useOrder(f.order)()
+	}
+	s := reflect.Zero(field.Type())
+	for i := 0; ; i++ {
+		if max := dec.maxSliceLen; max > 0 && i >= max {
+			return newDecodeError(path, field.Type(), fmt.Errorf("%w: more than %d elements before the end of the slice", ErrLimitExceeded, max))
+		}
+		elem := reflect.New(field.Type().Elem()).Elem()
+		n := dec.n
+		err := dec.decode(elem, f.fieldLen(bytesLen), f.tags, path+"["+strconv.Itoa(i)+"]")
+		if f.until.eof && dec.n == n && errors.Is(err, ErrShortMessage) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !f.until.eof && f.until.isSentinel(elem) {
+			break
+		}
+		s = reflect.Append(s, elem)
+	}
+	field.Set(s)
+	return nil
+}
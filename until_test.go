@@ -0,0 +1,78 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestUntilSentinel(t *testing.T) {
+	type record struct {
+		Kind  uint8
+		Name  []uint8  `until:"0x00"`
+		Ports []uint16 `until:"0xffff" endian:"be"`
+	}
+	in := record{Kind: 1, Name: []uint8("ab"), Ports: []uint16{80, 443}}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), []byte{1, 'a', 'b', 0, 0, 80, 1, 0xbb, 0xff, 0xff})
+
+	var out record
+	if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Name) != "ab" || len(out.Ports) != 2 || out.Ports[1] != 443 {
+		t.Errorf("got %+v", out)
+	}
+
+	in.Name = []uint8{'a', 0}
+	if err := binencoder.NewEncoder(buf).Encode(in, 0); !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if err := binencoder.NewDecoder(bytes.NewReader([]byte{1, 'a'})).Decode(&out, 0); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage without the sentinel, got %v", err)
+	}
+}
+
+func TestUntilEOF(t *testing.T) {
+	type file struct {
+		Version uint8
+		Records []struct {
+			ID    uint16
+			Value uint8
+		} `until:"eof"`
+	}
+	data := []byte{2, 1, 0, 10, 2, 0, 20}
+	var out file
+	if err := binencoder.NewDecoder(bytes.NewReader(data)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 2 || len(out.Records) != 2 || out.Records[1].ID != 2 || out.Records[1].Value != 20 {
+		t.Errorf("got %+v", out)
+	}
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf).Encode(out, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalByte(t, buf.Bytes(), data)
+
+	if err := binencoder.NewDecoder(bytes.NewReader(data[:5])).Decode(&out, 0); !errors.Is(err, binencoder.ErrShortMessage) {
+		t.Errorf("expected ErrShortMessage within a record, got %v", err)
+	}
+	err := binencoder.NewDecoder(bytes.NewReader(data), binencoder.WithMaxSliceLen(1)).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	var bad struct {
+		Items []uint8 `until:"eof"`
+		After uint8
+	}
+	if err := binencoder.NewEncoder(buf).Encode(bad, 0); err == nil {
+		t.Error("expected an error for a field after an until:\"eof\" field")
+	}
+}
@@ -0,0 +1,78 @@
+package binencoder
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+const uuidLen = 16
+
+// uuidToWire encodes a `uuid`-tagged field: a [16]byte array, a type with a
+// Bytes() []byte method or an encoding.BinaryMarshaler producing 16 bytes.
+// The "guid" layout stores the first three groups little-endian, as
+// Microsoft GUIDs do; "rfc" (the default) keeps RFC 4122 byte order.
+func uuidToWire(v reflect.Value, layout string) (reflect.Value, error) {
+	var b []byte
+	switch u := v.Interface().(type) {
+	case interface{ Bytes() []byte }:
+		b = u.Bytes()
+	case encoding.BinaryMarshaler:
+		var err error
+		if b, err = u.MarshalBinary(); err != nil {
+			return v, err
+		}
+	default:
+		if !isUUIDArray(v.Type()) {
+			return v, fmt.Errorf("%w: %s is not a UUID", ErrUnknownType, v.Type())
+		}
+		b = make([]byte, uuidLen)
+		reflect.Copy(reflect.ValueOf(b), v)
+	}
+	if len(b) != uuidLen {
+		return v, fmt.Errorf("binencoder: UUID of %d bytes", len(b))
+	}
+	b, err := uuidLayout(append([]byte{}, b...), layout)
+	if err != nil {
+		return v, err
+	}
+	return reflect.ValueOf(rawBytes(b)), nil
+}
+
+// uuidFromWire sets v from 16 wire bytes. Types that are not [16]byte
+// arrays must implement encoding.BinaryUnmarshaler.
+func uuidFromWire(v reflect.Value, b []byte, layout string) error {
+	b, err := uuidLayout(b, layout)
+	if err != nil {
+		return err
+	}
+	if isUUIDArray(v.Type()) {
+		reflect.Copy(v, reflect.ValueOf(b))
+		return nil
+	}
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+			return u.UnmarshalBinary(b)
+		}
+	}
+	return fmt.Errorf("%w: %s is not a UUID", ErrUnknownType, v.Type())
+}
+
+// uuidLayout converts between RFC 4122 and mixed-endian GUID byte order in
+// place; the conversion is its own inverse.
+func uuidLayout(b []byte, layout string) ([]byte, error) {
+	switch layout {
+	case "rfc":
+	case "guid":
+		b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+		b[4], b[5] = b[5], b[4]
+		b[6], b[7] = b[7], b[6]
+	default:
+		return b, fmt.Errorf("binencoder: unknown uuid layout %q", layout)
+	}
+	return b, nil
+}
+
+func isUUIDArray(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Len() == uuidLen && t.Elem().Kind() == reflect.Uint8
+}
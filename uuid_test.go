@@ -0,0 +1,65 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+type arrayUUID [16]byte
+
+// sliceUUID stands in for UUID types that are not [16]byte arrays.
+type sliceUUID struct {
+	b []byte
+}
+
+func (u sliceUUID) MarshalBinary() ([]byte, error) {
+	return u.b, nil
+}
+
+func (u *sliceUUID) UnmarshalBinary(b []byte) error {
+	u.b = append([]byte{}, b...)
+	return nil
+}
+
+type uuidMessage struct {
+	Plain  arrayUUID
+	RFC    arrayUUID `uuid:"rfc"`
+	GUID   arrayUUID `uuid:"guid"`
+	Custom sliceUUID `uuid:"guid"`
+}
+
+func TestUUIDTag(t *testing.T) {
+	var id arrayUUID
+	hex.Decode(id[:], []byte("00112233445566778899aabbccddeeff"))
+	in := uuidMessage{Plain: id, RFC: id, GUID: id, Custom: sliceUUID{id[:]}}
+
+	buf := new(bytes.Buffer)
+	if err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(in, 0); err != nil {
+		t.Fatal(err)
+	}
+	equalErr(t, hex.EncodeToString(buf.Bytes()),
+		"00112233445566778899aabbccddeeff"+
+			"00112233445566778899aabbccddeeff"+
+			"33221100554477668899aabbccddeeff"+
+			"33221100554477668899aabbccddeeff")
+
+	var out uuidMessage
+	if err := binencoder.NewDecoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	if out.Plain != id || out.RFC != id || out.GUID != id || !bytes.Equal(out.Custom.b, id[:]) {
+		t.Errorf("We have:\n%+v\n got:\n%+v\n", in, out)
+	}
+
+	err := binencoder.NewEncoder(buf, binencoder.WithByteOrder(binary.LittleEndian)).Encode(struct {
+		ID uint32 `uuid:"rfc"`
+	}{}, 0)
+	if !errors.Is(err, binencoder.ErrUnknownType) {
+		t.Errorf("expected ErrUnknownType, got %v", err)
+	}
+}
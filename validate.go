@@ -0,0 +1,137 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validation holds the rules of a `validate` tag.
+type validation struct {
+	min, max string
+	oneof    []string
+}
+
+// parseValidateTag parses a `validate:"min=1,max=255"` or
+// `validate:"oneof=1 2 4"` tag, returning nil for an empty tag.
+func parseValidateTag(tag string) (*validation, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	val := new(validation)
+	for _, rule := range strings.Split(tag, ",") {
+		i := strings.IndexByte(rule, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("binencoder: bad validate rule %q", rule)
+		}
+		name, arg := strings.TrimSpace(rule[:i]), strings.TrimSpace(rule[i+1:])
+		switch name {
+		case "min", "max":
+			if _, err := strconv.ParseFloat(arg, 64); err != nil {
+				if _, err := strconv.ParseInt(arg, 0, 64); err != nil {
+					return nil, fmt.Errorf("binencoder: bad validate rule %q", rule)
+				}
+			}
+			if name == "min" {
+				val.min = arg
+			} else {
+				val.max = arg
+			}
+		case "oneof":
+			val.oneof = strings.Fields(arg)
+		default:
+			return nil, fmt.Errorf("binencoder: unknown validate rule %q", rule)
+		}
+	}
+	return val, nil
+}
+
+// check returns an error matching ErrInvalidValue unless v satisfies the
+// rules. min and max bound numbers and the lengths of strings, arrays and
+// slices; oneof lists the allowed numbers or strings. Nil pointers pass.
+func (val *validation) check(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	what, n := "", v
+	switch v.Kind() {
+	case reflect.String, reflect.Array, reflect.Slice:
+		what, n = "length ", reflect.ValueOf(v.Len())
+	}
+	if val.min != "" && compareNumber(n, val.min) < 0 {
+		return fmt.Errorf("%w: %s%s is less than min %s", ErrInvalidValue, what, formatScalar(n), val.min)
+	}
+	if val.max != "" && compareNumber(n, val.max) > 0 {
+		return fmt.Errorf("%w: %s%s is greater than max %s", ErrInvalidValue, what, formatScalar(n), val.max)
+	}
+	if val.oneof != nil {
+		for _, opt := range val.oneof {
+			if v.Kind() == reflect.String && v.String() == opt || v.Kind() != reflect.String && compareNumber(v, opt) == 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s is not one of %s", ErrInvalidValue, formatScalar(v), strings.Join(val.oneof, " "))
+	}
+	return nil
+}
+
+// compareNumber compares the number v with bound, returning -1, 0 or 1, or
+// 2 if they cannot be compared.
+func compareNumber(v reflect.Value, bound string) int {
+	cmp := func(less, greater bool) int {
+		switch {
+		case less:
+			return -1
+		case greater:
+			return 1
+		}
+		return 0
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if b, err := strconv.ParseInt(bound, 0, 64); err == nil {
+			return cmp(v.Int() < b, v.Int() > b)
+		}
+		if b, err := strconv.ParseFloat(bound, 64); err == nil {
+			return cmp(float64(v.Int()) < b, float64(v.Int()) > b)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if b, err := strconv.ParseUint(bound, 0, 64); err == nil {
+			return cmp(v.Uint() < b, v.Uint() > b)
+		}
+		if b, err := strconv.ParseFloat(bound, 64); err == nil {
+			return cmp(float64(v.Uint()) < b, float64(v.Uint()) > b)
+		}
+	case reflect.Float32, reflect.Float64:
+		if b, err := strconv.ParseFloat(bound, 64); err == nil {
+			return cmp(v.Float() < b, v.Float() > b)
+		}
+	}
+	return 2
+}
+
+func formatScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return v.Type().String()
+}
+
+// validate checks a decoded field against its `validate` tag and enum.
+func (dec *Decoder) validate(field reflect.Value, f *fieldPlan, path string) error {
+	if err := f.check(field); err != nil {
+		return newDecodeError(path, field.Type(), err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestValidate(t *testing.T) {
+	type message struct {
+		Version uint8   `validate:"min=1,max=3"`
+		Offset  int16   `validate:"min=-10"`
+		Mode    string  `len:"4" validate:"oneof=idle run"`
+		Items   []uint8 `validate:"max=2"`
+		Rate    *uint32 `validate:"oneof=9600 0x4b00"`
+	}
+	rate := uint32(19200)
+	valid := message{Version: 1, Offset: -10, Mode: "run", Items: []uint8{1, 2}, Rate: &rate}
+	var buf bytes.Buffer
+	if err := binencoder.NewEncoder(&buf).Encode(valid, 0); err != nil {
+		t.Fatal(err)
+	}
+	out := message{Items: make([]uint8, 2)}
+	if err := binencoder.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		change func(m *message)
+		want   string
+	}{
+		{func(m *message) { m.Version = 0 }, "binencoder: encoding Version (uint8): invalid value: 0 is less than min 1"},
+		{func(m *message) { m.Version = 4 }, "binencoder: encoding Version (uint8): invalid value: 4 is greater than max 3"},
+		{func(m *message) { m.Offset = -11 }, "binencoder: encoding Offset (int16): invalid value: -11 is less than min -10"},
+		{func(m *message) { m.Mode = "stop" }, `binencoder: encoding Mode (string): invalid value: "stop" is not one of idle run`},
+		{func(m *message) { m.Items = make([]uint8, 3) }, "binencoder: encoding Items ([]uint8): invalid value: length 3 is greater than max 2"},
+		{func(m *message) { r := uint32(1); m.Rate = &r }, "binencoder: encoding Rate (*uint32): invalid value: 1 is not one of 9600 0x4b00"},
+	} {
+		m := valid
+		c.change(&m)
+		err := binencoder.NewEncoder(&bytes.Buffer{}).Encode(m, 0)
+		if !errors.Is(err, binencoder.ErrInvalidValue) {
+			t.Errorf("expected ErrInvalidValue, got %v", err)
+			continue
+		}
+		equalErr(t, err.Error(), c.want)
+	}
+	m := valid
+	m.Rate = nil
+	if err := binencoder.NewEncoder(&bytes.Buffer{}).Encode(m, 0); err != nil {
+		t.Errorf("nil pointer: %v", err)
+	}
+
+	// A peer sending an invalid version is caught when decoding.
+	b := append([]byte(nil), buf.Bytes()...)
+	b[0] = 9
+	err := binencoder.NewDecoder(bytes.NewReader(b)).Decode(&message{Items: make([]uint8, 2)}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, got %v", err)
+	}
+	equalErr(t, err.Error(), "binencoder: decoding Version (uint8): invalid value: 9 is greater than max 3")
+
+	type tlv struct {
+		Code uint8 `tlv:"1" validate:"oneof=1 2"`
+	}
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{1, 1, 3})).Decode(&tlv{}, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for a TLV item, got %v", err)
+	}
+
+	type bad struct {
+		A uint8 `validate:"min=x"`
+	}
+	if err := binencoder.NewEncoder(&bytes.Buffer{}).Encode(bad{}, 0); err == nil {
+		t.Error("expected an error for a bad validate tag")
+	}
+}
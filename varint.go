@@ -0,0 +1,144 @@
+package binencoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// varint is a variable-length integer encoding selected by an `encoding`
+// tag on an integer field. Every byte holds 7 bits of the value and, in
+// its high bit, whether more bytes follow.
+type varint struct {
+	name string
+	// maxLen is the longest encoding in bytes.
+	maxLen int
+	// msbFirst puts the most significant group first.
+	msbFirst bool
+}
+
+// parseVarint returns the integer encoding named by an `encoding` tag.
+func parseVarint(name string) (varint, error) {
+	switch name {
+	case "mqttvbi":
+		// The Variable Byte Integer of MQTT: least significant group
+		// first, at most 4 bytes.
+		return varint{name: name, maxLen: 4}, nil
+	case "vlq":
+		// The variable-length quantity of MIDI files: most significant
+		// group first, here up to 64 bits.
+		return varint{name: name, maxLen: 10, msbFirst: true}, nil
+	}
+	return varint{}, fmt.Errorf("binencoder: unknown integer encoding %q", name)
+}
+
+// isVarint reports whether v is an integer with an `encoding` tag.
+func isVarint(v reflect.Value, tags fieldTags) bool {
+	return tags.encoding != "" && isVarintKind(v.Kind())
+}
+
+func isVarintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// max returns the largest value vi can encode.
+func (vi varint) max() uint64 {
+	if 7*vi.maxLen >= 64 {
+		return 1<<64 - 1
+	}
+	return 1<<uint(7*vi.maxLen) - 1
+}
+
+// append appends the encoding of x to b.
+func (vi varint) append(b []byte, x uint64) ([]byte, error) {
+	if x > vi.max() {
+		return b, fmt.Errorf("%w: %d exceeds %s", ErrOverflow, x, vi.name)
+	}
+	start := len(b)
+	for x >= 0x80 {
+		b = append(b, byte(x)|0x80)
+		x >>= 7
+	}
+	b = append(b, byte(x))
+	if vi.msbFirst {
+		groups := b[start:]
+		for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+			groups[i], groups[j] = groups[j], groups[i]
+		}
+		groups[0] |= 0x80
+		groups[len(groups)-1] &^= 0x80
+	}
+	return b, nil
+}
+
+// encodeVarint encodes an integer with an `encoding` tag.
+func (enc *Encoder) encodeVarint(v reflect.Value, tags fieldTags, path int) error {
+	vi, err := parseVarint(tags.encoding)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	var x uint64
+	switch v.Kind() {
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return enc.fail(path, v.Type(), fmt.Errorf("%w: negative %s %d", ErrInvalidValue, vi.name, v.Int()))
+		}
+		x = uint64(v.Int())
+	default:
+		x = v.Uint()
+	}
+	b, err := vi.append(enc.scratch[:0], x)
+	if err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	enc.scratch = b
+	if err := enc.write(b); err != nil {
+		return enc.fail(path, v.Type(), err)
+	}
+	return nil
+}
+
+// decodeVarint is the inverse of Encoder.encodeVarint.
+func (dec *Decoder) decodeVarint(v reflect.Value, tags fieldTags, path string) error {
+	vi, err := parseVarint(tags.encoding)
+	if err != nil {
+		return newDecodeError(path, v.Type(), err)
+	}
+	var x uint64
+	b := make([]byte, 1)
+	for i := 0; ; i++ {
+		if i == vi.maxLen {
+			return newDecodeError(path, v.Type(), fmt.Errorf("%w: %s longer than %d bytes", ErrInvalidValue, vi.name, vi.maxLen))
+		}
+		if err := dec.readFull(b); err != nil {
+			return newDecodeError(path, v.Type(), err)
+		}
+		if vi.msbFirst {
+			if x > vi.max()>>7 {
+				return newDecodeError(path, v.Type(), fmt.Errorf("%w: %s exceeds 64 bits", ErrOverflow, vi.name))
+			}
+			x = x<<7 | uint64(b[0]&0x7f)
+		} else {
+			x |= uint64(b[0]&0x7f) << uint(7*i)
+		}
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+	switch v.Kind() {
+	case reflect.Int16, reflect.Int32, reflect.Int64:
+		if x > 1<<63-1 || v.OverflowInt(int64(x)) {
+			return newDecodeError(path, v.Type(), fmt.Errorf("%w: %d", ErrOverflow, x))
+		}
+		v.SetInt(int64(x))
+	default:
+		if v.OverflowUint(x) {
+			return newDecodeError(path, v.Type(), fmt.Errorf("%w: %d", ErrOverflow, x))
+		}
+		v.SetUint(x)
+	}
+	return nil
+}
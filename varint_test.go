@@ -0,0 +1,95 @@
+package binencoder_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/milQA/binencoder"
+)
+
+func TestMQTTVarint(t *testing.T) {
+	type fixedHeader struct {
+		Type      uint8
+		Remaining uint32 `encoding:"mqttvbi"`
+	}
+	for _, tc := range []struct {
+		n    uint32
+		wire []byte
+	}{
+		{0, []byte{0}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{268435455, []byte{0xff, 0xff, 0xff, 0x7f}},
+	} {
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf).Encode(fixedHeader{0x30, tc.n}, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), append([]byte{0x30}, tc.wire...))
+		var out fixedHeader
+		if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out.Remaining != tc.n {
+			t.Errorf("decoded %d, want %d", out.Remaining, tc.n)
+		}
+	}
+
+	err := binencoder.NewEncoder(new(bytes.Buffer)).Encode(fixedHeader{Remaining: 268435456}, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	var out fixedHeader
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01})).Decode(&out, 0)
+	if !errors.Is(err, binencoder.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	var small struct {
+		N uint8 `encoding:"mqttvbi"`
+	}
+	err = binencoder.NewDecoder(bytes.NewReader([]byte{0x80, 0x02})).Decode(&small, 0)
+	if !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestVLQ(t *testing.T) {
+	type event struct {
+		Delta uint64 `encoding:"vlq"`
+	}
+	for _, tc := range []struct {
+		n    uint64
+		wire []byte
+	}{
+		{0, []byte{0x00}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0x81, 0x00}},
+		{0x2000, []byte{0xc0, 0x00}},
+		{0x3fff, []byte{0xff, 0x7f}},
+		{0x4000, []byte{0x81, 0x80, 0x00}},
+		{0x0fffffff, []byte{0xff, 0xff, 0xff, 0x7f}},
+		{1<<64 - 1, []byte{0x81, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+	} {
+		buf := new(bytes.Buffer)
+		if err := binencoder.NewEncoder(buf).Encode(event{tc.n}, 0); err != nil {
+			t.Fatal(err)
+		}
+		equalByte(t, buf.Bytes(), tc.wire)
+		var out event
+		if err := binencoder.NewDecoder(buf).Decode(&out, 0); err != nil {
+			t.Fatal(err)
+		}
+		if out.Delta != tc.n {
+			t.Errorf("decoded %#x, want %#x", out.Delta, tc.n)
+		}
+	}
+
+	var out event
+	tooBig := []byte{0x82, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	if err := binencoder.NewDecoder(bytes.NewReader(tooBig)).Decode(&out, 0); !errors.Is(err, binencoder.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}